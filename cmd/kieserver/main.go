@@ -34,7 +34,9 @@ import (
 	_ "github.com/go-chassis/cari/db/bootstrap"
 
 	//storage
+	_ "github.com/apache/servicecomb-kie/server/datasource/bolt"
 	_ "github.com/apache/servicecomb-kie/server/datasource/etcd"
+	_ "github.com/apache/servicecomb-kie/server/datasource/memory"
 	_ "github.com/apache/servicecomb-kie/server/datasource/mongo"
 
 	//quota management
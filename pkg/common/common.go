@@ -21,26 +21,44 @@ import "time"
 
 // match mode
 const (
-	QueryParamQ            = "q"
-	QueryByLabelsCon       = "&"
-	QueryParamWait         = "wait"
-	QueryParamRev          = "revision"
-	QueryParamMatch        = "match"
-	QueryParamKey          = "key"
-	QueryParamValue        = "value"
-	QueryParamLabel        = "label"
-	QueryParamStatus       = "status"
-	QueryParamOffset       = "offset"
-	QueryParamLimit        = "limit"
-	PathParamKVID          = "kv_id"
-	PathParameterProject   = "project"
-	QueryParamSessionID    = "sessionId"
-	QueryParamSessionGroup = "sessionGroup"
-	QueryParamIP           = "ip"
-	QueryParamURLPath      = "urlPath"
-	QueryParamUserAgent    = "userAgent"
-	QueryParamOverride     = "override"
-	QueryParamMode         = "mode"
+	QueryParamQ             = "q"
+	QueryByLabelsCon        = "&"
+	QueryParamWait          = "wait"
+	QueryParamRev           = "revision"
+	QueryParamMatch         = "match"
+	QueryParamKey           = "key"
+	QueryParamValue         = "value"
+	QueryParamLabel         = "label"
+	QueryParamStatus        = "status"
+	QueryParamOffset        = "offset"
+	QueryParamLimit         = "limit"
+	PathParamKVID           = "kv_id"
+	PathParameterProject    = "project"
+	QueryParamSessionID     = "sessionId"
+	QueryParamSessionGroup  = "sessionGroup"
+	QueryParamIP            = "ip"
+	QueryParamURLPath       = "urlPath"
+	QueryParamUserAgent     = "userAgent"
+	QueryParamOverride      = "override"
+	QueryParamMode          = "mode"
+	QueryParamPrune         = "prune"
+	QueryParamTopics        = "topics"
+	QueryParamResolve       = "resolve"
+	QueryParamValueType     = "value_type"
+	QueryParamIgnoreCase    = "ignore_case"
+	QueryParamUpdatedAfter  = "updated_after"
+	QueryParamUpdatedBefore = "updated_before"
+	QueryParamSort          = "sort"
+	QueryParamCursor        = "cursor"
+	QueryParamPrefix        = "prefix"
+	QueryParamDelimiter     = "delimiter"
+	QueryParamOrphan        = "orphan"
+	QueryParamDryRun        = "dryRun"
+	QueryParamAtomic        = "atomic"
+	QueryParamProject       = "project"
+	PathParamNode           = "node"
+	PathParamSchemaID       = "schema_id"
+	PathParamLabelSetID     = "label_set_id"
 )
 
 // http headers
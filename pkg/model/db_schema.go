@@ -17,16 +17,22 @@
 
 package model
 
-import "time"
+import (
+	"time"
 
-// LabelDoc is database struct to store labels
+	"github.com/apache/servicecomb-kie/pkg/selector"
+)
+
+// LabelDoc is database struct to store labels,
+// it is a label set used by kvs of a project, count is the number of the kvs
 type LabelDoc struct {
 	ID      string            `json:"id,omitempty" bson:"id,omitempty" yaml:"id,omitempty" swag:"string"`
 	Labels  map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
-	Format  string            `bson:"format,omitempty"`
+	Format  string            `json:"format,omitempty" bson:"format,omitempty" yaml:"format,omitempty"`
 	Domain  string            `json:"domain,omitempty" yaml:"domain,omitempty"` //tenant info
 	Project string            `json:"project,omitempty" yaml:"project,omitempty"`
 	Alias   string            `json:"alias,omitempty" yaml:"alias,omitempty"`
+	Count   int64             `json:"count" bson:"count" yaml:"count"`
 }
 
 // KVDoc is database struct to store kv
@@ -37,7 +43,7 @@ type KVDoc struct {
 	Value          string `json:"value" yaml:"value" validate:"max=131072,value"`                                                    //128K
 	ValueType      string `json:"value_type,omitempty" bson:"value_type,omitempty" yaml:"value_type,omitempty" validate:"valueType"` //ini,json,text,yaml,properties,xml
 	Priority       int    `json:"priority,omitempty" yaml:"priority,omitempty"`                                                      //the smaller value,the higher priority
-	Checker        string `json:"check,omitempty" yaml:"check,omitempty" validate:"max=1048576,check"`                               //python script
+	Checker        string `json:"check,omitempty" yaml:"check,omitempty" validate:"max=1048576,check"`                               //expression which values must satisfy
 	CreateRevision int64  `json:"create_revision,omitempty" bson:"create_revision," yaml:"create_revision,omitempty"`
 	UpdateRevision int64  `json:"update_revision,omitempty" bson:"update_revision," yaml:"update_revision,omitempty"`
 	Project        string `json:"project,omitempty" yaml:"project,omitempty" validate:"min=1,max=256,commonName"`
@@ -58,6 +64,29 @@ type ViewDoc struct {
 	Criteria string `json:"criteria,omitempty" yaml:"criteria,omitempty"`
 }
 
+// SchemaDoc is db struct, it is a json schema which values of keys matching the key pattern must conform to
+type SchemaDoc struct {
+	ID         string `json:"id,omitempty" bson:"id,omitempty" yaml:"id,omitempty" swag:"string"`
+	Key        string `json:"key" bson:"key" yaml:"key" validate:"min=1,max=2048,getKey"` //key or pattern, e.g. beginWith(timeout.)
+	Schema     string `json:"schema" bson:"schema" yaml:"schema" validate:"min=1,max=131072"`
+	Project    string `json:"project,omitempty" bson:"project,omitempty" yaml:"project,omitempty" validate:"min=1,max=256,commonName"`
+	Domain     string `json:"domain,omitempty" bson:"domain,omitempty" yaml:"domain,omitempty" validate:"min=1,max=256,commonName"` //redundant
+	CreateTime int64  `json:"create_time,omitempty" bson:"create_time," yaml:"create_time,omitempty"`
+	UpdateTime int64  `json:"update_time,omitempty" bson:"update_time," yaml:"update_time,omitempty"`
+}
+
+// OutboxEvent is db struct, it is a kv change event waiting to be delivered to the bus
+type OutboxEvent struct {
+	ID        string            `json:"id,omitempty" bson:"id,omitempty"`
+	Node      string            `json:"node,omitempty" bson:"node,omitempty"`
+	Key       string            `json:"key,omitempty" bson:"key,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty" bson:"labels,omitempty"`
+	Action    string            `json:"action,omitempty" bson:"action,omitempty"`
+	Domain    string            `json:"domain,omitempty" bson:"domain,omitempty"`
+	Project   string            `json:"project,omitempty" bson:"project,omitempty"`
+	Timestamp int64             `json:"timestamp,omitempty" bson:"timestamp,omitempty"`
+}
+
 // PollingDetail is db struct, it record operation history
 type PollingDetail struct {
 	ID           string                 `json:"id,omitempty" yaml:"id,omitempty"`
@@ -84,6 +113,31 @@ type UpdateKVRequest struct {
 	Status  string `json:"status,omitempty" yaml:"status,omitempty" validate:"kvStatus"`
 }
 
+// RelabelKVRequest moves the kv to another label set,
+// override decides what to do if the key already exists in the label set, it is abort by default
+type RelabelKVRequest struct {
+	ID       string            `json:"id,omitempty" yaml:"id,omitempty" validate:"min=1,max=64"`
+	Project  string            `json:"project,omitempty" yaml:"project,omitempty" validate:"min=1,max=256,commonName"`
+	Domain   string            `json:"domain,omitempty" yaml:"domain,omitempty" validate:"min=1,max=256,commonName"` //redundant
+	Labels   map[string]string `json:"labels,omitempty" yaml:"labels,omitempty" validate:"max=8,dive,keys,labelK,endkeys,labelV"`
+	Override string            `json:"override,omitempty" yaml:"override,omitempty"`
+}
+
+// CopyKVRequest copies kvs selected by key, labels and status in the project to the target project,
+// labels of the copies are rewritten, a label is removed if the rewritten value is empty
+type CopyKVRequest struct {
+	Project       string            `json:"project,omitempty" yaml:"project,omitempty" validate:"min=1,max=256,commonName"`
+	Domain        string            `json:"domain,omitempty" yaml:"domain,omitempty" validate:"min=1,max=256,commonName"` //redundant
+	Key           string            `json:"key,omitempty" yaml:"key,omitempty" validate:"max=128,getKey"`
+	Labels        map[string]string `json:"labels,omitempty" yaml:"labels,omitempty" validate:"max=8,dive,keys,labelK,endkeys,labelV"`
+	Selector      selector.Selector `json:"selector,omitempty" yaml:"selector,omitempty" validate:"max=8,dive"`
+	Status        string            `json:"status,omitempty" yaml:"status,omitempty" validate:"kvStatus"`
+	TargetProject string            `json:"target_project,omitempty" yaml:"target_project,omitempty" validate:"max=256,commonName"`
+	Rewrite       map[string]string `json:"rewrite,omitempty" yaml:"rewrite,omitempty" validate:"max=8,dive,keys,labelK,endkeys,labelV"`
+	Override      string            `json:"override,omitempty" yaml:"override,omitempty"`
+	DryRun        bool              `json:"dry_run,omitempty" yaml:"dry_run,omitempty"`
+}
+
 // GetKVRequest contains kv get request params
 type GetKVRequest struct {
 	Project string `json:"project,omitempty" yaml:"project,omitempty" validate:"min=1,max=256,commonName"`
@@ -98,10 +152,100 @@ type ListKVRequest struct {
 	Key     string            `json:"key" yaml:"key" validate:"max=128,getKey"`
 	Value   string            `json:"value" yaml:"value" validate:"max=128"`
 	Labels  map[string]string `json:"labels,omitempty" yaml:"labels,omitempty" validate:"max=8,dive,keys,labelK,endkeys,labelV"` //redundant
-	Offset  int64             `validate:"min=0"`
-	Limit   int64             `validate:"min=0,max=100"`
-	Status  string            `json:"status,omitempty" yaml:"status,omitempty" validate:"kvStatus"`
-	Match   string            `json:"match,omitempty" yaml:"match,omitempty"`
+	// Selector is the label requirements besides equality, like env!=prod
+	Selector selector.Selector `json:"selector,omitempty" yaml:"selector,omitempty" validate:"max=8,dive"`
+	// Combinations are label combinations, the result is the union of kvs matching any of them
+	Combinations []map[string]string `json:"combinations,omitempty" yaml:"combinations,omitempty" validate:"max=8,dive,max=8,dive,keys,labelK,endkeys,labelV"`
+	Offset       int64               `validate:"min=0"`
+	Limit        int64               `validate:"min=0,max=100"`
+	Status       string              `json:"status,omitempty" yaml:"status,omitempty" validate:"kvStatus"`
+	Match        string              `json:"match,omitempty" yaml:"match,omitempty"`
+	Resolve      bool                `json:"resolve,omitempty" yaml:"resolve,omitempty"` //replace references in values
+	// Sort is the field to sort by, prefixed by "-" in descending order, kvs are paged by cursor if it is set
+	Sort   string `json:"sort,omitempty" yaml:"sort,omitempty"`
+	Cursor string `json:"cursor,omitempty" yaml:"cursor,omitempty" validate:"max=1024"`
+}
+
+// SearchKVRequest contains kv search request params, key and value are regular expressions
+type SearchKVRequest struct {
+	Project       string            `json:"project,omitempty" yaml:"project,omitempty" validate:"min=1,max=256,commonName"`
+	Domain        string            `json:"domain,omitempty" yaml:"domain,omitempty" validate:"min=1,max=256,commonName"` //redundant
+	Key           string            `json:"key,omitempty" yaml:"key,omitempty" validate:"max=256"`
+	Value         string            `json:"value,omitempty" yaml:"value,omitempty" validate:"max=256"`
+	IgnoreCase    bool              `json:"ignore_case,omitempty" yaml:"ignore_case,omitempty"`
+	ValueType     string            `json:"value_type,omitempty" yaml:"value_type,omitempty" validate:"valueType"`
+	Status        string            `json:"status,omitempty" yaml:"status,omitempty" validate:"kvStatus"`
+	Labels        map[string]string `json:"labels,omitempty" yaml:"labels,omitempty" validate:"max=8,dive,keys,labelK,endkeys,labelV"`
+	Selector      selector.Selector `json:"selector,omitempty" yaml:"selector,omitempty" validate:"max=8,dive"`
+	UpdatedAfter  int64             `json:"updated_after,omitempty" yaml:"updated_after,omitempty" validate:"min=0"`
+	UpdatedBefore int64             `json:"updated_before,omitempty" yaml:"updated_before,omitempty" validate:"min=0"`
+	Offset        int64             `validate:"min=0"`
+	Limit         int64             `validate:"min=0,max=100"`
+}
+
+// KeyTreeRequest contains params to browse keys under the prefix level by level
+type KeyTreeRequest struct {
+	Project   string            `json:"project,omitempty" yaml:"project,omitempty" validate:"min=1,max=256,commonName"`
+	Domain    string            `json:"domain,omitempty" yaml:"domain,omitempty" validate:"min=1,max=256,commonName"` //redundant
+	Prefix    string            `json:"prefix,omitempty" yaml:"prefix,omitempty" validate:"max=2048,keyPrefix"`
+	Delimiter string            `json:"delimiter,omitempty" yaml:"delimiter,omitempty" validate:"delimiter"`
+	Status    string            `json:"status,omitempty" yaml:"status,omitempty" validate:"kvStatus"`
+	Labels    map[string]string `json:"labels,omitempty" yaml:"labels,omitempty" validate:"max=8,dive,keys,labelK,endkeys,labelV"`
+	Selector  selector.Selector `json:"selector,omitempty" yaml:"selector,omitempty" validate:"max=8,dive"`
+}
+
+// LabelAliasRequest gives the label set a human friendly name, an empty alias removes the name
+type LabelAliasRequest struct {
+	Project string            `json:"project,omitempty" yaml:"project,omitempty" validate:"min=1,max=256,commonName"`
+	Domain  string            `json:"domain,omitempty" yaml:"domain,omitempty" validate:"min=1,max=256,commonName"` //redundant
+	Labels  map[string]string `json:"labels,omitempty" yaml:"labels,omitempty" validate:"max=8,dive,keys,labelK,endkeys,labelV"`
+	Alias   string            `json:"alias,omitempty" yaml:"alias,omitempty" validate:"max=128,commonName"`
+}
+
+// KVPatch is the fields to set, nil fields are not changed
+type KVPatch struct {
+	Value    *string `json:"value,omitempty" yaml:"value,omitempty"`
+	Status   string  `json:"status,omitempty" yaml:"status,omitempty" validate:"kvStatus"`
+	Priority *int    `json:"priority,omitempty" yaml:"priority,omitempty"`
+}
+
+// BulkUpdateKVRequest patches kvs selected by ids, or by key and labels the same as listing them
+type BulkUpdateKVRequest struct {
+	Project  string            `json:"project,omitempty" yaml:"project,omitempty" validate:"min=1,max=256,commonName"`
+	Domain   string            `json:"domain,omitempty" yaml:"domain,omitempty" validate:"min=1,max=256,commonName"` //redundant
+	IDs      []string          `json:"ids,omitempty" yaml:"ids,omitempty" validate:"max=1000"`
+	Key      string            `json:"key,omitempty" yaml:"key,omitempty" validate:"max=128,getKey"`
+	Labels   map[string]string `json:"labels,omitempty" yaml:"labels,omitempty" validate:"max=8,dive,keys,labelK,endkeys,labelV"`
+	Selector selector.Selector `json:"selector,omitempty" yaml:"selector,omitempty" validate:"max=8,dive"`
+	Set      KVPatch           `json:"set" yaml:"set"`
+	DryRun   bool              `json:"dry_run,omitempty" yaml:"dry_run,omitempty"`
+}
+
+// operations of TxnOp
+const (
+	TxnCreate = "create"
+	TxnUpdate = "update"
+	TxnDelete = "delete"
+)
+
+// TxnOp is an operation of the kv transaction, the kv to update or delete is located by id,
+// revision is the precondition, the transaction fails if the update revision of the kv is not the same
+type TxnOp struct {
+	Op        string            `json:"op" yaml:"op"`
+	ID        string            `json:"id,omitempty" yaml:"id,omitempty" validate:"max=64"`
+	Key       string            `json:"key,omitempty" yaml:"key,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Value     string            `json:"value,omitempty" yaml:"value,omitempty"`
+	ValueType string            `json:"value_type,omitempty" yaml:"value_type,omitempty"`
+	Status    string            `json:"status,omitempty" yaml:"status,omitempty" validate:"kvStatus"`
+	Revision  int64             `json:"revision,omitempty" yaml:"revision,omitempty" validate:"min=0"`
+}
+
+// TxnRequest applies the operations atomically under one revision
+type TxnRequest struct {
+	Project string   `json:"project,omitempty" yaml:"project,omitempty" validate:"min=1,max=256,commonName"`
+	Domain  string   `json:"domain,omitempty" yaml:"domain,omitempty" validate:"min=1,max=256,commonName"` //redundant
+	Ops     []*TxnOp `json:"ops" yaml:"ops"`
 }
 
 // UploadKVRequest contains kv list upload request params
@@ -110,4 +254,8 @@ type UploadKVRequest struct {
 	Project  string `json:"project,omitempty" yaml:"project,omitempty" validate:"min=1,max=256,commonName"`
 	KVs      []*KVDoc
 	Override string
+	// DryRun previews the changes without persisting them
+	DryRun bool
+	// Atomic writes all kvs in one transaction, or nothing
+	Atomic bool
 }
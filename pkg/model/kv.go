@@ -22,7 +22,7 @@ type KVRequest struct {
 	Key       string            `json:"key" yaml:"key"`
 	Value     string            `json:"value,omitempty" yaml:"value,omitempty"`
 	ValueType string            `json:"value_type,omitempty" bson:"value_type,omitempty" yaml:"value_type,omitempty"` //ini,json,text,yaml,properties,xml
-	Checker   string            `json:"check,omitempty" yaml:"check,omitempty"`                                       //python script
+	Checker   string            `json:"check,omitempty" yaml:"check,omitempty"`                                       //expression which values must satisfy
 	Labels    map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`                                     //redundant
 }
 
@@ -30,6 +30,66 @@ type KVRequest struct {
 type KVResponse struct {
 	Total int      `json:"total"`
 	Data  []*KVDoc `json:"data"`
+	// Matched tags kvs by id with the label combinations they matched, only set when listing by label combinations
+	Matched map[string][]string `json:"matched,omitempty" yaml:"matched,omitempty"`
+	// NextCursor is the token of the next page when listing by cursor, it is empty on the last page
+	NextCursor string `json:"next_cursor,omitempty" yaml:"next_cursor,omitempty"`
+}
+
+// SearchResponse represents the kvs matching a search
+type SearchResponse struct {
+	Total int             `json:"total"`
+	Data  []*SearchResult `json:"data"`
+}
+
+// SearchResult is a kv with the fragments matching the search,
+// highlight is keyed by "key" and "value", matches are wrapped in <em></em>
+type SearchResult struct {
+	*KVDoc    `yaml:",inline"`
+	Highlight map[string][]string `json:"highlight,omitempty" yaml:"highlight,omitempty"`
+}
+
+// KeyTreeResponse lists the child nodes right under the prefix, like common prefixes of s3
+type KeyTreeResponse struct {
+	Prefix    string     `json:"prefix"`
+	Delimiter string     `json:"delimiter"`
+	Total     int        `json:"total"` //number of kvs under the prefix
+	Data      []*KeyNode `json:"data"`
+}
+
+// KeyNode is a segment of keys after the prefix and before the next delimiter,
+// browse its children by the prefix of path and delimiter
+type KeyNode struct {
+	Name  string `json:"name"`
+	Path  string `json:"path"`
+	Count int    `json:"count"` //number of kvs whose key is the path or under it
+	Key   bool   `json:"key"`   //the path itself is a key
+	Leaf  bool   `json:"leaf"`  //no key is under the path
+}
+
+// LabelResponse lists the distinct label keys of a project
+type LabelResponse struct {
+	Total int         `json:"total"`
+	Data  []*LabelKey `json:"data"`
+}
+
+// LabelKey is a label key with its values, count is the number of kvs having the key
+type LabelKey struct {
+	Key    string        `json:"key"`
+	Count  int64         `json:"count"`
+	Values []*LabelValue `json:"values"`
+}
+
+// LabelValue is a value of the label key, count is the number of kvs having the label
+type LabelValue struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// LabelSetResponse lists the label sets of a project
+type LabelSetResponse struct {
+	Total int         `json:"total"`
+	Data  []*LabelDoc `json:"data"`
 }
 
 // LabelDocResponse is label struct
@@ -44,12 +104,34 @@ type LabelHistoryResponse struct {
 	Revision int               `json:"revision"`
 }
 
+// SchemaResponse represents the schema list
+type SchemaResponse struct {
+	Total int          `json:"total"`
+	Data  []*SchemaDoc `json:"data"`
+}
+
 // ViewResponse represents the view list
 type ViewResponse struct {
 	Total int        `json:"total,omitempty"`
 	Data  []*ViewDoc `json:"data,omitempty"`
 }
 
+// TxnResponse is the result of the transaction, data are kvs in the order of operations,
+// deleted kvs are returned as they were before deletion
+type TxnResponse struct {
+	Revision int64    `json:"revision"`
+	Data     []*KVDoc `json:"data"`
+}
+
+// BulkResponse is the result of bulk operations, total is the number of selected kvs,
+// updated kvs share the revision, nothing is changed in dry run
+type BulkResponse struct {
+	Total    int                  `json:"total"`
+	Revision int64                `json:"revision,omitempty"`
+	Success  []*KVDoc             `json:"success"`
+	Failure  []*DocFailedOfUpload `json:"failure"`
+}
+
 // DocResponseSingleKey is response doc
 type DocResponseSingleKey struct {
 	CreateRevision int64             `json:"create_revision"`
@@ -74,14 +156,50 @@ type DocResponseGetKey struct {
 type DocRespOfUpload struct {
 	Success []*KVDoc             `json:"success"`
 	Failure []*DocFailedOfUpload `json:"failure"`
+	// Diff previews the changes in dry run, nothing is persisted
+	Diff []*KVDiff `json:"diff,omitempty"`
+}
+
+// actions of KVDiff
+const (
+	DiffCreate    = "create"
+	DiffUpdate    = "update"
+	DiffUnchanged = "unchanged"
+	DiffSkip      = "skip"
+	DiffAbort     = "abort"
+)
+
+// KVDiff is the change would be made to a kv
+type KVDiff struct {
+	Key      string            `json:"key"`
+	Labels   map[string]string `json:"labels"`
+	Action   string            `json:"action"`
+	OldValue string            `json:"old_value,omitempty"`
+	NewValue string            `json:"new_value,omitempty"`
 }
 
 // DocFailedOfUpload is reponse doc
 type DocFailedOfUpload struct {
+	ID      string            `json:"id,omitempty"`
 	Key     string            `json:"key"`
 	Labels  map[string]string `json:"labels"`
 	ErrCode int32             `json:"error_code"`
 	ErrMsg  string            `json:"error_message"`
+	// Line and Column locate the syntax error of the value
+	Line   int `json:"line,omitempty"`
+	Column int `json:"column,omitempty"`
+}
+
+// DocRespOfCheck is response doc
+type DocRespOfCheck struct {
+	Results []*CheckResult `json:"results"`
+}
+
+// CheckResult tells whether a sample value satisfies the checker
+type CheckResult struct {
+	Value  string `json:"value"`
+	Passed bool   `json:"passed"`
+	Reason string `json:"reason,omitempty"`
 }
 
 // PollingDataResponse  is response doc
@@ -92,8 +210,31 @@ type PollingDataResponse struct {
 
 // DocHealthCheck is response doc
 type DocHealthCheck struct {
-	Version   string `json:"version"`
-	Revision  string `json:"revision"`
-	Timestamp int64  `json:"timestamp"`
-	Total     int64  `json:"total_kv_count"`
+	Version         string `json:"version"`
+	Revision        string `json:"revision"`
+	ProjectRevision string `json:"project_revision,omitempty"`
+	Timestamp       int64  `json:"timestamp"`
+	Total           int64  `json:"total_kv_count"`
+}
+
+// ClusterMember is a kie node in the cluster
+type ClusterMember struct {
+	Name           string            `json:"name"`
+	Addr           string            `json:"addr"`
+	Status         string            `json:"status"`
+	Tags           map[string]string `json:"tags,omitempty"`
+	EventsReceived int64             `json:"events_received"`
+	LastEventTime  int64             `json:"last_event_time"`
+	Local          bool              `json:"local"`
+}
+
+// DocClusterMembers is response doc
+type DocClusterMembers struct {
+	Total int              `json:"total"`
+	Data  []*ClusterMember `json:"data"`
+}
+
+// DocClusterJoin is response doc
+type DocClusterJoin struct {
+	Joined int `json:"joined"`
 }
@@ -0,0 +1,191 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package selector implements label selectors,
+// a selector is a list of requirements and a labels map matches it only if it satisfies all of them
+package selector
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+)
+
+// operators of requirement
+const (
+	OpEquals    = "="
+	OpNotEquals = "!="
+	OpIn        = "in"
+	OpNotIn     = "notin"
+	OpExists    = "exists"
+	OpNotExists = "!"
+)
+
+// ErrInvalidRequirement means the requirement can not be parsed
+var ErrInvalidRequirement = errors.New("invalid label selector")
+
+// Requirement is a condition of one label key,
+// like kubernetes, "!=" and "notin" are also satisfied if the key does not exist
+type Requirement struct {
+	Key      string   `json:"key" validate:"labelK"`
+	Operator string   `json:"operator"`
+	Values   []string `json:"values,omitempty" validate:"max=32,dive,labelV"`
+}
+
+// Selector is a list of requirements
+type Selector []*Requirement
+
+// ParseRequirement parse one requirement, supported forms are
+// "k:v", "k!=v", "k in (v1,v2)", "k notin (v1,v2)", "k" (key exists) and "!k" (key does not exist)
+func ParseRequirement(s string) (*Requirement, error) {
+	s = strings.TrimSpace(s)
+	var r *Requirement
+	switch {
+	case strings.HasPrefix(s, OpNotExists) && !strings.Contains(s, OpNotEquals):
+		r = &Requirement{Key: strings.TrimSpace(s[1:]), Operator: OpNotExists}
+	case strings.Contains(s, OpNotEquals):
+		kv := strings.SplitN(s, OpNotEquals, 2)
+		r = &Requirement{Key: strings.TrimSpace(kv[0]), Operator: OpNotEquals, Values: []string{strings.TrimSpace(kv[1])}}
+	case strings.HasSuffix(s, ")"):
+		return parseSet(s)
+	case strings.Contains(s, ":"):
+		kv := strings.Split(s, ":")
+		if len(kv) != 2 {
+			return nil, ErrInvalidRequirement
+		}
+		r = &Requirement{Key: kv[0], Operator: OpEquals, Values: []string{kv[1]}}
+	default:
+		r = &Requirement{Key: s, Operator: OpExists}
+	}
+	if r.Key == "" || strings.ContainsAny(r.Key, " (),!:") {
+		return nil, ErrInvalidRequirement
+	}
+	return r, nil
+}
+
+func parseSet(s string) (*Requirement, error) {
+	i := strings.Index(s, "(")
+	if i < 0 {
+		return nil, ErrInvalidRequirement
+	}
+	fields := strings.Fields(s[:i])
+	if len(fields) != 2 || (fields[1] != OpIn && fields[1] != OpNotIn) {
+		return nil, ErrInvalidRequirement
+	}
+	set := strings.TrimSpace(s[i+1 : len(s)-1])
+	if set == "" {
+		return nil, ErrInvalidRequirement
+	}
+	r := &Requirement{Key: fields[0], Operator: fields[1]}
+	for _, v := range strings.Split(set, ",") {
+		r.Values = append(r.Values, strings.TrimSpace(v))
+	}
+	if strings.ContainsAny(r.Key, "!:") {
+		return nil, ErrInvalidRequirement
+	}
+	return r, nil
+}
+
+// Matches return true if the labels satisfy the requirement
+func (r *Requirement) Matches(labels map[string]string) bool {
+	v, ok := labels[r.Key]
+	switch r.Operator {
+	case OpEquals:
+		return ok && v == r.Values[0]
+	case OpNotEquals:
+		return !ok || v != r.Values[0]
+	case OpIn:
+		return ok && r.has(v)
+	case OpNotIn:
+		return !ok || !r.has(v)
+	case OpExists:
+		return ok
+	case OpNotExists:
+		return !ok
+	}
+	return false
+}
+
+func (r *Requirement) has(v string) bool {
+	for _, value := range r.Values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// String return the requirement in the form it is parsed from, values of a set are sorted
+func (r *Requirement) String() string {
+	switch r.Operator {
+	case OpEquals:
+		return r.Key + ":" + r.Values[0]
+	case OpNotEquals:
+		return r.Key + OpNotEquals + r.Values[0]
+	case OpIn, OpNotIn:
+		values := append([]string{}, r.Values...)
+		sort.Strings(values)
+		return r.Key + " " + r.Operator + " (" + strings.Join(values, ",") + ")"
+	case OpExists:
+		return r.Key
+	}
+	return OpNotExists + r.Key
+}
+
+// Matches return true if the labels satisfy all requirements
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, r := range s {
+		if !r.Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// Strings return the sorted requirements, the result is identical for the same requirements
+func (s Selector) Strings() []string {
+	result := make([]string, 0, len(s))
+	for _, r := range s {
+		result = append(result, r.String())
+	}
+	sort.Strings(result)
+	return result
+}
+
+// MarshalJSON encode the selector as the sorted requirement strings
+func (s Selector) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Strings())
+}
+
+// UnmarshalJSON decode the requirement strings
+func (s *Selector) UnmarshalJSON(b []byte) error {
+	var ss []string
+	if err := json.Unmarshal(b, &ss); err != nil {
+		return err
+	}
+	result := make(Selector, 0, len(ss))
+	for _, str := range ss {
+		r, err := ParseRequirement(str)
+		if err != nil {
+			return err
+		}
+		result = append(result, r)
+	}
+	*s = result
+	return nil
+}
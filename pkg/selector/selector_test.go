@@ -0,0 +1,103 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package selector_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/apache/servicecomb-kie/pkg/selector"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRequirement(t *testing.T) {
+	tests := []struct {
+		in   string
+		want *selector.Requirement
+	}{
+		{"env:prod", &selector.Requirement{Key: "env", Operator: selector.OpEquals, Values: []string{"prod"}}},
+		{"env!=prod", &selector.Requirement{Key: "env", Operator: selector.OpNotEquals, Values: []string{"prod"}}},
+		{"version in (1.0, 1.1)", &selector.Requirement{Key: "version", Operator: selector.OpIn, Values: []string{"1.0", "1.1"}}},
+		{"version notin (1.0)", &selector.Requirement{Key: "version", Operator: selector.OpNotIn, Values: []string{"1.0"}}},
+		{"region", &selector.Requirement{Key: "region", Operator: selector.OpExists}},
+		{"!canary", &selector.Requirement{Key: "canary", Operator: selector.OpNotExists}},
+	}
+	for _, tt := range tests {
+		r, err := selector.ParseRequirement(tt.in)
+		assert.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, r, tt.in)
+	}
+	for _, in := range []string{"", "a:b:c", "!", "version in ()", "version has (1.0)", "a b", "!=prod"} {
+		_, err := selector.ParseRequirement(in)
+		assert.Equal(t, selector.ErrInvalidRequirement, err, in)
+	}
+}
+
+func TestSelector_Matches(t *testing.T) {
+	labels := map[string]string{"env": "test", "version": "1.0"}
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"env:test", true},
+		{"env:prod", false},
+		{"env!=prod", true},
+		{"env!=test", false},
+		{"region!=cn", true},
+		{"version in (1.0,1.1)", true},
+		{"version in (1.1)", false},
+		{"region in (cn)", false},
+		{"version notin (1.1)", true},
+		{"version notin (1.0)", false},
+		{"region notin (cn)", true},
+		{"env", true},
+		{"region", false},
+		{"!region", true},
+		{"!env", false},
+	}
+	for _, tt := range tests {
+		r, err := selector.ParseRequirement(tt.in)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, selector.Selector{r}.Matches(labels), tt.in)
+	}
+	t.Run("all requirements must be satisfied", func(t *testing.T) {
+		s := selector.Selector{
+			{Key: "env", Operator: selector.OpNotEquals, Values: []string{"prod"}},
+			{Key: "canary", Operator: selector.OpNotExists},
+		}
+		assert.True(t, s.Matches(labels))
+		assert.False(t, s.Matches(map[string]string{"canary": "true"}))
+		assert.True(t, selector.Selector(nil).Matches(labels))
+	})
+}
+
+func TestSelector_JSON(t *testing.T) {
+	s := selector.Selector{
+		{Key: "version", Operator: selector.OpIn, Values: []string{"1.1", "1.0"}},
+		{Key: "canary", Operator: selector.OpNotExists},
+	}
+	b, err := json.Marshal(s)
+	assert.NoError(t, err)
+	assert.Equal(t, `["!canary","version in (1.0,1.1)"]`, string(b))
+
+	var s2 selector.Selector
+	assert.NoError(t, json.Unmarshal(b, &s2))
+	b2, err := json.Marshal(s2)
+	assert.NoError(t, err)
+	assert.Equal(t, b, b2)
+}
@@ -26,6 +26,7 @@ const (
 	labelKeyRegexString   = `^[a-zA-Z0-9]{1,32}$|^[a-zA-Z0-9][a-zA-Z0-9_\-.]{1,30}[a-zA-Z0-9]$`
 	labelValueRegexString = `^[a-zA-Z0-9]{0,160}$|^[a-zA-Z0-9][a-zA-Z0-9_\-.]{0,158}[a-zA-Z0-9]$`
 	getKeyRegexString     = `^[a-zA-Z0-9._:-]*$|^beginWith\([a-zA-Z0-9._:-]*\)$|^wildcard\([a-zA-Z0-9*._:-]*\)$`
+	keyPrefixRegexString  = `^[a-zA-Z0-9._:-]*$`
 	asciiRegexString      = `^[\x00-\x7F]*$`
 	allCharString         = `.*`
 )
@@ -35,6 +36,8 @@ const (
 var customRules = []*validator.RegexValidateRule{
 	validator.NewRegexRule(key, keyRegex),
 	validator.NewRegexRule("getKey", getKeyRegexString),
+	validator.NewRegexRule("keyPrefix", keyPrefixRegexString),
+	validator.NewRegexRule("delimiter", `^[._:-]$`),
 	validator.NewRegexRule("commonName", commonNameRegexString),
 	validator.NewRegexRule("valueType", `^$|^(ini|json|text|yaml|properties|xml)$`),
 	validator.NewRegexRule("kvStatus", `^$|^(enabled|disabled)$`),
@@ -0,0 +1,227 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package validator
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// value types
+const (
+	ValueTypeText       = "text"
+	ValueTypeJSON       = "json"
+	ValueTypeYAML       = "yaml"
+	ValueTypeXML        = "xml"
+	ValueTypeINI        = "ini"
+	ValueTypeProperties = "properties"
+)
+
+// SyntaxError describes where a value fails to parse as its value type,
+// line and column start from 1, and they are 0 if unknown
+type SyntaxError struct {
+	ValueType string
+	Line      int
+	Column    int
+	Msg       string
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("invalid %s value: %s", e.ValueType, e.Msg)
+	}
+	if e.Column == 0 {
+		return fmt.Sprintf("invalid %s value at line %d: %s", e.ValueType, e.Line, e.Msg)
+	}
+	return fmt.Sprintf("invalid %s value at line %d, column %d: %s", e.ValueType, e.Line, e.Column, e.Msg)
+}
+
+// SyntaxChecker checks the value syntax of a value type
+type SyntaxChecker func(value string) *SyntaxError
+
+var syntaxCheckers = map[string]SyntaxChecker{
+	ValueTypeJSON:       checkJSON,
+	ValueTypeYAML:       checkYAML,
+	ValueTypeXML:        checkXML,
+	ValueTypeINI:        checkINI,
+	ValueTypeProperties: checkProperties,
+}
+
+// ValidateValue checks whether the value parses as the value type,
+// text and empty values are always valid
+func ValidateValue(valueType, value string) *SyntaxError {
+	checker, ok := syntaxCheckers[valueType]
+	if !ok || value == "" {
+		return nil
+	}
+	if e := checker(value); e != nil {
+		e.ValueType = valueType
+		return e
+	}
+	return nil
+}
+
+func checkJSON(value string) *SyntaxError {
+	var v interface{}
+	err := json.Unmarshal([]byte(value), &v)
+	if err == nil {
+		return nil
+	}
+	var se *json.SyntaxError
+	if errors.As(err, &se) {
+		line, col := position(value, se.Offset)
+		return &SyntaxError{Line: line, Column: col, Msg: se.Error()}
+	}
+	return &SyntaxError{Msg: err.Error()}
+}
+
+var yamlLineRegex = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+func checkYAML(value string) *SyntaxError {
+	var v interface{}
+	err := yaml.Unmarshal([]byte(value), &v)
+	if err == nil {
+		return nil
+	}
+	if m := yamlLineRegex.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return &SyntaxError{Line: line, Msg: m[2]}
+	}
+	return &SyntaxError{Msg: strings.TrimPrefix(err.Error(), "yaml: ")}
+}
+
+func checkXML(value string) *SyntaxError {
+	d := xml.NewDecoder(strings.NewReader(value))
+	roots := 0
+	depth := 0
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			line, col := d.InputPos()
+			var se *xml.SyntaxError
+			if errors.As(err, &se) {
+				return &SyntaxError{Line: se.Line, Column: col, Msg: se.Msg}
+			}
+			return &SyntaxError{Line: line, Column: col, Msg: err.Error()}
+		}
+		switch t.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				roots++
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+	}
+	if roots != 1 {
+		return &SyntaxError{Msg: fmt.Sprintf("expect 1 root element, got %d", roots)}
+	}
+	return nil
+}
+
+// checkINI accepts sections, key value pairs separated by = or :, and comments start with ; or #
+func checkINI(value string) *SyntaxError {
+	s := bufio.NewScanner(strings.NewReader(value))
+	s.Buffer(make([]byte, 0, len(value)+1), len(value)+1)
+	line := 0
+	for s.Scan() {
+		line++
+		raw := s.Text()
+		text := strings.TrimSpace(raw)
+		col := strings.Index(raw, text) + 1
+		if text == "" || text[0] == ';' || text[0] == '#' {
+			continue
+		}
+		if text[0] == '[' {
+			end := strings.IndexByte(text, ']')
+			if end < 0 {
+				return &SyntaxError{Line: line, Column: col + len(text), Msg: "section is not closed"}
+			}
+			if strings.TrimSpace(text[1:end]) == "" {
+				return &SyntaxError{Line: line, Column: col + 1, Msg: "section name is empty"}
+			}
+			continue
+		}
+		sep := strings.IndexAny(text, "=:")
+		if sep < 0 {
+			return &SyntaxError{Line: line, Column: col, Msg: "expect key=value"}
+		}
+		if strings.TrimSpace(text[:sep]) == "" {
+			return &SyntaxError{Line: line, Column: col, Msg: "key is empty"}
+		}
+	}
+	return nil
+}
+
+// checkProperties accepts any line as java properties do, except malformed unicode escapes
+func checkProperties(value string) *SyntaxError {
+	s := bufio.NewScanner(strings.NewReader(value))
+	s.Buffer(make([]byte, 0, len(value)+1), len(value)+1)
+	line := 0
+	for s.Scan() {
+		line++
+		text := s.Text()
+		trimmed := strings.TrimLeft(text, " \t\f")
+		if trimmed == "" || trimmed[0] == '#' || trimmed[0] == '!' {
+			continue
+		}
+		for i := 0; i < len(text); i++ {
+			if text[i] != '\\' {
+				continue
+			}
+			if i+1 < len(text) && text[i+1] == 'u' {
+				hex := text[i+2:]
+				if len(hex) > 4 {
+					hex = hex[:4]
+				}
+				if _, err := strconv.ParseUint(hex, 16, 16); err != nil || len(hex) < 4 {
+					return &SyntaxError{Line: line, Column: i + 1, Msg: "malformed \\uxxxx encoding"}
+				}
+			}
+			i++
+		}
+	}
+	return nil
+}
+
+// position converts the byte offset to line and column
+func position(value string, offset int64) (int, int) {
+	if offset > int64(len(value)) {
+		offset = int64(len(value))
+	}
+	before := []byte(value[:offset])
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := len(before) - bytes.LastIndexByte(before, '\n') - 1
+	if col < 1 {
+		col = 1
+	}
+	return line, col
+}
@@ -0,0 +1,43 @@
+package validator_test
+
+import (
+	"testing"
+
+	validsvc "github.com/apache/servicecomb-kie/pkg/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateValue(t *testing.T) {
+	cases := []struct {
+		valueType string
+		value     string
+		line      int
+		column    int
+		valid     bool
+	}{
+		{valueType: "text", value: "{", valid: true},
+		{valueType: "json", value: `{"a": [1, 2]}`, valid: true},
+		{valueType: "json", value: "{\"a\":\n  }", line: 2, column: 3},
+		{valueType: "yaml", value: "a:\n  b: c\n", valid: true},
+		{valueType: "yaml", value: "a: b\nc: d: e\n", line: 2},
+		{valueType: "xml", value: "<a><b/></a>", valid: true},
+		{valueType: "xml", value: "<a><b></a>", line: 1, column: 11},
+		{valueType: "xml", value: "<a/><b/>"},
+		{valueType: "ini", value: "; comment\n[s]\nk = v\n", valid: true},
+		{valueType: "ini", value: "[s]\nk=v\n[bad\n", line: 3, column: 5},
+		{valueType: "ini", value: "k=v\nnot a pair\n", line: 2, column: 1},
+		{valueType: "properties", value: "# comment\na=b\nc \\\n  d\ne=\\u00e9", valid: true},
+		{valueType: "properties", value: "a=b\nc=\\u12", line: 2, column: 3},
+	}
+	for _, c := range cases {
+		err := validsvc.ValidateValue(c.valueType, c.value)
+		if c.valid {
+			assert.Nil(t, err, c.value)
+			continue
+		}
+		if assert.NotNil(t, err, c.value) {
+			assert.Equal(t, c.line, err.Line, c.value)
+			assert.Equal(t, c.column, err.Column, c.value)
+		}
+	}
+}
@@ -1,43 +1,161 @@
 package cache
 
 import (
+	"container/list"
 	"sync"
+	"time"
 
 	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/metrics"
 	"github.com/go-chassis/cari/pkg/errsvc"
 )
 
-var pollingCache = &LongPollingCache{}
+// defaults of long polling cache
+const (
+	DefaultSize = 10000
+	DefaultTTL  = 5 * time.Minute
+)
+
+var pollingCache = NewLongPollingCache(DefaultSize, DefaultTTL)
 
-// LongPollingCache exchange space for time
+// LongPollingCache exchange space for time,
+// it is a LRU cache of query results by topic, results expire after ttl,
+// or once the revision moves on
 type LongPollingCache struct {
-	m sync.Map
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	ll      *list.List
+	entries map[string]*list.Element
+
+	hits   int64
+	misses int64
 }
+
 type DBResult struct {
 	KVs *model.KVResponse
 	Err *errsvc.Error
 	Rev int64
 }
 
+type entry struct {
+	topic    string
+	result   *DBResult
+	expireAt time.Time
+}
+
+// Stats is the snapshot of the cache
+type Stats struct {
+	Size    int      `json:"size"`
+	MaxSize int      `json:"max_size"`
+	TTL     string   `json:"ttl"`
+	Hits    int64    `json:"hits"`
+	Misses  int64    `json:"misses"`
+	Topics  []string `json:"topics,omitempty"`
+}
+
+// NewLongPollingCache create a cache holds at most size results
+func NewLongPollingCache(size int, ttl time.Duration) *LongPollingCache {
+	if size <= 0 {
+		size = DefaultSize
+	}
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &LongPollingCache{
+		size:    size,
+		ttl:     ttl,
+		ll:      list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// Init replace the cache with a new one of given size and ttl
+func Init(size int, ttl time.Duration) {
+	pollingCache = NewLongPollingCache(size, ttl)
+}
+
 func CachedKV() *LongPollingCache {
 	return pollingCache
 }
 
-// Read reads the cached query result
-// only need to filter by labels if match pattern is exact
-func (c *LongPollingCache) Read(topic string) (int64, *model.KVResponse, *errsvc.Error) {
-	value, ok := c.m.Load(topic)
+// Read reads the cached query result,
+// a result which is expired or older than rev is evicted and treated as a miss
+func (c *LongPollingCache) Read(topic string, rev int64) (*DBResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[topic]
 	if !ok {
-		return 0, nil, nil
+		c.miss()
+		return nil, false
 	}
-	t := value.(*DBResult)
-	if t.Err != nil {
-		return 0, nil, t.Err
+	en := e.Value.(*entry)
+	if time.Now().After(en.expireAt) || en.result.Rev < rev {
+		c.removeElement(e)
+		c.miss()
+		return nil, false
 	}
-	return t.Rev, t.KVs, nil
-
+	c.ll.MoveToFront(e)
+	c.hits++
+	metrics.ReportCacheHit()
+	return en.result, true
 }
 
+// Write caches the query result of a topic, and evicts the least recently used one if cache is full
 func (c *LongPollingCache) Write(topic string, r *DBResult) {
-	c.m.Store(topic, r)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	expireAt := time.Now().Add(c.ttl)
+	if e, ok := c.entries[topic]; ok {
+		en := e.Value.(*entry)
+		en.result = r
+		en.expireAt = expireAt
+		c.ll.MoveToFront(e)
+		return
+	}
+	c.entries[topic] = c.ll.PushFront(&entry{topic: topic, result: r, expireAt: expireAt})
+	for c.ll.Len() > c.size {
+		c.removeElement(c.ll.Back())
+	}
+	metrics.ReportCacheSize(c.ll.Len())
+}
+
+// Flush removes all cached results
+func (c *LongPollingCache) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ll.Init()
+	c.entries = make(map[string]*list.Element)
+	metrics.ReportCacheSize(0)
+}
+
+// Stats return the snapshot of the cache, topics are listed from the most recently used
+func (c *LongPollingCache) Stats(withTopics bool) *Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := &Stats{
+		Size:    c.ll.Len(),
+		MaxSize: c.size,
+		TTL:     c.ttl.String(),
+		Hits:    c.hits,
+		Misses:  c.misses,
+	}
+	if withTopics {
+		s.Topics = make([]string, 0, c.ll.Len())
+		for e := c.ll.Front(); e != nil; e = e.Next() {
+			s.Topics = append(s.Topics, e.Value.(*entry).topic)
+		}
+	}
+	return s
+}
+
+func (c *LongPollingCache) removeElement(e *list.Element) {
+	c.ll.Remove(e)
+	delete(c.entries, e.Value.(*entry).topic)
+	metrics.ReportCacheSize(c.ll.Len())
+}
+
+func (c *LongPollingCache) miss() {
+	c.misses++
+	metrics.ReportCacheMiss()
 }
@@ -0,0 +1,76 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func result(rev int64) *cache.DBResult {
+	return &cache.DBResult{
+		KVs: &model.KVResponse{},
+		Rev: rev,
+	}
+}
+
+func TestLongPollingCache(t *testing.T) {
+	t.Run("evict the least recently used result when cache is full", func(t *testing.T) {
+		c := cache.NewLongPollingCache(2, time.Minute)
+		c.Write("a", result(1))
+		c.Write("b", result(1))
+		_, ok := c.Read("a", 1)
+		assert.True(t, ok)
+		c.Write("c", result(1))
+		_, ok = c.Read("b", 1)
+		assert.False(t, ok)
+		_, ok = c.Read("a", 1)
+		assert.True(t, ok)
+		_, ok = c.Read("c", 1)
+		assert.True(t, ok)
+		s := c.Stats(true)
+		assert.Equal(t, 2, s.Size)
+		assert.Equal(t, []string{"c", "a"}, s.Topics)
+		assert.Equal(t, int64(3), s.Hits)
+		assert.Equal(t, int64(1), s.Misses)
+	})
+	t.Run("result older than revision should be evicted", func(t *testing.T) {
+		c := cache.NewLongPollingCache(2, time.Minute)
+		c.Write("a", result(1))
+		_, ok := c.Read("a", 2)
+		assert.False(t, ok)
+		assert.Equal(t, 0, c.Stats(false).Size)
+	})
+	t.Run("result should expire after ttl", func(t *testing.T) {
+		c := cache.NewLongPollingCache(2, 10*time.Millisecond)
+		c.Write("a", result(1))
+		time.Sleep(20 * time.Millisecond)
+		_, ok := c.Read("a", 1)
+		assert.False(t, ok)
+	})
+	t.Run("flush cache", func(t *testing.T) {
+		c := cache.NewLongPollingCache(2, time.Minute)
+		c.Write("a", result(1))
+		c.Flush()
+		_, ok := c.Read("a", 1)
+		assert.False(t, ok)
+	})
+}
@@ -52,3 +52,28 @@ func GetRBAC() RBAC {
 func GetSync() Sync {
 	return Configurations.Sync
 }
+
+// GetEvent return event config
+func GetEvent() Event {
+	return Configurations.Event
+}
+
+// GetLongPolling return long polling config
+func GetLongPolling() LongPolling {
+	return Configurations.LongPolling
+}
+
+// GetOutbox return outbox config
+func GetOutbox() Outbox {
+	return Configurations.Outbox
+}
+
+// GetValueType return value type validation config
+func GetValueType() ValueType {
+	return Configurations.ValueType
+}
+
+// GetChecker return checker budget config
+func GetChecker() Checker {
+	return Configurations.Checker
+}
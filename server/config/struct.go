@@ -19,9 +19,15 @@ package config
 
 // Config is yaml file struct
 type Config struct {
-	DB   DB   `yaml:"db"`
-	RBAC RBAC `yaml:"rbac"`
-	Sync Sync `yaml:"sync"`
+	DB    DB    `yaml:"db"`
+	RBAC  RBAC  `yaml:"rbac"`
+	Sync  Sync  `yaml:"sync"`
+	Event Event `yaml:"event"`
+	// LongPolling is long polling cache config
+	LongPolling LongPolling `yaml:"longPolling"`
+	Outbox      Outbox      `yaml:"outbox"`
+	ValueType   ValueType   `yaml:"valueType"`
+	Checker     Checker     `yaml:"checker"`
 	// config from cli
 	ConfigFile     string
 	NodeName       string
@@ -46,6 +52,9 @@ type DB struct {
 	Kind     string `yaml:"kind" json:"kind,omitempty"`
 	PoolSize int    `yaml:"poolSize" json:"pool_size,omitempty"`
 	Timeout  string `yaml:"timeout" json:"timeout,omitempty"`
+	// RevisionBlock is the number of revisions a node reserves at once, default is 1,
+	// a value larger than 1 is for single node deployments only
+	RevisionBlock int64 `yaml:"revisionBlock" json:"revision_block,omitempty"`
 }
 
 // RBAC is rbac config
@@ -59,3 +68,47 @@ type RBAC struct {
 type Sync struct {
 	Enabled bool `yaml:"enabled"`
 }
+
+// Event is kv change event config
+type Event struct {
+	// Coalesce retains events and fires them in batches,
+	// events of the same key, action and labels in one batch are merged, watchers are woken up once in a batch
+	Coalesce      bool   `yaml:"coalesce"`
+	BatchSize     int    `yaml:"batchSize"`
+	BatchInterval string `yaml:"batchInterval"`
+}
+
+// LongPolling is long polling config
+type LongPolling struct {
+	// CacheSize is the max number of cached query results
+	CacheSize int `yaml:"cacheSize"`
+	// CacheTTL is how long a query result is cached, e.g. 5m
+	CacheTTL string `yaml:"cacheTTL"`
+}
+
+// Outbox is outbox config
+type Outbox struct {
+	// Enabled writes kv change events to db in the same transaction as kv mutations,
+	// then delivers them to other nodes with retry
+	Enabled bool `yaml:"enabled"`
+}
+
+// ValueType is value type validation config,
+// values are always checked against their value types,
+// strict mode additionally requires value_type to be declared instead of defaulting to text
+type ValueType struct {
+	// Strict applies strict mode to all projects
+	Strict bool `yaml:"strict"`
+	// StrictProjects applies strict mode to these projects
+	StrictProjects []string `yaml:"strictProjects"`
+}
+
+// Checker is the budget of kv checker expressions
+type Checker struct {
+	// Timeout is the max time to evaluate a checker, e.g. 100ms
+	Timeout string `yaml:"timeout"`
+	// MaxNodes is the max number of syntax nodes of a checker
+	MaxNodes uint `yaml:"maxNodes"`
+	// MemoryBudget is the max number of items a checker can allocate, it bounds loops of the checker
+	MemoryBudget uint `yaml:"memoryBudget"`
+}
@@ -21,6 +21,7 @@ import (
 	"context"
 
 	"github.com/apache/servicecomb-kie/pkg/model"
+	rbacmodel "github.com/go-chassis/cari/rbac"
 )
 
 const verbGet, verbCreate, verbUpdate, verbDelete = "get", "create", "update", "delete"
@@ -53,6 +54,28 @@ func FilterKVList(ctx context.Context, kvs []*model.KVDoc) ([]*model.KVDoc, erro
 	return FilterKVs(kvs, labels), nil
 }
 
+// KVFilter return a function tells whether the kv can be got, nil means all kvs can be got
+func KVFilter(ctx context.Context) func(*model.KVDoc) bool {
+	if !CheckEnable(ctx) {
+		return nil
+	}
+	labelsList, err := CheckPerm(ctx, configPerms(verbGet, nil))
+	if err != nil {
+		return func(*model.KVDoc) bool { return false }
+	}
+	if len(labelsList) == 0 {
+		return nil
+	}
+	return func(kv *model.KVDoc) bool {
+		for _, labels := range labelsList {
+			if matchOne(kv, labels) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
 func CheckGetKV(ctx context.Context, kv *model.KVDoc) error {
 	if !CheckEnable(ctx) {
 		return nil
@@ -77,6 +100,21 @@ func CheckDeleteKV(ctx context.Context, kv *model.KVDoc) error {
 	return err
 }
 
+// CheckAdmin only allows admin to operate the cluster
+func CheckAdmin(ctx context.Context) error {
+	if !CheckEnable(ctx) {
+		return nil
+	}
+	account, err := Identify(ctx)
+	if err != nil {
+		return err
+	}
+	if hasAdmin, _ := filterRoles(account.Roles); !hasAdmin {
+		return rbacmodel.NewError(rbacmodel.ErrNoPermission, "only admin can operate the cluster")
+	}
+	return nil
+}
+
 func CheckUpdateKV(ctx context.Context, kv *model.KVDoc) error {
 	if !CheckEnable(ctx) {
 		return nil
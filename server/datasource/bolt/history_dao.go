@@ -0,0 +1,176 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bolt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-chassis/openlog"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/datasource"
+	"github.com/apache/servicecomb-kie/server/datasource/auth"
+)
+
+// HistoryDao keeps revisions of kvs in the db file
+type HistoryDao struct {
+}
+
+// historyKey orders revisions of the kv by update revision
+func historyKey(domain, project, kvID string, rev int64) string {
+	return join(domain, project, kvID, fmt.Sprintf("%020d", rev))
+}
+
+// GetHistory get all history by kv id
+func (s *HistoryDao) GetHistory(ctx context.Context, kvID, project, domain string, options ...datasource.FindOption) (*model.KVResponse, error) {
+	kvdoc, err := datasource.GetBroker().GetKVDao().Get(ctx, &model.GetKVRequest{
+		Domain:  domain,
+		Project: project,
+		ID:      kvID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.CheckGetKV(ctx, kvdoc); err != nil {
+		return nil, err
+	}
+
+	opts := datasource.FindOptions{}
+	for _, o := range options {
+		o(&opts)
+	}
+	var histories []*model.KVDoc
+	err = client.View(func(tx *bolt.Tx) error {
+		histories, err = listHistories(tx, domain, project, kvID)
+		return err
+	})
+	if err != nil {
+		openlog.Error(err.Error())
+		return nil, err
+	}
+	return &model.KVResponse{
+		Data:  pagingResult(histories, opts.Offset, opts.Limit),
+		Total: len(histories),
+	}, nil
+}
+
+func listHistories(tx *bolt.Tx, domain, project, kvID string) ([]*model.KVDoc, error) {
+	histories := make([]*model.KVDoc, 0)
+	err := scan(tx, bucketHistory, prefix(domain, project, kvID), func(k, v []byte) error {
+		var doc model.KVDoc
+		if err := json.Unmarshal(v, &doc); err != nil {
+			openlog.Error("decode error: " + err.Error())
+			return nil
+		}
+		histories = append(histories, &doc)
+		return nil
+	})
+	return histories, err
+}
+
+func pagingResult(histories []*model.KVDoc, offset, limit int64) []*model.KVDoc {
+	total := int64(len(histories))
+	if limit != 0 && offset >= total {
+		return []*model.KVDoc{}
+	}
+
+	datasource.ReverseByPriorityAndUpdateRev(histories)
+
+	if limit == 0 {
+		return histories
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	return histories[offset:end]
+}
+
+// carryHistories copies the revisions of old to kv in the transaction
+func carryHistories(tx *bolt.Tx, old, kv *model.KVDoc) error {
+	histories, err := listHistories(tx, old.Domain, old.Project, old.ID)
+	if err != nil {
+		return err
+	}
+	for _, h := range histories {
+		h.ID, h.Domain, h.Project = kv.ID, kv.Domain, kv.Project
+		if err := put(tx, bucketHistory, historyKey(kv.Domain, kv.Project, kv.ID, h.UpdateRevision), h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddHistory add kv history, the oldest revisions beyond datasource.MaxHistoryNum are deleted in the same transaction
+func (s *HistoryDao) AddHistory(ctx context.Context, kv *model.KVDoc) error {
+	err := client.Update(func(tx *bolt.Tx) error {
+		if err := put(tx, bucketHistory, historyKey(kv.Domain, kv.Project, kv.ID, kv.UpdateRevision), kv); err != nil {
+			return err
+		}
+		histories, err := listHistories(tx, kv.Domain, kv.Project, kv.ID)
+		if err != nil || len(histories) <= datasource.MaxHistoryNum {
+			return err
+		}
+		datasource.ReverseByPriorityAndUpdateRev(histories)
+		for _, h := range histories[datasource.MaxHistoryNum:] {
+			if err := del(tx, bucketHistory, historyKey(h.Domain, h.Project, h.ID, h.UpdateRevision)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		openlog.Error("add history error: " + err.Error())
+		return err
+	}
+	return nil
+}
+
+// DelayDeletionTime marks the current revisions of the kvs,
+// thus these revisions will be deleted after they expire, the same as the TTL index of mongo
+func (s *HistoryDao) DelayDeletionTime(ctx context.Context, kvIDs []string, project, domain string) error {
+	deadline := time.Now().Add(expiration).Unix()
+	return client.Update(func(tx *bolt.Tx) error {
+		for _, kvID := range kvIDs {
+			// revisions are in order, the last one is the current revision
+			var last string
+			err := scan(tx, bucketHistory, prefix(domain, project, kvID), func(k, v []byte) error {
+				last = string(k)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if last == "" {
+				continue
+			}
+			err = put(tx, bucketHistoryExpiry, join(domain, project, kvID), &expiry{
+				Deadline: deadline,
+				Last:     last,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
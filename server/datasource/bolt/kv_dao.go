@@ -0,0 +1,506 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bolt
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-chassis/cari/sync"
+	"github.com/go-chassis/openlog"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/datasource"
+	"github.com/apache/servicecomb-kie/server/datasource/auth"
+)
+
+// KVDao operate kvs in the db file, a write is done in one bolt transaction,
+// permissions are checked before it, because roles are read in another transaction
+type KVDao struct {
+}
+
+func kvKey(domain, project, id string) string {
+	return join(domain, project, id)
+}
+
+// loadKV return nil if the kv does not exist
+func loadKV(domain, project, id string) (*model.KVDoc, error) {
+	var kv *model.KVDoc
+	err := client.View(func(tx *bolt.Tx) error {
+		doc := &model.KVDoc{}
+		ok, err := get(tx, bucketKV, kvKey(domain, project, id), doc)
+		if ok {
+			kv = doc
+		}
+		return err
+	})
+	return kv, err
+}
+
+// saveWrites saves tasks, tombstones and outbox events in the transaction of kv mutations
+func saveWrites(tx *bolt.Tx, w *datasource.KVWrites) error {
+	for _, task := range w.Tasks {
+		if err := put(tx, bucketTask, taskKey(task), task); err != nil {
+			return err
+		}
+	}
+	for _, tombstone := range w.Tombstones {
+		if err := put(tx, bucketTombstone, tombstoneKey(tombstone), tombstone); err != nil {
+			return err
+		}
+	}
+	for _, event := range w.Events {
+		if err := put(tx, bucketOutbox, outboxKey(event), event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *KVDao) Create(ctx context.Context, kv *model.KVDoc, options ...datasource.WriteOption) (*model.KVDoc, error) {
+	if err := auth.CheckCreateKV(ctx, kv); err != nil {
+		return nil, err
+	}
+	w := datasource.NewKVWrites(options...)
+	if err := w.Put(kv, sync.CreateAction); err != nil {
+		return nil, err
+	}
+	err := client.Update(func(tx *bolt.Tx) error {
+		k := kvKey(kv.Domain, kv.Project, kv.ID)
+		if exist(tx, bucketKV, k) {
+			return datasource.ErrKVAlreadyExists
+		}
+		if err := put(tx, bucketKV, k, kv); err != nil {
+			return err
+		}
+		if err := saveWrites(tx, w); err != nil {
+			return err
+		}
+		return countLabels(tx, datasource.CountLabels(kv.Domain, kv.Project, []*model.KVDoc{kv}, 1))
+	})
+	if err != nil {
+		openlog.Error("create error", openlog.WithTags(openlog.Tags{
+			"err": err.Error(),
+			"kv":  kv,
+		}))
+		return nil, err
+	}
+	return kv, nil
+}
+
+// Update update key value
+func (s *KVDao) Update(ctx context.Context, kv *model.KVDoc, options ...datasource.WriteOption) error {
+	old, err := loadKV(kv.Domain, kv.Project, kv.ID)
+	if err != nil {
+		openlog.Error(err.Error())
+		return err
+	}
+	if old == nil {
+		return datasource.ErrKeyNotExists
+	}
+	if err := auth.CheckUpdateKV(ctx, old); err != nil {
+		return err
+	}
+	old.LabelFormat = kv.LabelFormat
+	old.Value = kv.Value
+	old.Status = kv.Status
+	old.Checker = kv.Checker
+	old.Priority = kv.Priority
+	old.UpdateTime = kv.UpdateTime
+	old.UpdateRevision = kv.UpdateRevision
+
+	w := datasource.NewKVWrites(options...)
+	if err := w.Put(old, sync.UpdateAction); err != nil {
+		return err
+	}
+	err = client.Update(func(tx *bolt.Tx) error {
+		k := kvKey(kv.Domain, kv.Project, kv.ID)
+		if !exist(tx, bucketKV, k) {
+			return datasource.ErrKeyNotExists
+		}
+		if err := put(tx, bucketKV, k, old); err != nil {
+			return err
+		}
+		return saveWrites(tx, w)
+	})
+	if err != nil {
+		openlog.Error(err.Error())
+		return err
+	}
+	return nil
+}
+
+// Exist supports you query a key value by label map or labels id
+func (s *KVDao) Exist(ctx context.Context, key, project, domain string, options ...datasource.FindOption) (bool, error) {
+	kvs, err := s.GetByKey(ctx, key, project, domain, options...)
+	if err == datasource.ErrKeyNotExists {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return len(kvs) == 1, nil
+}
+
+func (s *KVDao) GetByKey(ctx context.Context, key, project, domain string, options ...datasource.FindOption) ([]*model.KVDoc, error) {
+	opts := datasource.FindOptions{Key: key}
+	for _, o := range options {
+		o(&opts)
+	}
+	kvs, err := s.list(ctx, project, domain, nil,
+		datasource.WithExactLabels(),
+		datasource.WithLabels(opts.Labels),
+		datasource.WithLabelFormat(opts.LabelFormat),
+		datasource.WithKey(key),
+		datasource.WithCaseSensitive())
+	if err != nil {
+		openlog.Error("check kv exist: " + err.Error())
+		return nil, err
+	}
+	if datasource.IsUniqueFind(opts) && len(kvs.Data) == 0 {
+		return nil, datasource.ErrKeyNotExists
+	}
+	if len(kvs.Data) != 1 {
+		return nil, datasource.ErrTooMany
+	}
+	return kvs.Data, nil
+}
+
+// getKVToDelete return the kv if it can be deleted
+func getKVToDelete(ctx context.Context, domain, project, kvID string) (*model.KVDoc, error) {
+	kv, err := loadKV(domain, project, kvID)
+	if err != nil {
+		openlog.Error(err.Error())
+		return nil, err
+	}
+	if kv == nil {
+		return nil, datasource.ErrKeyNotExists
+	}
+	if err := auth.CheckDeleteKV(ctx, kv); err != nil {
+		return nil, err
+	}
+	return kv, nil
+}
+
+// FindOneAndDelete deletes one kv by id and return the deleted kv as these appeared before deletion
+func (s *KVDao) FindOneAndDelete(ctx context.Context, kvID, project, domain string, options ...datasource.WriteOption) (*model.KVDoc, error) {
+	kv, err := getKVToDelete(ctx, domain, project, kvID)
+	if err != nil {
+		return nil, err
+	}
+	w := datasource.NewKVWrites(options...)
+	if err := w.Delete(kv); err != nil {
+		return nil, err
+	}
+	err = client.Update(func(tx *bolt.Tx) error {
+		k := kvKey(domain, project, kvID)
+		if !exist(tx, bucketKV, k) {
+			return datasource.ErrKeyNotExists
+		}
+		if err := del(tx, bucketKV, k); err != nil {
+			return err
+		}
+		if err := saveWrites(tx, w); err != nil {
+			return err
+		}
+		return countLabels(tx, datasource.CountLabels(domain, project, []*model.KVDoc{kv}, -1))
+	})
+	if err != nil {
+		openlog.Error("find and delete error", openlog.WithTags(openlog.Tags{
+			"err": err.Error(),
+		}))
+		return nil, err
+	}
+	return kv, nil
+}
+
+// FindManyAndDelete deletes multiple kvs and return the deleted kv list as these appeared before deletion
+func (s *KVDao) FindManyAndDelete(ctx context.Context, kvIDs []string, project, domain string, options ...datasource.WriteOption) ([]*model.KVDoc, int64, error) {
+	var found []*model.KVDoc
+	for _, id := range kvIDs {
+		kv, err := getKVToDelete(ctx, domain, project, id)
+		if err == datasource.ErrKeyNotExists {
+			continue
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		found = append(found, kv)
+	}
+	var docs []*model.KVDoc
+	err := client.Update(func(tx *bolt.Tx) error {
+		docs = nil
+		w := datasource.NewKVWrites(options...)
+		for _, kv := range found {
+			k := kvKey(domain, project, kv.ID)
+			// deleted concurrently
+			if !exist(tx, bucketKV, k) {
+				continue
+			}
+			if err := w.Delete(kv); err != nil {
+				return err
+			}
+			if err := del(tx, bucketKV, k); err != nil {
+				return err
+			}
+			docs = append(docs, kv)
+		}
+		if len(docs) == 0 {
+			return datasource.ErrKeyNotExists
+		}
+		if err := saveWrites(tx, w); err != nil {
+			return err
+		}
+		return countLabels(tx, datasource.CountLabels(domain, project, docs, -1))
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return docs, int64(len(docs)), nil
+}
+
+// Move replaces kv old by kv of another label set in a transaction,
+// the revisions of old are carried over to kv in the same transaction
+func (s *KVDao) Move(ctx context.Context, old, kv *model.KVDoc, override bool, options ...datasource.WriteOption) error {
+	if err := auth.CheckDeleteKV(ctx, old); err != nil {
+		return err
+	}
+	if err := auth.CheckCreateKV(ctx, kv); err != nil {
+		return err
+	}
+	w := datasource.NewKVWrites(options...)
+	if err := w.Delete(old); err != nil {
+		return err
+	}
+	if err := w.Put(kv, sync.CreateAction); err != nil {
+		return err
+	}
+	return client.Update(func(tx *bolt.Tx) error {
+		oldKey := kvKey(old.Domain, old.Project, old.ID)
+		newKey := kvKey(kv.Domain, kv.Project, kv.ID)
+		cur := &model.KVDoc{}
+		ok, err := get(tx, bucketKV, oldKey, cur)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return datasource.ErrKeyNotExists
+		}
+		if cur.UpdateRevision != old.UpdateRevision {
+			return datasource.ErrKVConflict
+		}
+		target := exist(tx, bucketKV, newKey)
+		if target && !override {
+			return datasource.ErrKVAlreadyExists
+		}
+		if err := del(tx, bucketKV, oldKey); err != nil {
+			return err
+		}
+		if err := put(tx, bucketKV, newKey, kv); err != nil {
+			return err
+		}
+		if err := carryHistories(tx, old, kv); err != nil {
+			return err
+		}
+		if err := saveWrites(tx, w); err != nil {
+			return err
+		}
+		if err := countLabels(tx, datasource.CountLabels(old.Domain, old.Project, []*model.KVDoc{old}, -1)); err != nil {
+			return err
+		}
+		if target {
+			return nil
+		}
+		return countLabels(tx, datasource.CountLabels(kv.Domain, kv.Project, []*model.KVDoc{kv}, 1))
+	})
+}
+
+// Batch writes kvs in one transaction
+func (s *KVDao) Batch(ctx context.Context, txn *datasource.KVTxn, options ...datasource.WriteOption) error {
+	for _, kv := range txn.Creates {
+		if err := auth.CheckCreateKV(ctx, kv); err != nil {
+			return err
+		}
+	}
+	for _, kv := range txn.Updates {
+		if err := auth.CheckUpdateKV(ctx, kv); err != nil {
+			return err
+		}
+	}
+	for _, kv := range txn.Deletes {
+		if err := auth.CheckDeleteKV(ctx, kv); err != nil {
+			return err
+		}
+	}
+	w := datasource.NewKVWrites(options...)
+	for _, kv := range txn.Creates {
+		if err := w.Put(kv, sync.CreateAction); err != nil {
+			return err
+		}
+	}
+	for _, kv := range txn.Updates {
+		if err := w.Put(kv, sync.UpdateAction); err != nil {
+			return err
+		}
+	}
+	for _, kv := range txn.Deletes {
+		if err := w.Delete(kv); err != nil {
+			return err
+		}
+	}
+	err := client.Update(func(tx *bolt.Tx) error {
+		for _, kv := range txn.Creates {
+			if exist(tx, bucketKV, kvKey(kv.Domain, kv.Project, kv.ID)) {
+				return datasource.ErrKVConflict
+			}
+		}
+		for _, kvs := range [][]*model.KVDoc{txn.Updates, txn.Deletes} {
+			for _, kv := range kvs {
+				ok, err := unchanged(tx, kv, txn.Revisions)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return datasource.ErrKVConflict
+				}
+			}
+		}
+		for _, kvs := range [][]*model.KVDoc{txn.Creates, txn.Updates} {
+			for _, kv := range kvs {
+				if err := put(tx, bucketKV, kvKey(kv.Domain, kv.Project, kv.ID), kv); err != nil {
+					return err
+				}
+			}
+		}
+		for _, kv := range txn.Deletes {
+			if err := del(tx, bucketKV, kvKey(kv.Domain, kv.Project, kv.ID)); err != nil {
+				return err
+			}
+		}
+		if err := saveWrites(tx, w); err != nil {
+			return err
+		}
+		if len(txn.Creates) != 0 {
+			err := countLabels(tx, datasource.CountLabels(txn.Creates[0].Domain, txn.Creates[0].Project, txn.Creates, 1))
+			if err != nil {
+				return err
+			}
+		}
+		if len(txn.Deletes) != 0 {
+			return countLabels(tx, datasource.CountLabels(txn.Deletes[0].Domain, txn.Deletes[0].Project, txn.Deletes, -1))
+		}
+		return nil
+	})
+	if err != nil && err != datasource.ErrKVConflict {
+		openlog.Error("batch write kvs error: " + err.Error())
+	}
+	return err
+}
+
+// unchanged checks the kv exists, and its update revision is the same as the one in revisions if present
+func unchanged(tx *bolt.Tx, kv *model.KVDoc, revisions map[string]int64) (bool, error) {
+	cur := &model.KVDoc{}
+	ok, err := get(tx, bucketKV, kvKey(kv.Domain, kv.Project, kv.ID), cur)
+	if err != nil || !ok {
+		return false, err
+	}
+	rev, ok := revisions[kv.ID]
+	return !ok || cur.UpdateRevision == rev, nil
+}
+
+// Get get kv by kv id
+func (s *KVDao) Get(ctx context.Context, req *model.GetKVRequest) (*model.KVDoc, error) {
+	kv, err := loadKV(req.Domain, req.Project, req.ID)
+	if err != nil {
+		openlog.Error(err.Error())
+		return nil, err
+	}
+	if kv == nil {
+		return nil, datasource.ErrKeyNotExists
+	}
+	if err := auth.CheckGetKV(ctx, kv); err != nil {
+		return nil, err
+	}
+	return kv, nil
+}
+
+// Total return the kv number of the project, or of the domain if project is empty
+func (s *KVDao) Total(ctx context.Context, project, domain string) (int64, error) {
+	p := prefix(domain, project)
+	if project == "" {
+		p = prefix(domain)
+	}
+	var total int64
+	err := client.View(func(tx *bolt.Tx) error {
+		return scan(tx, bucketKV, p, func(k, v []byte) error {
+			total++
+			return nil
+		})
+	})
+	if err != nil {
+		openlog.Error("find total number: " + err.Error())
+		return 0, err
+	}
+	return total, nil
+}
+
+// List get kv list by key and criteria
+func (s *KVDao) List(ctx context.Context, project, domain string, options ...datasource.FindOption) (*model.KVResponse, error) {
+	return s.list(ctx, project, domain, auth.KVFilter(ctx), options...)
+}
+
+// list get kv list by key and criteria, kvs which are not allowed are filtered out
+func (s *KVDao) list(ctx context.Context, project, domain string, allow func(*model.KVDoc) bool,
+	options ...datasource.FindOption) (*model.KVResponse, error) {
+	opts := datasource.NewDefaultFindOpts()
+	for _, o := range options {
+		o(&opts)
+	}
+	regex, err := datasource.KeyRegex(opts)
+	if err != nil {
+		return nil, err
+	}
+	search, err := datasource.NewSearchRegex(opts)
+	if err != nil {
+		return nil, err
+	}
+	c := datasource.NewKVCollector(opts, allow)
+	err = client.View(func(tx *bolt.Tx) error {
+		return scan(tx, bucketKV, prefix(domain, project), func(k, v []byte) error {
+			var doc model.KVDoc
+			if err := json.Unmarshal(v, &doc); err != nil {
+				openlog.Error("decode to KVList error: " + err.Error())
+				return nil
+			}
+			if !datasource.MatchKV(&doc, opts, regex) || !search.Match(&doc, opts) {
+				return nil
+			}
+			datasource.ClearPart(&doc)
+			if c.Add(&doc) && datasource.IsUniqueFind(opts) {
+				return errStop
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		openlog.Error("list kv failed: " + err.Error())
+		return nil, err
+	}
+	return datasource.PageKVs(c.Done(), opts), nil
+}
@@ -0,0 +1,115 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bolt
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-chassis/openlog"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/datasource"
+)
+
+// LabelDao operate the label catalog in the db file
+type LabelDao struct {
+}
+
+// countLabels adds the counts of label sets in the transaction of kv mutations, label sets are created on first use
+func countLabels(tx *bolt.Tx, docs []*model.LabelDoc) error {
+	for _, doc := range docs {
+		k := join(doc.Domain, doc.Project, doc.ID)
+		cur := &model.LabelDoc{}
+		ok, err := get(tx, bucketLabel, k, cur)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			*cur = *doc
+			cur.Count = 0
+		}
+		cur.Count += doc.Count
+		if cur.Count < 0 {
+			cur.Count = 0
+		}
+		if err := put(tx, bucketLabel, k, cur); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *LabelDao) List(ctx context.Context, project, domain string) ([]*model.LabelDoc, error) {
+	docs := make([]*model.LabelDoc, 0)
+	err := client.View(func(tx *bolt.Tx) error {
+		return scan(tx, bucketLabel, prefix(domain, project), func(k, v []byte) error {
+			doc := &model.LabelDoc{}
+			if err := json.Unmarshal(v, doc); err != nil {
+				openlog.Error("decode label set error: " + err.Error())
+				return nil
+			}
+			docs = append(docs, doc)
+			return nil
+		})
+	})
+	if err != nil {
+		openlog.Error(err.Error())
+		return nil, err
+	}
+	return docs, nil
+}
+
+func (d *LabelDao) UpdateAlias(ctx context.Context, id, project, domain, alias string) (*model.LabelDoc, error) {
+	doc := &model.LabelDoc{}
+	err := client.Update(func(tx *bolt.Tx) error {
+		k := join(domain, project, id)
+		ok, err := get(tx, bucketLabel, k, doc)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return datasource.ErrLabelNotExists
+		}
+		doc.Alias = alias
+		return put(tx, bucketLabel, k, doc)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func (d *LabelDao) DeleteOrphan(ctx context.Context, id, project, domain string) error {
+	return client.Update(func(tx *bolt.Tx) error {
+		k := join(domain, project, id)
+		doc := &model.LabelDoc{}
+		ok, err := get(tx, bucketLabel, k, doc)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return datasource.ErrLabelNotExists
+		}
+		if doc.Count != 0 {
+			return datasource.ErrLabelInUse
+		}
+		return del(tx, bucketLabel, k)
+	})
+}
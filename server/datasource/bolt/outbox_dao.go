@@ -0,0 +1,81 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bolt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-chassis/openlog"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+)
+
+// OutboxDao operate outbox events in the db file
+type OutboxDao struct {
+}
+
+// outboxKey orders events by timestamp
+func outboxKey(event *model.OutboxEvent) string {
+	return join(fmt.Sprintf("%020d", event.Timestamp), event.ID)
+}
+
+func (d *OutboxDao) List(ctx context.Context, after *model.OutboxEvent, limit int64) ([]*model.OutboxEvent, error) {
+	events := make([]*model.OutboxEvent, 0)
+	var start []byte
+	if after != nil {
+		// the first key larger than the one of after
+		start = append([]byte(outboxKey(after)), 0)
+	}
+	err := client.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucketOutbox).Cursor()
+		for k, v := c.Seek(start); k != nil; k, v = c.Next() {
+			if limit > 0 && int64(len(events)) >= limit {
+				return nil
+			}
+			event := &model.OutboxEvent{}
+			if err := json.Unmarshal(v, event); err != nil {
+				openlog.Error("decode outbox event failed: " + err.Error())
+				return err
+			}
+			events = append(events, event)
+		}
+		return nil
+	})
+	if err != nil {
+		openlog.Error("list outbox events failed: " + err.Error())
+		return nil, err
+	}
+	return events, nil
+}
+
+func (d *OutboxDao) Done(ctx context.Context, events []*model.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return client.Update(func(tx *bolt.Tx) error {
+		for _, event := range events {
+			if err := del(tx, bucketOutbox, outboxKey(event)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
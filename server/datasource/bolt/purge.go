@@ -0,0 +1,124 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bolt
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/go-chassis/openlog"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+)
+
+const (
+	// expiration of polling details and revisions of deleted kvs, the same as the TTL indexes of mongo
+	expiration    = 7 * 24 * time.Hour
+	purgeInterval = time.Hour
+)
+
+// expiry marks revisions of a deleted kv, revisions up to the key Last are deleted after Deadline,
+// so that revisions of the kv created again are kept
+type expiry struct {
+	Deadline int64  `json:"deadline"`
+	Last     string `json:"last"`
+}
+
+func purgeLoop(db *bolt.DB, stop chan struct{}) {
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+	for {
+		if err := purge(db, time.Now()); err != nil {
+			openlog.Error("purge expired data failed: " + err.Error())
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purge deletes polling details and revisions of deleted kvs which are expired
+func purge(db *bolt.DB, now time.Time) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		if err := purgeTracks(tx, now.Add(-expiration)); err != nil {
+			return err
+		}
+		return purgeHistories(tx, now.Unix())
+	})
+}
+
+func purgeTracks(tx *bolt.Tx, before time.Time) error {
+	var keys [][]byte
+	err := scan(tx, bucketTrack, nil, func(k, v []byte) error {
+		var detail model.PollingDetail
+		if err := json.Unmarshal(v, &detail); err != nil || detail.Timestamp.Before(before) {
+			keys = append(keys, append([]byte{}, k...))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if err := tx.Bucket(bucketTrack).Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func purgeHistories(tx *bolt.Tx, now int64) error {
+	expired := make(map[string]string)
+	err := scan(tx, bucketHistoryExpiry, nil, func(k, v []byte) error {
+		var e expiry
+		if err := json.Unmarshal(v, &e); err != nil || e.Deadline <= now {
+			expired[string(k)] = e.Last
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for id, last := range expired {
+		var keys [][]byte
+		p := []byte(id + "/")
+		err := scan(tx, bucketHistory, p, func(k, v []byte) error {
+			if bytes.Compare(k, []byte(last)) > 0 {
+				return errStop
+			}
+			keys = append(keys, append([]byte{}, k...))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			if err := tx.Bucket(bucketHistory).Delete(k); err != nil {
+				return err
+			}
+		}
+		if err := tx.Bucket(bucketHistoryExpiry).Delete([]byte(id)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bolt
+
+import (
+	"context"
+	"errors"
+
+	crbac "github.com/go-chassis/cari/rbac"
+	"github.com/go-chassis/openlog"
+	bolt "go.etcd.io/bbolt"
+)
+
+// RbacDao reads roles and accounts in the db file, they are written by PutRole and PutAccount
+type RbacDao struct {
+}
+
+func (re *RbacDao) GetRole(ctx context.Context, name string) (*crbac.Role, error) {
+	role := &crbac.Role{}
+	var ok bool
+	err := client.View(func(tx *bolt.Tx) error {
+		var err error
+		ok, err = get(tx, bucketRole, name, role)
+		return err
+	})
+	if err != nil {
+		openlog.Error("role info format invalid", openlog.WithErr(err))
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("role not exist")
+	}
+	return role, nil
+}
+
+func (re *RbacDao) AccountExist(ctx context.Context, name string) (bool, error) {
+	var ok bool
+	err := client.View(func(tx *bolt.Tx) error {
+		ok = exist(tx, bucketAccount, name)
+		return nil
+	})
+	return ok, err
+}
+
+// PutRole saves the role, there is no role management api in kie, so roles are seeded by callers
+func PutRole(role *crbac.Role) error {
+	return client.Update(func(tx *bolt.Tx) error {
+		return put(tx, bucketRole, role.Name, role)
+	})
+}
+
+// PutAccount saves the account, there is no account management api in kie, so accounts are seeded by callers
+func PutAccount(account *crbac.Account) error {
+	return client.Update(func(tx *bolt.Tx) error {
+		return put(tx, bucketAccount, account.Name, account)
+	})
+}
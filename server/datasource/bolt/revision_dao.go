@@ -0,0 +1,118 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bolt
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/go-chassis/cari/config"
+	"github.com/go-chassis/openlog"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/apache/servicecomb-kie/server/datasource"
+)
+
+// RevisionDao keeps revision counters in the db file
+type RevisionDao struct {
+}
+
+// GetRevision return current revision number, revisions reserved but not handed out are left out
+func (s *RevisionDao) GetRevision(ctx context.Context, domain string) (int64, error) {
+	counter, err := readCounter(bucketRevision, domain)
+	if err != nil {
+		return 0, err
+	}
+	return datasource.Revisions().Visible(domain, counter), nil
+}
+
+// GetProjectRevision return current revision number of the project
+func (s *RevisionDao) GetProjectRevision(ctx context.Context, project, domain string) (int64, error) {
+	stored, err := readCounter(bucketProjectRevision, join(domain, project))
+	if err != nil {
+		return 0, err
+	}
+	return datasource.Revisions().ProjectRevision(domain, project, stored), nil
+}
+
+// ApplyRevision increase revision number of the domain and the project, return the domain one,
+// the domain revision is handed out from the reserved block, and it is the project revision as well
+func (s *RevisionDao) ApplyRevision(ctx context.Context, project, domain string) (int64, error) {
+	return datasource.Revisions().NextForProject(ctx, domain, project, reserve, storeProject)
+}
+
+// storeProject sets rev as the revision of the project
+func storeProject(ctx context.Context, domain, project string, rev int64) error {
+	err := client.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketProjectRevision).Put([]byte(join(domain, project)), []byte(strconv.FormatInt(rev, 10)))
+	})
+	if err != nil {
+		openlog.Error("apply revision error: " + err.Error())
+		return config.NewError(config.ErrInternal, "apply revision failed")
+	}
+	return nil
+}
+
+// reserve adds n to the domain counter
+func reserve(ctx context.Context, domain string, n int64) (int64, error) {
+	return addCounter(bucketRevision, domain, n)
+}
+
+func readCounter(bucket []byte, key string) (int64, error) {
+	var n int64
+	err := client.View(func(tx *bolt.Tx) error {
+		var err error
+		n, err = counter(tx, bucket, key)
+		return err
+	})
+	if err != nil {
+		openlog.Error("get error: " + err.Error())
+		return 0, err
+	}
+	return n, nil
+}
+
+func addCounter(bucket []byte, key string, delta int64) (int64, error) {
+	var n int64
+	err := client.Update(func(tx *bolt.Tx) error {
+		cur, err := counter(tx, bucket, key)
+		if err != nil {
+			return err
+		}
+		n = cur + delta
+		return tx.Bucket(bucket).Put([]byte(key), []byte(strconv.FormatInt(n, 10)))
+	})
+	if err != nil {
+		openlog.Error("apply revision error: " + err.Error())
+		return 0, config.NewError(config.ErrInternal, "apply revision failed")
+	}
+	return n, nil
+}
+
+func counter(tx *bolt.Tx, bucket []byte, key string) (int64, error) {
+	v := tx.Bucket(bucket).Get([]byte(key))
+	if v == nil {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(string(v), 10, 64)
+	if err != nil {
+		openlog.Error("invalid revision counter: " + err.Error())
+		return 0, err
+	}
+	return n, nil
+}
@@ -0,0 +1,102 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bolt
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-chassis/openlog"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/datasource"
+)
+
+// SchemaDao operate json schemas of keys in the db file
+type SchemaDao struct {
+}
+
+func (d *SchemaDao) Create(ctx context.Context, schema *model.SchemaDoc) (*model.SchemaDoc, error) {
+	err := client.Update(func(tx *bolt.Tx) error {
+		k := join(schema.Domain, schema.Project, schema.ID)
+		if exist(tx, bucketSchema, k) {
+			return datasource.ErrSchemaAlreadyExists
+		}
+		return put(tx, bucketSchema, k, schema)
+	})
+	if err != nil {
+		openlog.Error("create schema error: " + err.Error())
+		return nil, err
+	}
+	return schema, nil
+}
+
+func (d *SchemaDao) Update(ctx context.Context, schema *model.SchemaDoc) error {
+	return client.Update(func(tx *bolt.Tx) error {
+		return put(tx, bucketSchema, join(schema.Domain, schema.Project, schema.ID), schema)
+	})
+}
+
+func (d *SchemaDao) Get(ctx context.Context, id, project, domain string) (*model.SchemaDoc, error) {
+	schema := &model.SchemaDoc{}
+	var ok bool
+	err := client.View(func(tx *bolt.Tx) error {
+		var err error
+		ok, err = get(tx, bucketSchema, join(domain, project, id), schema)
+		return err
+	})
+	if err != nil {
+		openlog.Error(err.Error())
+		return nil, err
+	}
+	if !ok {
+		return nil, datasource.ErrSchemaNotExists
+	}
+	return schema, nil
+}
+
+func (d *SchemaDao) List(ctx context.Context, project, domain string) ([]*model.SchemaDoc, error) {
+	schemas := make([]*model.SchemaDoc, 0)
+	err := client.View(func(tx *bolt.Tx) error {
+		return scan(tx, bucketSchema, prefix(domain, project), func(k, v []byte) error {
+			schema := &model.SchemaDoc{}
+			if err := json.Unmarshal(v, schema); err != nil {
+				openlog.Error("decode schema error: " + err.Error())
+				return nil
+			}
+			schemas = append(schemas, schema)
+			return nil
+		})
+	})
+	if err != nil {
+		openlog.Error(err.Error())
+		return nil, err
+	}
+	return schemas, nil
+}
+
+func (d *SchemaDao) Delete(ctx context.Context, id, project, domain string) error {
+	return client.Update(func(tx *bolt.Tx) error {
+		k := join(domain, project, id)
+		if !exist(tx, bucketSchema, k) {
+			return datasource.ErrSchemaNotExists
+		}
+		return del(tx, bucketSchema, k)
+	})
+}
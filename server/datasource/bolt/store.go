@@ -0,0 +1,161 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bolt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-chassis/openlog"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// DefaultDir is the data directory if db uri is empty
+	DefaultDir = "data"
+	fileName   = "kie.db"
+)
+
+var (
+	bucketKV              = []byte("kv")
+	bucketHistory         = []byte("history")
+	bucketHistoryExpiry   = []byte("history_expiry")
+	bucketTrack           = []byte("track")
+	bucketRevision        = []byte("revision")
+	bucketProjectRevision = []byte("project_revision")
+	bucketOutbox          = []byte("outbox")
+	bucketSchema          = []byte("schema")
+	bucketLabel           = []byte("label")
+	bucketRole            = []byte("role")
+	bucketAccount         = []byte("account")
+	bucketTask            = []byte("task")
+	bucketTombstone       = []byte("tombstone")
+
+	buckets = [][]byte{bucketKV, bucketHistory, bucketHistoryExpiry, bucketTrack, bucketRevision, bucketProjectRevision,
+		bucketOutbox, bucketSchema, bucketLabel, bucketRole, bucketAccount, bucketTask, bucketTombstone}
+)
+
+// errStop stops scanning without error
+var errStop = errors.New("stop scanning")
+
+var (
+	client *bolt.DB
+	stop   chan struct{}
+)
+
+// Open opens the db file in the data directory, it is created if it does not exist,
+// timeout is how long to wait for the file lock, which is held by another kie process using the same directory
+func Open(dir string, timeout time.Duration) error {
+	if dir == "" {
+		dir = DefaultDir
+	}
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return err
+	}
+	db, err := bolt.Open(filepath.Join(dir, fileName), 0600, &bolt.Options{Timeout: timeout})
+	if err != nil {
+		return err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, b := range buckets {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return err
+	}
+	client = db
+	stop = make(chan struct{})
+	go purgeLoop(db, stop)
+	openlog.Info("open db file " + db.Path())
+	return nil
+}
+
+// Close stops purging expired data and closes the db file
+func Close() error {
+	if client == nil {
+		return nil
+	}
+	close(stop)
+	err := client.Close()
+	client = nil
+	return err
+}
+
+func join(parts ...string) string {
+	return strings.Join(parts, "/")
+}
+
+// prefix return the prefix of keys under the parts
+func prefix(parts ...string) []byte {
+	return []byte(join(parts...) + "/")
+}
+
+// get decodes the value of the key into v, it return false if the key does not exist
+func get(tx *bolt.Tx, bucket []byte, key string, v interface{}) (bool, error) {
+	b := tx.Bucket(bucket).Get([]byte(key))
+	if b == nil {
+		return false, nil
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		openlog.Error("decode error: " + err.Error())
+		return false, err
+	}
+	return true, nil
+}
+
+func exist(tx *bolt.Tx, bucket []byte, key string) bool {
+	return tx.Bucket(bucket).Get([]byte(key)) != nil
+}
+
+func put(tx *bolt.Tx, bucket []byte, key string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		openlog.Error("encode error: " + err.Error())
+		return err
+	}
+	return tx.Bucket(bucket).Put([]byte(key), b)
+}
+
+func del(tx *bolt.Tx, bucket []byte, key string) error {
+	return tx.Bucket(bucket).Delete([]byte(key))
+}
+
+// scan calls f with keys starting with p and their values in key order, f returns errStop to stop scanning,
+// keys must not be deleted in f
+func scan(tx *bolt.Tx, bucket, p []byte, f func(k, v []byte) error) error {
+	c := tx.Bucket(bucket).Cursor()
+	for k, v := c.Seek(p); k != nil && bytes.HasPrefix(k, p); k, v = c.Next() {
+		if err := f(k, v); err != nil {
+			if err == errStop {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bolt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-chassis/cari/sync"
+	bolt "go.etcd.io/bbolt"
+
+	edatasource "github.com/apache/servicecomb-service-center/eventbase/datasource"
+	emodel "github.com/apache/servicecomb-service-center/eventbase/model"
+)
+
+// TaskDao keeps sync tasks in the db file
+type TaskDao struct {
+}
+
+// taskKey orders tasks of the project by timestamp
+func taskKey(task *sync.Task) string {
+	return join(task.Domain, task.Project, fmt.Sprintf("%020d", task.Timestamp), task.ID)
+}
+
+func tombstoneKey(tombstone *sync.Tombstone) string {
+	return join(tombstone.Domain, tombstone.Project, tombstone.ResourceType, tombstone.ResourceID)
+}
+
+// listPrefix return the prefix of the domain and the project, the same as the etcd keys of eventbase
+func listPrefix(domain, project string) []byte {
+	if domain == "" {
+		return nil
+	}
+	if project == "" {
+		return prefix(domain)
+	}
+	return prefix(domain, project)
+}
+
+func (d *TaskDao) Create(ctx context.Context, task *sync.Task) (*sync.Task, error) {
+	err := client.Update(func(tx *bolt.Tx) error {
+		k := taskKey(task)
+		if exist(tx, bucketTask, k) {
+			return edatasource.ErrTaskAlreadyExists
+		}
+		return put(tx, bucketTask, k, task)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+func (d *TaskDao) Update(ctx context.Context, task *sync.Task) error {
+	return client.Update(func(tx *bolt.Tx) error {
+		k := taskKey(task)
+		cur := &sync.Task{}
+		ok, err := get(tx, bucketTask, k, cur)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return edatasource.ErrTaskNotExists
+		}
+		cur.Status = task.Status
+		return put(tx, bucketTask, k, cur)
+	})
+}
+
+func (d *TaskDao) Delete(ctx context.Context, tasks ...*sync.Task) error {
+	return client.Update(func(tx *bolt.Tx) error {
+		for _, task := range tasks {
+			if err := del(tx, bucketTask, taskKey(task)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (d *TaskDao) List(ctx context.Context, options ...edatasource.TaskFindOption) ([]*sync.Task, error) {
+	opts := edatasource.NewTaskFindOptions()
+	for _, o := range options {
+		o(&opts)
+	}
+	tasks := make([]*sync.Task, 0)
+	err := client.View(func(tx *bolt.Tx) error {
+		return scan(tx, bucketTask, listPrefix(opts.Domain, opts.Project), func(k, v []byte) error {
+			task := sync.Task{}
+			if err := json.Unmarshal(v, &task); err != nil {
+				return nil
+			}
+			if opts.Action != "" && task.Action != opts.Action {
+				return nil
+			}
+			if opts.ResourceType != "" && task.ResourceType != opts.ResourceType {
+				return nil
+			}
+			if opts.Status != "" && task.Status != opts.Status {
+				return nil
+			}
+			tasks = append(tasks, &task)
+			return nil
+		})
+	})
+	return tasks, err
+}
+
+// TombstoneDao keeps tombstones of deleted kvs in the db file
+type TombstoneDao struct {
+}
+
+func (d *TombstoneDao) Get(ctx context.Context, req *emodel.GetTombstoneRequest) (*sync.Tombstone, error) {
+	tombstone := &sync.Tombstone{}
+	var ok bool
+	err := client.View(func(tx *bolt.Tx) error {
+		var err error
+		ok, err = get(tx, bucketTombstone, join(req.Domain, req.Project, req.ResourceType, req.ResourceID), tombstone)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, edatasource.ErrTombstoneNotExists
+	}
+	return tombstone, nil
+}
+
+func (d *TombstoneDao) Create(ctx context.Context, tombstone *sync.Tombstone) (*sync.Tombstone, error) {
+	err := client.Update(func(tx *bolt.Tx) error {
+		k := tombstoneKey(tombstone)
+		if exist(tx, bucketTombstone, k) {
+			return edatasource.ErrTombstoneAlreadyExists
+		}
+		return put(tx, bucketTombstone, k, tombstone)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tombstone, nil
+}
+
+func (d *TombstoneDao) Delete(ctx context.Context, tombstones ...*sync.Tombstone) error {
+	return client.Update(func(tx *bolt.Tx) error {
+		for _, tombstone := range tombstones {
+			if err := del(tx, bucketTombstone, tombstoneKey(tombstone)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (d *TombstoneDao) List(ctx context.Context, options ...edatasource.TombstoneFindOption) ([]*sync.Tombstone, error) {
+	opts := edatasource.NewTombstoneFindOptions()
+	for _, o := range options {
+		o(&opts)
+	}
+	tombstones := make([]*sync.Tombstone, 0)
+	err := client.View(func(tx *bolt.Tx) error {
+		return scan(tx, bucketTombstone, listPrefix(opts.Domain, opts.Project), func(k, v []byte) error {
+			tombstone := sync.Tombstone{}
+			if err := json.Unmarshal(v, &tombstone); err != nil {
+				return nil
+			}
+			if opts.ResourceType != "" && tombstone.ResourceType != opts.ResourceType {
+				return nil
+			}
+			if opts.BeforeTimestamp != 0 && tombstone.Timestamp > opts.BeforeTimestamp {
+				return nil
+			}
+			tombstones = append(tombstones, &tombstone)
+			return nil
+		})
+	})
+	return tombstones, err
+}
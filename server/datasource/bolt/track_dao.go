@@ -0,0 +1,85 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bolt
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-chassis/openlog"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/datasource"
+)
+
+// TrackDao keeps polling details in the db file, they are purged after they expire
+type TrackDao struct {
+}
+
+// CreateOrUpdate create a record or update exist record
+// If revision and session_id exists then update else insert
+func (s *TrackDao) CreateOrUpdate(ctx context.Context, detail *model.PollingDetail) (*model.PollingDetail, error) {
+	err := client.Update(func(tx *bolt.Tx) error {
+		return put(tx, bucketTrack, join(detail.Domain, detail.Project, detail.Revision, detail.SessionID), detail)
+	})
+	if err != nil {
+		openlog.Error(err.Error())
+		return nil, err
+	}
+	return detail, nil
+}
+
+// GetPollingDetail is to get a track data
+func (s *TrackDao) GetPollingDetail(ctx context.Context, detail *model.PollingDetail) ([]*model.PollingDetail, error) {
+	records := make([]*model.PollingDetail, 0)
+	err := client.View(func(tx *bolt.Tx) error {
+		return scan(tx, bucketTrack, prefix(detail.Domain, detail.Project), func(k, v []byte) error {
+			var doc model.PollingDetail
+			if err := json.Unmarshal(v, &doc); err != nil {
+				openlog.Error("decode polling detail error: " + err.Error())
+				return nil
+			}
+			if detail.SessionID != "" && doc.SessionID != detail.SessionID {
+				return nil
+			}
+			if detail.IP != "" && doc.IP != detail.IP {
+				return nil
+			}
+			if detail.UserAgent != "" && doc.UserAgent != detail.UserAgent {
+				return nil
+			}
+			if detail.URLPath != "" && doc.URLPath != detail.URLPath {
+				return nil
+			}
+			if detail.Revision != "" && doc.Revision != detail.Revision {
+				return nil
+			}
+			records = append(records, &doc)
+			return nil
+		})
+	})
+	if err != nil {
+		openlog.Error(err.Error())
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, datasource.ErrRecordNotExists
+	}
+	return records, nil
+}
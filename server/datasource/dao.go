@@ -20,13 +20,16 @@ package datasource
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/apache/servicecomb-kie/server/datasource/rbac"
 	"github.com/go-chassis/openlog"
 
 	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/pkg/stringutil"
 )
 
 var (
@@ -40,6 +43,14 @@ var (
 	ErrRevisionNotExist = errors.New("revision does not exist")
 	ErrKVAlreadyExists  = errors.New("kv already exists")
 	ErrTooMany          = errors.New("key with labels should be only one")
+	ErrKVConflict       = errors.New("kvs were changed concurrently")
+	ErrSearchTimeout    = errors.New("search exceeded the time limit, simplify the regexes or narrow the filters")
+
+	ErrSchemaNotExists     = errors.New("can not find the schema")
+	ErrSchemaAlreadyExists = errors.New("schema of the key already exists")
+
+	ErrLabelNotExists = errors.New("can not find the label set")
+	ErrLabelInUse     = errors.New("the label set is still used by key values")
 )
 
 const (
@@ -63,12 +74,24 @@ type Broker interface {
 	GetTrackDao() TrackDao
 	GetKVDao() KVDao
 	GetRbacDao() rbac.Dao
+	GetOutboxDao() OutboxDao
+	GetSchemaDao() SchemaDao
+	GetLabelDao() LabelDao
 }
 
 func GetBroker() Broker {
 	return b
 }
 
+// KVTxn is kv mutations written in one transaction,
+// kvs to update and delete must exist, and their update revisions must be the same as Revisions by id if present
+type KVTxn struct {
+	Creates   []*model.KVDoc
+	Updates   []*model.KVDoc
+	Deletes   []*model.KVDoc
+	Revisions map[string]int64
+}
+
 // KVDao provide api of KV entity
 type KVDao interface {
 	// Create Update List are usually for admin console
@@ -79,6 +102,13 @@ type KVDao interface {
 	FindOneAndDelete(ctx context.Context, kvID string, project, domain string, options ...WriteOption) (*model.KVDoc, error)
 	//FindManyAndDelete deletes multiple kvs and return the deleted kv list as these appeared before deletion
 	FindManyAndDelete(ctx context.Context, kvIDs []string, project, domain string, options ...WriteOption) ([]*model.KVDoc, int64, error)
+	//Move replaces kv old by kv of another label set in a transaction, the revisions of old are carried over to kv,
+	//the existing kv with the id of kv is overwritten if override is true, or ErrKVAlreadyExists is returned,
+	//ErrKVConflict is returned if old is changed since it was read
+	Move(ctx context.Context, old, kv *model.KVDoc, override bool, options ...WriteOption) error
+	//Batch writes kvs in one transaction, nothing is written if any of them fails,
+	//ErrKVConflict is returned if a kv to create exists, or a kv to update or delete is changed
+	Batch(ctx context.Context, txn *KVTxn, options ...WriteOption) error
 
 	//Get return kv by id
 	Get(ctx context.Context, req *model.GetKVRequest) (*model.KVDoc, error)
@@ -101,10 +131,34 @@ type TrackDao interface {
 	GetPollingDetail(ctx context.Context, detail *model.PollingDetail) ([]*model.PollingDetail, error)
 }
 
+// SchemaDao persists json schemas of keys
+type SchemaDao interface {
+	Create(ctx context.Context, schema *model.SchemaDoc) (*model.SchemaDoc, error)
+	Update(ctx context.Context, schema *model.SchemaDoc) error
+	Get(ctx context.Context, id, project, domain string) (*model.SchemaDoc, error)
+	List(ctx context.Context, project, domain string) ([]*model.SchemaDoc, error)
+	Delete(ctx context.Context, id, project, domain string) error
+}
+
+// LabelDao is the catalog of label sets used by kvs,
+// kv numbers of label sets are maintained by KVDao on kv creation and deletion
+type LabelDao interface {
+	List(ctx context.Context, project, domain string) ([]*model.LabelDoc, error)
+	// UpdateAlias sets the alias of the label set by id
+	UpdateAlias(ctx context.Context, id, project, domain, alias string) (*model.LabelDoc, error)
+	// DeleteOrphan deletes the label set by id, only if no kv uses it
+	DeleteOrphan(ctx context.Context, id, project, domain string) error
+}
+
 // RevisionDao is global revision number management
 type RevisionDao interface {
 	GetRevision(ctx context.Context, domain string) (int64, error)
-	ApplyRevision(ctx context.Context, domain string) (int64, error)
+	// GetProjectRevision returns the revision of the project, it is the domain revision of the last write of the project,
+	// so it only moves on when kvs of the project are changed
+	GetProjectRevision(ctx context.Context, project, domain string) (int64, error)
+	// ApplyRevision increases the revision of the domain and moves the project revision to it,
+	// the domain revision is returned as the revision of kvs
+	ApplyRevision(ctx context.Context, project, domain string) (int64, error)
 }
 
 // ViewDao create update and get view data
@@ -117,16 +171,20 @@ type ViewDao interface {
 	GetContent(ctx context.Context, id, domain, project string, options ...FindOption) ([]*model.KVResponse, error)
 }
 
-func Init(kind string) error {
+func Init(kind string, options ...InitOption) error {
 	var err error
 	f, ok := plugins[kind]
 	if !ok {
 		return fmt.Errorf("do not support '%s'", kind)
 	}
 	dbc := &Config{}
+	for _, o := range options {
+		o(dbc)
+	}
 	if b, err = f(dbc); err != nil {
 		return err
 	}
+	Revisions().SetBlockSize(dbc.RevisionBlock)
 	openlog.Info(fmt.Sprintf("use %s as storage", kind))
 	return nil
 }
@@ -138,6 +196,43 @@ func ClearPart(kv *model.KVDoc) {
 	kv.LabelFormat = ""
 }
 
+// LabelID return the id of the label set in the project
+func LabelID(domain, project, format string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join([]string{domain, project, format}, "/"))))
+}
+
+// KVLabelFormat return the label format of the kv, it is formatted from the labels if it is not set
+func KVLabelFormat(kv *model.KVDoc) string {
+	if kv.LabelFormat != "" {
+		return kv.LabelFormat
+	}
+	return stringutil.FormatMap(kv.Labels)
+}
+
+// CountLabels groups kvs of the project by label set, the count of each label set is the kv number multiplied by delta
+func CountLabels(domain, project string, kvs []*model.KVDoc, delta int64) []*model.LabelDoc {
+	docs := make([]*model.LabelDoc, 0)
+	index := make(map[string]*model.LabelDoc)
+	for _, kv := range kvs {
+		format := KVLabelFormat(kv)
+		id := LabelID(domain, project, format)
+		doc, ok := index[id]
+		if !ok {
+			doc = &model.LabelDoc{
+				ID:      id,
+				Labels:  kv.Labels,
+				Format:  format,
+				Domain:  domain,
+				Project: project,
+			}
+			index[id] = doc
+			docs = append(docs, doc)
+		}
+		doc.Count += delta
+	}
+	return docs
+}
+
 // TombstoneID return tombstone's resourceID, using key and labelFormat as resourceID
 func TombstoneID(kv *model.KVDoc) string {
 	return kv.Key + "/" + kv.LabelFormat
@@ -19,42 +19,134 @@ package counter
 
 import (
 	"context"
+	"strconv"
+	"sync"
 
 	"github.com/apache/servicecomb-kie/server/datasource"
 	"github.com/apache/servicecomb-kie/server/datasource/etcd/key"
 	"github.com/go-chassis/cari/config"
 	"github.com/go-chassis/openlog"
 	"github.com/little-cui/etcdadpt"
+	"go.etcd.io/etcd/api/v3/mvccpb"
 )
 
-const revision = "revision_counter"
+const (
+	revision          = "revision_counter"
+	maxReserveRetries = 10
+)
+
+// lastSeen is the domain counters written by this node
+var lastSeen sync.Map
 
 // Dao is the implementation
 type Dao struct {
 }
 
-// GetRevision return current revision number
+// GetRevision return current revision number, revisions reserved but not handed out are left out
 func (s *Dao) GetRevision(ctx context.Context, domain string) (int64, error) {
 	kv, err := etcdadpt.Get(ctx, key.Counter(revision, domain))
 	if err != nil {
 		openlog.Error("get error: " + err.Error())
 		return 0, err
 	}
-	if kv == nil {
-		return 0, nil
+	counter, err := value(kv)
+	if err != nil {
+		return 0, err
+	}
+	return datasource.Revisions().Visible(domain, counter), nil
+}
+
+// GetProjectRevision return current revision number of the project
+func (s *Dao) GetProjectRevision(ctx context.Context, project, domain string) (int64, error) {
+	kv, err := etcdadpt.Get(ctx, key.ProjectCounter(revision, domain, project))
+	if err != nil {
+		openlog.Error("get error: " + err.Error())
+		return 0, err
+	}
+	stored, err := value(kv)
+	if err != nil {
+		return 0, err
 	}
-	return kv.Version, nil
+	return datasource.Revisions().ProjectRevision(domain, project, stored), nil
 }
 
-// ApplyRevision increase revision number and return modified value
-func (s *Dao) ApplyRevision(ctx context.Context, domain string) (int64, error) {
-	resp, err := etcdadpt.PutBytesAndGet(ctx, key.Counter(revision, domain), nil)
+// ApplyRevision increase revision number of the domain and the project, return the domain one,
+// the domain revision is handed out from the reserved block, and it is the project revision as well
+func (s *Dao) ApplyRevision(ctx context.Context, project, domain string) (int64, error) {
+	return datasource.Revisions().NextForProject(ctx, domain, project, reserve, storeProject)
+}
+
+// storeProject puts rev as the revision of the project
+func storeProject(ctx context.Context, domain, project string, rev int64) error {
+	err := etcdadpt.PutBytes(ctx, key.ProjectCounter(revision, domain, project), []byte(strconv.FormatInt(rev, 10)))
 	if err != nil {
-		openlog.Error("put bytes error: " + err.Error())
-		return 0, config.NewError(config.ErrInternal, "apply revision failed")
+		openlog.Error("put project revision error: " + err.Error())
+		return config.NewError(config.ErrInternal, "apply revision failed")
+	}
+	return nil
+}
+
+// reserve adds n to the domain counter by compare and swap,
+// the counter seen last time is compared first, so that it costs one round trip if no other node reserves
+func reserve(ctx context.Context, domain string, n int64) (int64, error) {
+	k := key.Counter(revision, domain)
+	seen, _ := lastSeen.Load(k)
+	kv, _ := seen.(*mvccpb.KeyValue)
+	var err error
+	if kv == nil {
+		if kv, err = etcdadpt.Get(ctx, k); err != nil {
+			openlog.Error("get error: " + err.Error())
+			return 0, err
+		}
 	}
-	if resp.Count == 0 {
-		return 0, datasource.ErrRevisionNotExist
+	for i := 0; i < maxReserveRetries; i++ {
+		current, err := value(kv)
+		if err != nil {
+			return 0, err
+		}
+		cmp := etcdadpt.NotExistKey(k)
+		if kv != nil {
+			cmp = etcdadpt.EqualModRev(k, kv.ModRevision)
+		}
+		reserved := current + n
+		resp, err := etcdadpt.TxnWithCmp(ctx,
+			[]etcdadpt.OpOptions{etcdadpt.OpPut(etcdadpt.WithStrKey(k), etcdadpt.WithStrValue(strconv.FormatInt(reserved, 10)))},
+			etcdadpt.If(cmp),
+			[]etcdadpt.OpOptions{etcdadpt.OpGet(etcdadpt.WithStrKey(k))})
+		if err != nil {
+			openlog.Error("reserve revisions error: " + err.Error())
+			return 0, config.NewError(config.ErrInternal, "apply revision failed")
+		}
+		if resp.Succeeded {
+			lastSeen.Store(k, &mvccpb.KeyValue{
+				Key:         []byte(k),
+				Value:       []byte(strconv.FormatInt(reserved, 10)),
+				ModRevision: resp.Revision,
+			})
+			return reserved, nil
+		}
+		kv = nil
+		if len(resp.Kvs) != 0 {
+			kv = resp.Kvs[0]
+		}
+	}
+	lastSeen.Delete(k)
+	return 0, datasource.ErrKVConflict
+}
+
+// value returns the revision in the counter, the counter was a key without value, and its version is the revision,
+// the version of a project counter is the number of writes, which is never larger than the domain revisions
+func value(kv *mvccpb.KeyValue) (int64, error) {
+	if kv == nil {
+		return 0, nil
+	}
+	if len(kv.Value) == 0 {
+		return kv.Version, nil
+	}
+	v, err := strconv.ParseInt(string(kv.Value), 10, 64)
+	if err != nil {
+		openlog.Error("invalid revision counter: " + err.Error())
+		return 0, err
 	}
-	return resp.Kvs[0].Version, nil
+	return v, nil
 }
@@ -22,7 +22,10 @@ import (
 	"github.com/apache/servicecomb-kie/server/datasource/etcd/counter"
 	"github.com/apache/servicecomb-kie/server/datasource/etcd/history"
 	"github.com/apache/servicecomb-kie/server/datasource/etcd/kv"
+	"github.com/apache/servicecomb-kie/server/datasource/etcd/label"
+	"github.com/apache/servicecomb-kie/server/datasource/etcd/outbox"
 	"github.com/apache/servicecomb-kie/server/datasource/etcd/rbac"
+	"github.com/apache/servicecomb-kie/server/datasource/etcd/schema"
 	"github.com/apache/servicecomb-kie/server/datasource/etcd/track"
 	rbacdao "github.com/apache/servicecomb-kie/server/datasource/rbac"
 )
@@ -49,6 +52,15 @@ func (*Broker) GetTrackDao() datasource.TrackDao {
 func (*Broker) GetRbacDao() rbacdao.Dao {
 	return &rbac.Dao{}
 }
+func (*Broker) GetOutboxDao() datasource.OutboxDao {
+	return &outbox.Dao{}
+}
+func (*Broker) GetSchemaDao() datasource.SchemaDao {
+	return &schema.Dao{}
+}
+func (*Broker) GetLabelDao() datasource.LabelDao {
+	return &label.Dao{}
+}
 
 func init() {
 	datasource.RegisterPlugin("etcd", NewFrom)
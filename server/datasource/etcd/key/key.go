@@ -31,6 +31,9 @@ const (
 	syncer     = "syncer"
 	task       = "task"
 	tombstone  = "tombstone"
+	outbox     = "outbox"
+	keySchema  = "schema"
+	keyLabel   = "label"
 )
 
 func getSyncRootKey() string {
@@ -41,6 +44,10 @@ func getTombstoneRootKey() string {
 	return split + tombstone
 }
 
+func getOutboxRootKey() string {
+	return split + outbox
+}
+
 func TaskKey(domain, project, taskID string, timestamp int64) string {
 	strTimestamp := strconv.FormatInt(timestamp, 10)
 	return strings.Join([]string{getSyncRootKey(), domain, project, strTimestamp, taskID}, split)
@@ -50,6 +57,20 @@ func TombstoneKey(domain, project, resourceType, resourceID string) string {
 	return strings.Join([]string{getTombstoneRootKey(), domain, project, resourceType, resourceID}, split)
 }
 
+// Outbox is sorted by timestamp, so that events are delivered in order
+func Outbox(eventID string, timestamp int64) string {
+	return strings.Join([]string{getOutboxRootKey(), strconv.FormatInt(timestamp, 10), eventID}, split)
+}
+
+func OutboxList() string {
+	return getOutboxRootKey() + split
+}
+
+// OutboxListEnd is the end of the key range of outbox events, it is the key next to all of them
+func OutboxListEnd() string {
+	return getOutboxRootKey() + string(rune(split[0]+1))
+}
+
 func KV(domain, project, kvID string) string {
 	return strings.Join([]string{keyKV, domain, project, kvID}, split)
 }
@@ -61,10 +82,30 @@ func KVList(domain, project string) string {
 	return strings.Join([]string{keyKV, domain, project, ""}, split)
 }
 
+func Schema(domain, project, schemaID string) string {
+	return strings.Join([]string{keySchema, domain, project, schemaID}, split)
+}
+
+func SchemaList(domain, project string) string {
+	return strings.Join([]string{keySchema, domain, project, ""}, split)
+}
+
+func Label(domain, project, labelID string) string {
+	return strings.Join([]string{keyLabel, domain, project, labelID}, split)
+}
+
+func LabelList(domain, project string) string {
+	return strings.Join([]string{keyLabel, domain, project, ""}, split)
+}
+
 func Counter(name, domain string) string {
 	return strings.Join([]string{keyCounter, domain, name}, split)
 }
 
+func ProjectCounter(name, domain, project string) string {
+	return strings.Join([]string{keyCounter, domain, project, name}, split)
+}
+
 func His(domain, project, kvID string, updateRevision int64) string {
 	return strings.Join([]string{keyHistory, domain, project, kvID,
 		strconv.FormatInt(updateRevision, 10)}, split)
@@ -17,6 +17,7 @@ import (
 
 	"github.com/apache/servicecomb-kie/pkg/model"
 	"github.com/apache/servicecomb-kie/pkg/stringutil"
+	"github.com/apache/servicecomb-kie/pkg/util"
 	"github.com/apache/servicecomb-kie/server/datasource"
 	"github.com/apache/servicecomb-kie/server/datasource/etcd/key"
 )
@@ -63,6 +64,8 @@ type CacheSearchReq struct {
 	Project string
 	Opts    *datasource.FindOptions
 	Regex   *regexp.Regexp
+	Search  *datasource.SearchRegex
+	Allow   func(*model.KVDoc) bool
 }
 
 func (kc *Cache) Refresh(ctx context.Context) {
@@ -222,46 +225,39 @@ func (kc *Cache) DeleteKvDoc(kvID string) {
 }
 
 func Search(ctx context.Context, req *CacheSearchReq) (*model.KVResponse, bool, error) {
-	if !req.Opts.ExactLabels {
+	c := datasource.NewKVCollector(*req.Opts, req.Allow)
+	var kvIDs []string
+	switch {
+	case req.Opts.ExactLabels:
+		openlog.Debug(fmt.Sprintf("using cache to search kv, domain %v, project %v, opts %+v", req.Domain, req.Project, *req.Opts))
+		cacheKey := kvCache.GetCacheKey(req.Domain, req.Project, req.Opts.Labels)
+		kvIds, ok := kvCache.LoadKvIDSet(cacheKey)
+		if !ok {
+			kvCache.StoreKvIDSet(cacheKey, &sync.Map{})
+			return c.Done(), true, nil
+		}
+		kvIds.Range(func(kvID, value any) bool {
+			kvIDs = append(kvIDs, kvID.(string))
+			return true
+		})
+	case req.Search != nil:
+		openlog.Debug(fmt.Sprintf("using cache to search kv of project, domain %v, project %v", req.Domain, req.Project))
+		kvIDs = kvCache.loadProjectKvIDs(req.Domain, req.Project)
+	default:
 		return nil, false, nil
 	}
 
-	openlog.Debug(fmt.Sprintf("using cache to search kv, domain %v, project %v, opts %+v", req.Domain, req.Project, *req.Opts))
-	result := &model.KVResponse{
-		Data: []*model.KVDoc{},
-	}
-	cacheKey := kvCache.GetCacheKey(req.Domain, req.Project, req.Opts.Labels)
-	kvIds, ok := kvCache.LoadKvIDSet(cacheKey)
-	if !ok {
-		kvCache.StoreKvIDSet(cacheKey, &sync.Map{})
-		return result, true, nil
-	}
-
-	var docs []*model.KVDoc
-
-	var kvIdsLeft []string
-	kvIds.Range(func(kvID, value any) bool {
-		if doc, ok := kvCache.LoadKvDoc(kvID.(string)); ok {
-			docs = append(docs, doc)
-		} else {
-			kvIdsLeft = append(kvIdsLeft, kvID.(string))
-		}
-		return true
-	})
-	tpData, err := kvCache.getKvFromEtcd(ctx, req, kvIdsLeft)
+	docs, err := kvCache.loadKvDocs(ctx, req, kvIDs)
 	if err != nil {
 		return nil, true, err
 	}
-	docs = append(docs, tpData...)
-
 	for _, doc := range docs {
 		if isMatch(req, doc) {
 			datasource.ClearPart(doc)
-			result.Data = append(result.Data, doc)
+			c.Add(doc)
 		}
 	}
-	result.Total = len(result.Data)
-	return result, true, nil
+	return c.Done(), true, nil
 }
 
 func (kc *Cache) getKvFromEtcd(ctx context.Context, req *CacheSearchReq, kvIdsLeft []string) ([]*model.KVDoc, error) {
@@ -311,13 +307,22 @@ func isMatch(req *CacheSearchReq, doc *model.KVDoc) bool {
 	if req.Opts.Status != "" && doc.Status != req.Opts.Status {
 		return false
 	}
+	if !datasource.MatchIDs(doc, *req.Opts) {
+		return false
+	}
 	if req.Regex != nil && !req.Regex.MatchString(doc.Key) {
 		return false
 	}
 	if req.Opts.Value != "" && !strings.Contains(doc.Value, req.Opts.Value) {
 		return false
 	}
-	return true
+	if len(req.Opts.Selector) != 0 && !req.Opts.Selector.Matches(doc.Labels) {
+		return false
+	}
+	if !req.Opts.ExactLabels && !util.IsContainLabel(doc.Labels, req.Opts.Labels) {
+		return false
+	}
+	return req.Search.Match(doc, *req.Opts)
 }
 
 func (kc *Cache) GetKvDoc(kv *mvccpb.KeyValue) (*model.KVDoc, error) {
@@ -3,6 +3,10 @@ package kv
 import (
 	"testing"
 
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/pkg/selector"
+	"github.com/apache/servicecomb-kie/server/datasource"
+
 	"github.com/little-cui/etcdadpt"
 	"github.com/stretchr/testify/assert"
 	"go.etcd.io/etcd/api/v3/mvccpb"
@@ -96,3 +100,17 @@ func TestCacheDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestIsMatch(t *testing.T) {
+	doc := &model.KVDoc{Key: "withFruit", Value: "no", Labels: map[string]string{"environment": "testing"}}
+	opts := datasource.NewDefaultFindOpts()
+	req := &CacheSearchReq{Opts: &opts}
+	assert.True(t, isMatch(req, doc))
+
+	opts.Selector = selector.Selector{{Key: "environment", Operator: selector.OpIn, Values: []string{"testing", "dev"}}}
+	assert.True(t, isMatch(req, doc))
+	opts.Selector = selector.Selector{{Key: "environment", Operator: selector.OpNotEquals, Values: []string{"testing"}}}
+	assert.False(t, isMatch(req, doc))
+	opts.Selector = selector.Selector{{Key: "region", Operator: selector.OpNotExists}}
+	assert.True(t, isMatch(req, doc))
+}
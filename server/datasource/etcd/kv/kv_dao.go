@@ -21,17 +21,17 @@ import (
 	"context"
 	"encoding/json"
 	"regexp"
-	"strings"
 
 	"github.com/go-chassis/cari/sync"
 	"github.com/go-chassis/openlog"
 	"github.com/little-cui/etcdadpt"
 
 	"github.com/apache/servicecomb-kie/pkg/model"
-	"github.com/apache/servicecomb-kie/pkg/util"
 	"github.com/apache/servicecomb-kie/server/datasource"
 	"github.com/apache/servicecomb-kie/server/datasource/auth"
 	"github.com/apache/servicecomb-kie/server/datasource/etcd/key"
+	"github.com/apache/servicecomb-kie/server/datasource/etcd/label"
+	"github.com/apache/servicecomb-kie/server/datasource/etcd/outbox"
 )
 
 // Dao operate data in mongodb
@@ -46,9 +46,9 @@ func (s *Dao) Create(ctx context.Context, kv *model.KVDoc, options ...datasource
 	opts := datasource.NewWriteOptions(options...)
 	var exist bool
 	var err error
-	if opts.SyncEnable {
+	if opts.InTxn() {
 		// if syncEnable is true, will create task in a transaction operation
-		exist, err = txnCreate(ctx, kv)
+		exist, err = txnCreate(ctx, kv, opts)
 	} else {
 		exist, err = create(ctx, kv)
 	}
@@ -66,6 +66,7 @@ func (s *Dao) Create(ctx context.Context, kv *model.KVDoc, options ...datasource
 		}))
 		return nil, datasource.ErrKVAlreadyExists
 	}
+	label.Count(ctx, datasource.CountLabels(kv.Domain, kv.Project, []*model.KVDoc{kv}, 1))
 	return kv, nil
 }
 
@@ -78,26 +79,38 @@ func create(ctx context.Context, kv *model.KVDoc) (bool, error) {
 	return etcdadpt.InsertBytes(ctx, key.KV(kv.Domain, kv.Project, kv.ID), kvBytes)
 }
 
-func txnCreate(ctx context.Context, kv *model.KVDoc) (bool, error) {
+func txnCreate(ctx context.Context, kv *model.KVDoc, opts datasource.WriteOptions) (bool, error) {
 	kvBytes, err := json.Marshal(kv)
 	if err != nil {
 		openlog.Error("fail to marshal kv " + err.Error())
 		return false, err
 	}
-	task, err := sync.NewTask(kv.Domain, kv.Project, sync.CreateAction, datasource.ConfigResource, kv)
-	if err != nil {
-		openlog.Error("fail to create task" + err.Error())
-		return false, err
+	kvOpPut := etcdadpt.OpPut(etcdadpt.WithStrKey(key.KV(kv.Domain, kv.Project, kv.ID)), etcdadpt.WithValue(kvBytes))
+	ops := []etcdadpt.OpOptions{kvOpPut}
+	cmps := []etcdadpt.CmpOptions{etcdadpt.NotExistKey(string(kvOpPut.Key))}
+	if opts.SyncEnable {
+		task, err := sync.NewTask(kv.Domain, kv.Project, sync.CreateAction, datasource.ConfigResource, kv)
+		if err != nil {
+			openlog.Error("fail to create task" + err.Error())
+			return false, err
+		}
+		taskBytes, err := json.Marshal(task)
+		if err != nil {
+			openlog.Error("fail to marshal task ")
+			return false, err
+		}
+		taskOpPut := etcdadpt.OpPut(etcdadpt.WithStrKey(key.TaskKey(kv.Domain, kv.Project, task.ID, task.Timestamp)), etcdadpt.WithValue(taskBytes))
+		ops = append(ops, taskOpPut)
+		cmps = append(cmps, etcdadpt.NotExistKey(string(taskOpPut.Key)))
 	}
-	taskBytes, err := json.Marshal(task)
-	if err != nil {
-		openlog.Error("fail to marshal task ")
-		return false, err
+	if opts.OutboxEnable {
+		eventOpPut, err := outbox.OpPutEvent(kv.Domain, kv.Project, kv, datasource.OutboxActionPut, opts.Node)
+		if err != nil {
+			return false, err
+		}
+		ops = append(ops, eventOpPut)
 	}
-	kvOpPut := etcdadpt.OpPut(etcdadpt.WithStrKey(key.KV(kv.Domain, kv.Project, kv.ID)), etcdadpt.WithValue(kvBytes))
-	taskOpPut := etcdadpt.OpPut(etcdadpt.WithStrKey(key.TaskKey(kv.Domain, kv.Project, task.ID, task.Timestamp)), etcdadpt.WithValue(taskBytes))
-	resp, err := etcdadpt.TxnWithCmp(ctx, []etcdadpt.OpOptions{kvOpPut, taskOpPut},
-		etcdadpt.If(etcdadpt.NotExistKey(string(kvOpPut.Key)), etcdadpt.NotExistKey(string(taskOpPut.Key))), nil)
+	resp, err := etcdadpt.TxnWithCmp(ctx, ops, etcdadpt.If(cmps...), nil)
 	if err != nil {
 		return false, err
 	}
@@ -134,9 +147,9 @@ func (s *Dao) Update(ctx context.Context, kv *model.KVDoc, options ...datasource
 	oldKV.UpdateRevision = kv.UpdateRevision
 
 	opts := datasource.NewWriteOptions(options...)
-	if opts.SyncEnable {
+	if opts.InTxn() {
 		// if syncEnable is true, will create task in a transaction operation
-		err = txnUpdate(ctx, kv)
+		err = txnUpdate(ctx, kv, opts)
 	} else {
 		err = update(ctx, &oldKV, options...)
 	}
@@ -147,26 +160,35 @@ func (s *Dao) Update(ctx context.Context, kv *model.KVDoc, options ...datasource
 	return nil
 }
 
-func txnUpdate(ctx context.Context, kv *model.KVDoc) error {
+func txnUpdate(ctx context.Context, kv *model.KVDoc, opts datasource.WriteOptions) error {
 	keyKV := key.KV(kv.Domain, kv.Project, kv.ID)
 	kvBytes, err := json.Marshal(kv)
 	if err != nil {
 		openlog.Error(err.Error())
 		return err
 	}
-	task, err := sync.NewTask(kv.Domain, kv.Project, sync.UpdateAction, datasource.ConfigResource, kv)
-	if err != nil {
-		openlog.Error("fail to create task" + err.Error())
-		return err
+	ops := []etcdadpt.OpOptions{etcdadpt.OpPut(etcdadpt.WithStrKey(keyKV), etcdadpt.WithValue(kvBytes))}
+	if opts.SyncEnable {
+		task, err := sync.NewTask(kv.Domain, kv.Project, sync.UpdateAction, datasource.ConfigResource, kv)
+		if err != nil {
+			openlog.Error("fail to create task" + err.Error())
+			return err
+		}
+		taskBytes, err := json.Marshal(task)
+		if err != nil {
+			openlog.Error(err.Error())
+			return err
+		}
+		ops = append(ops, etcdadpt.OpPut(etcdadpt.WithStrKey(key.TaskKey(kv.Domain, kv.Project, task.ID, task.Timestamp)), etcdadpt.WithValue(taskBytes)))
 	}
-	taskBytes, err := json.Marshal(task)
-	if err != nil {
-		openlog.Error(err.Error())
-		return err
+	if opts.OutboxEnable {
+		eventOpPut, err := outbox.OpPutEvent(kv.Domain, kv.Project, kv, datasource.OutboxActionPut, opts.Node)
+		if err != nil {
+			return err
+		}
+		ops = append(ops, eventOpPut)
 	}
-	kvOpPut := etcdadpt.OpPut(etcdadpt.WithStrKey(keyKV), etcdadpt.WithValue(kvBytes))
-	taskOpPut := etcdadpt.OpPut(etcdadpt.WithStrKey(key.TaskKey(kv.Domain, kv.Project, task.ID, task.Timestamp)), etcdadpt.WithValue(taskBytes))
-	return etcdadpt.Txn(ctx, []etcdadpt.OpOptions{kvOpPut, taskOpPut})
+	return etcdadpt.Txn(ctx, ops)
 }
 
 func update(ctx context.Context, kv *model.KVDoc, options ...datasource.WriteOption) error {
@@ -179,13 +201,6 @@ func update(ctx context.Context, kv *model.KVDoc, options ...datasource.WriteOpt
 	return etcdadpt.PutBytes(ctx, keyKV, kvBytes)
 }
 
-// Extract key values
-func getValue(str string) string {
-	rex := regexp.MustCompile(`\(([^)]+)\)`)
-	res := rex.FindStringSubmatch(str)
-	return res[len(res)-1]
-}
-
 // Exist supports you query a key value by label map or labels id
 func (s *Dao) Exist(ctx context.Context, key, project, domain string, options ...datasource.FindOption) (bool, error) {
 	opts := datasource.FindOptions{Key: key}
@@ -202,7 +217,7 @@ func (s *Dao) Exist(ctx context.Context, key, project, domain string, options ..
 		openlog.Error("check kv exist: " + err.Error())
 		return false, err
 	}
-	if IsUniqueFind(opts) && len(kvs.Data) == 0 {
+	if datasource.IsUniqueFind(opts) && len(kvs.Data) == 0 {
 		return false, nil
 	}
 	if len(kvs.Data) != 1 {
@@ -226,7 +241,7 @@ func (s *Dao) GetByKey(ctx context.Context, key, project, domain string, options
 		openlog.Error("check kv exist: " + err.Error())
 		return nil, err
 	}
-	if IsUniqueFind(opts) && len(kvs.Data) == 0 {
+	if datasource.IsUniqueFind(opts) && len(kvs.Data) == 0 {
 		return nil, datasource.ErrKeyNotExists
 	}
 	if len(kvs.Data) != 1 {
@@ -239,11 +254,19 @@ func (s *Dao) GetByKey(ctx context.Context, key, project, domain string, options
 // domain=tenant
 func (s *Dao) FindOneAndDelete(ctx context.Context, kvID, project, domain string, options ...datasource.WriteOption) (*model.KVDoc, error) {
 	opts := datasource.NewWriteOptions(options...)
-	if opts.SyncEnable {
+	var kv *model.KVDoc
+	var err error
+	if opts.InTxn() {
 		// if syncEnable is ture, will delete kv, create task and create tombstone in a transaction operation
-		return txnFindOneAndDelete(ctx, kvID, project, domain)
+		kv, err = txnFindOneAndDelete(ctx, kvID, project, domain, opts)
+	} else {
+		kv, err = findOneAndDelete(ctx, kvID, project, domain)
+	}
+	if err != nil {
+		return nil, err
 	}
-	return findOneAndDelete(ctx, kvID, project, domain)
+	label.Count(ctx, datasource.CountLabels(domain, project, []*model.KVDoc{kv}, -1))
+	return kv, nil
 }
 
 func findOneAndDelete(ctx context.Context, kvID, project, domain string) (*model.KVDoc, error) {
@@ -271,13 +294,40 @@ func findOneAndDelete(ctx context.Context, kvID, project, domain string) (*model
 }
 
 // txnFindOneAndDelete is to start transaction when delete KV, will create task and tombstone in a transaction operation
-func txnFindOneAndDelete(ctx context.Context, kvID, project, domain string) (*model.KVDoc, error) {
+func txnFindOneAndDelete(ctx context.Context, kvID, project, domain string, opts datasource.WriteOptions) (*model.KVDoc, error) {
 	kvKey := key.KV(domain, project, kvID)
 	kvDoc, err := getKVDoc(ctx, domain, project, kvID)
 	if err != nil {
 		openlog.Error(err.Error())
 		return nil, err
 	}
+	ops := []etcdadpt.OpOptions{etcdadpt.OpDel(etcdadpt.WithStrKey(kvKey))}
+	if opts.SyncEnable {
+		syncOps, err := syncDeleteOps(domain, project, kvDoc)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, syncOps...)
+	}
+	if opts.OutboxEnable {
+		eventOpPut, err := outbox.OpPutEvent(domain, project, kvDoc, datasource.OutboxActionDelete, opts.Node)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, eventOpPut)
+	}
+	err = etcdadpt.Txn(ctx, ops)
+	if err != nil {
+		openlog.Error("find and delete error", openlog.WithTags(openlog.Tags{
+			"err": err.Error(),
+		}))
+		return nil, err
+	}
+	return kvDoc, nil
+}
+
+// syncDeleteOps return ops to create task and tombstone of the deleted kv
+func syncDeleteOps(domain, project string, kvDoc *model.KVDoc) ([]etcdadpt.OpOptions, error) {
 	task, err := sync.NewTask(domain, project, sync.DeleteAction, datasource.ConfigResource, kvDoc)
 	if err != nil {
 		openlog.Error("fail to create task" + err.Error())
@@ -294,18 +344,10 @@ func txnFindOneAndDelete(ctx context.Context, kvID, project, domain string) (*mo
 		openlog.Error("fail to marshal tombstone" + err.Error())
 		return nil, err
 	}
-	kvOpDel := etcdadpt.OpDel(etcdadpt.WithStrKey(kvKey))
 	taskOpPut := etcdadpt.OpPut(etcdadpt.WithStrKey(key.TaskKey(domain, project,
 		task.ID, task.Timestamp)), etcdadpt.WithValue(taskBytes))
 	tombstoneOpPut := etcdadpt.OpPut(etcdadpt.WithStrKey(key.TombstoneKey(domain, project, tombstone.ResourceType, tombstone.ResourceID)), etcdadpt.WithValue(tombstoneBytes))
-	err = etcdadpt.Txn(ctx, []etcdadpt.OpOptions{kvOpDel, taskOpPut, tombstoneOpPut})
-	if err != nil {
-		openlog.Error("find and delete error", openlog.WithTags(openlog.Tags{
-			"err": err.Error(),
-		}))
-		return nil, err
-	}
-	return kvDoc, nil
+	return []etcdadpt.OpOptions{taskOpPut, tombstoneOpPut}, nil
 }
 
 // getKVDoc is to get kv for delete
@@ -335,11 +377,20 @@ func getKVDoc(ctx context.Context, domain, project, kvID string) (*model.KVDoc,
 // FindManyAndDelete deletes multiple kvs and return the deleted kv list as these appeared before deletion
 func (s *Dao) FindManyAndDelete(ctx context.Context, kvIDs []string, project, domain string, options ...datasource.WriteOption) ([]*model.KVDoc, int64, error) {
 	opts := datasource.NewWriteOptions(options...)
-	if opts.SyncEnable {
+	var kvs []*model.KVDoc
+	var n int64
+	var err error
+	if opts.InTxn() {
 		// if sync enable is true, will delete kvs, create tasks and tombstones
-		return txnFindManyAndDelete(ctx, kvIDs, project, domain)
+		kvs, n, err = txnFindManyAndDelete(ctx, kvIDs, project, domain, opts)
+	} else {
+		kvs, n, err = findManyAndDelete(ctx, kvIDs, project, domain)
+	}
+	if err != nil {
+		return nil, 0, err
 	}
-	return findManyAndDelete(ctx, kvIDs, project, domain)
+	label.Count(ctx, datasource.CountLabels(domain, project, kvs, -1))
+	return kvs, n, nil
 }
 
 func findManyAndDelete(ctx context.Context, kvIDs []string, project, domain string) ([]*model.KVDoc, int64, error) {
@@ -378,16 +429,11 @@ func findManyAndDelete(ctx context.Context, kvIDs []string, project, domain stri
 }
 
 // txnFindManyAndDelete is to start transaction when delete KVs, will create tasks and tombstones in a transaction operation
-func txnFindManyAndDelete(ctx context.Context, kvIDs []string, project, domain string) ([]*model.KVDoc, int64, error) {
+func txnFindManyAndDelete(ctx context.Context, kvIDs []string, project, domain string, opts datasource.WriteOptions) ([]*model.KVDoc, int64, error) {
 	var docs []*model.KVDoc
 	var opOptions []etcdadpt.OpOptions
-	kvTotalNum := len(kvIDs)
-	docs = make([]*model.KVDoc, kvTotalNum)
-	tasks := make([]*sync.Task, kvTotalNum)
-	tombstones := make([]*sync.Tombstone, kvTotalNum)
-	successKVNum := 0
-	for i := 0; i < kvTotalNum; i++ {
-		kvDoc, err := getKVDoc(ctx, domain, project, kvIDs[i])
+	for _, id := range kvIDs {
+		kvDoc, err := getKVDoc(ctx, domain, project, id)
 		// if not find the kv, continue
 		if err != nil {
 			if err == datasource.ErrKeyNotExists {
@@ -399,45 +445,29 @@ func txnFindManyAndDelete(ctx context.Context, kvIDs []string, project, domain s
 		if kvDoc == nil {
 			continue
 		}
-		task, err := sync.NewTask(domain, project, sync.DeleteAction, datasource.ConfigResource, kvDoc)
-		if err != nil {
-			openlog.Error("fail to create task")
-			return nil, 0, err
-		}
-		docs[successKVNum] = kvDoc
-		tasks[successKVNum] = task
-		tombstones[successKVNum] = sync.NewTombstone(domain, project, datasource.ConfigResource,
-			datasource.TombstoneID(kvDoc))
-		successKVNum++
+		docs = append(docs, kvDoc)
 	}
-	if successKVNum == 0 {
+	if len(docs) == 0 {
 		return nil, 0, datasource.ErrKeyNotExists
 	}
-	if successKVNum != kvTotalNum {
-		docs = docs[:successKVNum]
-		tasks = tasks[:successKVNum]
-		tombstones = tombstones[:successKVNum]
-	}
 	for _, id := range kvIDs {
 		opOptions = append(opOptions, etcdadpt.OpDel(etcdadpt.WithStrKey(key.KV(domain, project, id))))
 	}
-	for _, task := range tasks {
-		taskBytes, err := json.Marshal(task)
-		if err != nil {
-			openlog.Error("fail to marshal task" + err.Error())
-			return nil, 0, err
+	for _, kvDoc := range docs {
+		if opts.SyncEnable {
+			syncOps, err := syncDeleteOps(domain, project, kvDoc)
+			if err != nil {
+				return nil, 0, err
+			}
+			opOptions = append(opOptions, syncOps...)
 		}
-		opOptions = append(opOptions, etcdadpt.OpPut(etcdadpt.WithStrKey(key.TaskKey(domain, project,
-			task.ID, task.Timestamp)), etcdadpt.WithValue(taskBytes)))
-	}
-	for _, tombstone := range tombstones {
-		tombstoneBytes, err := json.Marshal(tombstone)
-		if err != nil {
-			openlog.Error("fail to marshal tombstone" + err.Error())
-			return nil, 0, err
+		if opts.OutboxEnable {
+			eventOpPut, err := outbox.OpPutEvent(domain, project, kvDoc, datasource.OutboxActionDelete, opts.Node)
+			if err != nil {
+				return nil, 0, err
+			}
+			opOptions = append(opOptions, eventOpPut)
 		}
-		opOptions = append(opOptions, etcdadpt.OpPut(etcdadpt.WithStrKey(key.TombstoneKey(domain, project,
-			tombstone.ResourceType, tombstone.ResourceID)), etcdadpt.WithValue(tombstoneBytes)))
 	}
 	err := etcdadpt.Txn(ctx, opOptions)
 	if err != nil {
@@ -446,7 +476,281 @@ func txnFindManyAndDelete(ctx context.Context, kvIDs []string, project, domain s
 		}))
 		return nil, 0, err
 	}
-	return docs, int64(successKVNum), nil
+	return docs, int64(len(docs)), nil
+}
+
+// Move replaces kv old by kv of another label set in a transaction,
+// the revisions of old are carried over to kv in the same transaction
+func (s *Dao) Move(ctx context.Context, old, kv *model.KVDoc, override bool, options ...datasource.WriteOption) error {
+	if err := auth.CheckDeleteKV(ctx, old); err != nil {
+		return err
+	}
+	if err := auth.CheckCreateKV(ctx, kv); err != nil {
+		return err
+	}
+	opts := datasource.NewWriteOptions(options...)
+	oldKey := key.KV(old.Domain, old.Project, old.ID)
+	newKey := key.KV(kv.Domain, kv.Project, kv.ID)
+	source, err := etcdadpt.Get(ctx, oldKey)
+	if err != nil {
+		openlog.Error(err.Error())
+		return err
+	}
+	if source == nil {
+		return datasource.ErrKeyNotExists
+	}
+	cur := &model.KVDoc{}
+	if err := json.Unmarshal(source.Value, cur); err != nil {
+		openlog.Error(err.Error())
+		return err
+	}
+	if cur.UpdateRevision != old.UpdateRevision {
+		return datasource.ErrKVConflict
+	}
+	target, err := etcdadpt.Get(ctx, newKey)
+	if err != nil {
+		openlog.Error(err.Error())
+		return err
+	}
+	if target != nil && !override {
+		return datasource.ErrKVAlreadyExists
+	}
+	kvBytes, err := json.Marshal(kv)
+	if err != nil {
+		openlog.Error("fail to marshal kv " + err.Error())
+		return err
+	}
+	ops := []etcdadpt.OpOptions{
+		etcdadpt.OpDel(etcdadpt.WithStrKey(oldKey)),
+		etcdadpt.OpPut(etcdadpt.WithStrKey(newKey), etcdadpt.WithValue(kvBytes)),
+	}
+	historyOps, err := carryHistoryOps(ctx, old, kv)
+	if err != nil {
+		return err
+	}
+	ops = append(ops, historyOps...)
+	// old is moved only if it is not changed since it was read
+	cmps := []etcdadpt.CmpOptions{etcdadpt.EqualModRev(oldKey, source.ModRevision)}
+	if target == nil {
+		cmps = append(cmps, etcdadpt.NotExistKey(newKey))
+	} else {
+		cmps = append(cmps, etcdadpt.EqualModRev(newKey, target.ModRevision))
+	}
+	if opts.SyncEnable {
+		syncOps, err := syncDeleteOps(old.Domain, old.Project, old)
+		if err != nil {
+			return err
+		}
+		ops = append(ops, syncOps...)
+		task, err := sync.NewTask(kv.Domain, kv.Project, sync.CreateAction, datasource.ConfigResource, kv)
+		if err != nil {
+			openlog.Error("fail to create task" + err.Error())
+			return err
+		}
+		taskBytes, err := json.Marshal(task)
+		if err != nil {
+			openlog.Error("fail to marshal task ")
+			return err
+		}
+		ops = append(ops, etcdadpt.OpPut(etcdadpt.WithStrKey(key.TaskKey(kv.Domain, kv.Project, task.ID, task.Timestamp)),
+			etcdadpt.WithValue(taskBytes)))
+	}
+	if opts.OutboxEnable {
+		deleteOp, err := outbox.OpPutEvent(old.Domain, old.Project, old, datasource.OutboxActionDelete, opts.Node)
+		if err != nil {
+			return err
+		}
+		putOp, err := outbox.OpPutEvent(kv.Domain, kv.Project, kv, datasource.OutboxActionPut, opts.Node)
+		if err != nil {
+			return err
+		}
+		ops = append(ops, deleteOp, putOp)
+	}
+	resp, err := etcdadpt.TxnWithCmp(ctx, ops, etcdadpt.If(cmps...), nil)
+	if err != nil {
+		openlog.Error("move kv error: " + err.Error())
+		return err
+	}
+	if !resp.Succeeded {
+		// the kv is deleted or changed, or the target is written concurrently
+		cur, err := etcdadpt.Get(ctx, oldKey)
+		if err == nil && cur == nil {
+			return datasource.ErrKeyNotExists
+		}
+		if err == nil && cur.ModRevision != source.ModRevision {
+			return datasource.ErrKVConflict
+		}
+		return datasource.ErrKVAlreadyExists
+	}
+	label.Count(ctx, datasource.CountLabels(old.Domain, old.Project, []*model.KVDoc{old}, -1))
+	if target == nil {
+		label.Count(ctx, datasource.CountLabels(kv.Domain, kv.Project, []*model.KVDoc{kv}, 1))
+	}
+	return nil
+}
+
+// carryHistoryOps return the ops to copy the revisions of old to kv
+func carryHistoryOps(ctx context.Context, old, kv *model.KVDoc) ([]etcdadpt.OpOptions, error) {
+	revisions, _, err := etcdadpt.List(ctx, key.HisList(old.Domain, old.Project, old.ID))
+	if err != nil {
+		openlog.Error("list history error: " + err.Error())
+		return nil, err
+	}
+	ops := make([]etcdadpt.OpOptions, 0, len(revisions))
+	for _, r := range revisions {
+		h := &model.KVDoc{}
+		if err := json.Unmarshal(r.Value, h); err != nil {
+			openlog.Error("decode history error: " + err.Error())
+			return nil, err
+		}
+		h.ID, h.Domain, h.Project = kv.ID, kv.Domain, kv.Project
+		b, err := json.Marshal(h)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, etcdadpt.OpPut(etcdadpt.WithStrKey(key.His(kv.Domain, kv.Project, kv.ID, h.UpdateRevision)),
+			etcdadpt.WithValue(b)))
+	}
+	return ops, nil
+}
+
+// Batch writes kvs in one transaction
+func (s *Dao) Batch(ctx context.Context, txn *datasource.KVTxn, options ...datasource.WriteOption) error {
+	for _, kv := range txn.Creates {
+		if err := auth.CheckCreateKV(ctx, kv); err != nil {
+			return err
+		}
+	}
+	for _, kv := range txn.Updates {
+		if err := auth.CheckUpdateKV(ctx, kv); err != nil {
+			return err
+		}
+	}
+	for _, kv := range txn.Deletes {
+		if err := auth.CheckDeleteKV(ctx, kv); err != nil {
+			return err
+		}
+	}
+	opts := datasource.NewWriteOptions(options...)
+	var ops []etcdadpt.OpOptions
+	var cmps []etcdadpt.CmpOptions
+	for _, kv := range txn.Creates {
+		putOps, err := batchPutOps(kv, sync.CreateAction, opts)
+		if err != nil {
+			return err
+		}
+		ops = append(ops, putOps...)
+		cmps = append(cmps, etcdadpt.NotExistKey(key.KV(kv.Domain, kv.Project, kv.ID)))
+	}
+	for _, kv := range txn.Updates {
+		putOps, err := batchPutOps(kv, sync.UpdateAction, opts)
+		if err != nil {
+			return err
+		}
+		cmp, err := unchanged(ctx, kv, txn.Revisions)
+		if err != nil {
+			return err
+		}
+		ops = append(ops, putOps...)
+		cmps = append(cmps, cmp)
+	}
+	for _, kv := range txn.Deletes {
+		cmp, err := unchanged(ctx, kv, txn.Revisions)
+		if err != nil {
+			return err
+		}
+		ops = append(ops, etcdadpt.OpDel(etcdadpt.WithStrKey(key.KV(kv.Domain, kv.Project, kv.ID))))
+		cmps = append(cmps, cmp)
+		if opts.SyncEnable {
+			syncOps, err := syncDeleteOps(kv.Domain, kv.Project, kv)
+			if err != nil {
+				return err
+			}
+			ops = append(ops, syncOps...)
+		}
+		if opts.OutboxEnable {
+			eventOp, err := outbox.OpPutEvent(kv.Domain, kv.Project, kv, datasource.OutboxActionDelete, opts.Node)
+			if err != nil {
+				return err
+			}
+			ops = append(ops, eventOp)
+		}
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+	resp, err := etcdadpt.TxnWithCmp(ctx, ops, etcdadpt.If(cmps...), nil)
+	if err != nil {
+		openlog.Error("batch write kvs error: " + err.Error())
+		return err
+	}
+	if !resp.Succeeded {
+		return datasource.ErrKVConflict
+	}
+	if len(txn.Creates) != 0 {
+		label.Count(ctx, datasource.CountLabels(txn.Creates[0].Domain, txn.Creates[0].Project, txn.Creates, 1))
+	}
+	if len(txn.Deletes) != 0 {
+		label.Count(ctx, datasource.CountLabels(txn.Deletes[0].Domain, txn.Deletes[0].Project, txn.Deletes, -1))
+	}
+	return nil
+}
+
+// unchanged compares the kv with the update revision in revisions, it only checks the existence if there is no revision
+func unchanged(ctx context.Context, kv *model.KVDoc, revisions map[string]int64) (etcdadpt.CmpOptions, error) {
+	k := key.KV(kv.Domain, kv.Project, kv.ID)
+	rev, ok := revisions[kv.ID]
+	if !ok {
+		return etcdadpt.ExistKey(k), nil
+	}
+	resp, err := etcdadpt.Get(ctx, k)
+	if err != nil {
+		openlog.Error(err.Error())
+		return etcdadpt.CmpOptions{}, err
+	}
+	if resp == nil {
+		return etcdadpt.CmpOptions{}, datasource.ErrKVConflict
+	}
+	cur := &model.KVDoc{}
+	if err := json.Unmarshal(resp.Value, cur); err != nil {
+		openlog.Error(err.Error())
+		return etcdadpt.CmpOptions{}, err
+	}
+	if cur.UpdateRevision != rev {
+		return etcdadpt.CmpOptions{}, datasource.ErrKVConflict
+	}
+	return etcdadpt.EqualModRev(k, resp.ModRevision), nil
+}
+
+func batchPutOps(kv *model.KVDoc, action string, opts datasource.WriteOptions) ([]etcdadpt.OpOptions, error) {
+	kvBytes, err := json.Marshal(kv)
+	if err != nil {
+		openlog.Error("fail to marshal kv " + err.Error())
+		return nil, err
+	}
+	ops := []etcdadpt.OpOptions{etcdadpt.OpPut(etcdadpt.WithStrKey(key.KV(kv.Domain, kv.Project, kv.ID)), etcdadpt.WithValue(kvBytes))}
+	if opts.SyncEnable {
+		task, err := sync.NewTask(kv.Domain, kv.Project, action, datasource.ConfigResource, kv)
+		if err != nil {
+			openlog.Error("fail to create task" + err.Error())
+			return nil, err
+		}
+		taskBytes, err := json.Marshal(task)
+		if err != nil {
+			openlog.Error("fail to marshal task ")
+			return nil, err
+		}
+		ops = append(ops, etcdadpt.OpPut(etcdadpt.WithStrKey(key.TaskKey(kv.Domain, kv.Project, task.ID, task.Timestamp)),
+			etcdadpt.WithValue(taskBytes)))
+	}
+	if opts.OutboxEnable {
+		eventOp, err := outbox.OpPutEvent(kv.Domain, kv.Project, kv, datasource.OutboxActionPut, opts.Node)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, eventOp)
+	}
+	return ops, nil
 }
 
 // Get get kv by kv id
@@ -484,33 +788,26 @@ func (s *Dao) Total(ctx context.Context, project, domain string) (int64, error)
 
 // List get kv list by key and criteria
 func (s *Dao) List(ctx context.Context, project, domain string, options ...datasource.FindOption) (*model.KVResponse, error) {
-	result, opts, err := s.listData(ctx, project, domain, options...)
+	result, opts, err := s.listData(ctx, project, domain, auth.KVFilter(ctx), options...)
 	if err != nil {
 		return nil, err
 	}
 
-	filterKVs, err := auth.FilterKVList(ctx, result.Data)
-	if err != nil {
-		return nil, err
-	}
-
-	result.Data = filterKVs
-	result.Total = len(filterKVs)
-
-	return pagingResult(result, opts), nil
+	return datasource.PageKVs(result, opts), nil
 }
 
 func (s *Dao) listNoAuth(ctx context.Context, project, domain string, options ...datasource.FindOption) (*model.KVResponse, error) {
-	result, opts, err := s.listData(ctx, project, domain, options...)
+	result, opts, err := s.listData(ctx, project, domain, nil, options...)
 	if err != nil {
 		return nil, err
 	}
 
-	return pagingResult(result, opts), nil
+	return datasource.PageKVs(result, opts), nil
 }
 
-// List get kv list by key and criteria
-func (s *Dao) listData(ctx context.Context, project, domain string, options ...datasource.FindOption) (*model.KVResponse, datasource.FindOptions, error) {
+// List get kv list by key and criteria, kvs which are not allowed are filtered out
+func (s *Dao) listData(ctx context.Context, project, domain string, allow func(*model.KVDoc) bool,
+	options ...datasource.FindOption) (*model.KVResponse, datasource.FindOptions, error) {
 	opts := datasource.NewDefaultFindOpts()
 	for _, o := range options {
 		o(&opts)
@@ -518,7 +815,11 @@ func (s *Dao) listData(ctx context.Context, project, domain string, options ...d
 	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
 	defer cancel()
 
-	regex, err := toRegex(opts)
+	regex, err := datasource.KeyRegex(opts)
+	if err != nil {
+		return nil, opts, err
+	}
+	search, err := datasource.NewSearchRegex(opts)
 	if err != nil {
 		return nil, opts, err
 	}
@@ -529,6 +830,8 @@ func (s *Dao) listData(ctx context.Context, project, domain string, options ...d
 			Project: project,
 			Opts:    &opts,
 			Regex:   regex,
+			Search:  search,
+			Allow:   allow,
 		})
 		if useCache && err == nil {
 			return result, opts, nil
@@ -538,7 +841,7 @@ func (s *Dao) listData(ctx context.Context, project, domain string, options ...d
 		}
 	}
 
-	result, err := matchLabelsSearch(ctx, domain, project, regex, opts)
+	result, err := matchLabelsSearch(ctx, domain, project, regex, search, opts, allow)
 	if err != nil {
 		openlog.Error("list kv failed: " + err.Error())
 		return nil, opts, err
@@ -547,15 +850,16 @@ func (s *Dao) listData(ctx context.Context, project, domain string, options ...d
 	return result, opts, nil
 }
 
-func matchLabelsSearch(ctx context.Context, domain, project string, regex *regexp.Regexp, opts datasource.FindOptions) (*model.KVResponse, error) {
+// matchLabelsSearch scans all kvs of the project, kvs are stored by id,
+// so that a sorted page is picked in memory instead of seeking to the cursor
+func matchLabelsSearch(ctx context.Context, domain, project string, regex *regexp.Regexp, search *datasource.SearchRegex,
+	opts datasource.FindOptions, allow func(*model.KVDoc) bool) (*model.KVResponse, error) {
 	openlog.Debug("using labels to search kv")
 	kvs, _, err := etcdadpt.List(ctx, key.KVList(domain, project))
 	if err != nil {
 		return nil, err
 	}
-	result := &model.KVResponse{
-		Data: []*model.KVDoc{},
-	}
+	c := datasource.NewKVCollector(opts, allow)
 	for _, kv := range kvs {
 		var doc model.KVDoc
 		err := json.Unmarshal(kv.Value, &doc)
@@ -563,91 +867,15 @@ func matchLabelsSearch(ctx context.Context, domain, project string, regex *regex
 			openlog.Error("decode to KVList error: " + err.Error())
 			continue
 		}
-		if !filterMatch(&doc, opts, regex) {
+		if !datasource.MatchKV(&doc, opts, regex) || !search.Match(&doc, opts) {
 			continue
 		}
 
 		datasource.ClearPart(&doc)
-		result.Data = append(result.Data, &doc)
-		result.Total++
-
-		if IsUniqueFind(opts) {
+		if c.Add(&doc) && datasource.IsUniqueFind(opts) {
 			break
 		}
 	}
 
-	return result, nil
-}
-
-func IsUniqueFind(opts datasource.FindOptions) bool {
-	return opts.LabelFormat != "" && opts.Key != ""
-}
-
-func toRegex(opts datasource.FindOptions) (*regexp.Regexp, error) {
-	var value string
-	if opts.Key == "" {
-		return nil, nil
-	}
-	switch {
-	case strings.HasPrefix(opts.Key, "beginWith("):
-		value = strings.ReplaceAll(getValue(opts.Key), ".", "\\.") + ".*"
-	case strings.HasPrefix(opts.Key, "wildcard("):
-		value = strings.ReplaceAll(getValue(opts.Key), ".", "\\.")
-		value = strings.ReplaceAll(value, "*", ".*")
-	default:
-		value = strings.ReplaceAll(opts.Key, ".", "\\.")
-	}
-	value = "^" + value + "$"
-	if !opts.CaseSensitive {
-		value = "(?i)" + value
-	}
-	regex, err := regexp.Compile(value)
-	if err != nil {
-		openlog.Error("invalid wildcard expr: " + value + ", error: " + err.Error())
-		return nil, err
-	}
-	return regex, nil
-}
-
-func pagingResult(result *model.KVResponse, opts datasource.FindOptions) *model.KVResponse {
-	datasource.ReverseByPriorityAndUpdateRev(result.Data)
-
-	if opts.Limit == 0 {
-		return result
-	}
-	total := int64(result.Total)
-	if opts.Offset >= total {
-		result.Data = []*model.KVDoc{}
-		return result
-	}
-	end := opts.Offset + opts.Limit
-	if end > total {
-		end = total
-	}
-	result.Data = result.Data[opts.Offset:end]
-	return result
-}
-
-func filterMatch(doc *model.KVDoc, opts datasource.FindOptions, regex *regexp.Regexp) bool {
-	if opts.Status != "" && doc.Status != opts.Status {
-		return false
-	}
-	if regex != nil && !regex.MatchString(doc.Key) {
-		return false
-	}
-	if len(opts.Labels) != 0 {
-		if opts.ExactLabels && !util.IsEquivalentLabel(opts.Labels, doc.Labels) {
-			return false
-		}
-		if !opts.ExactLabels && !util.IsContainLabel(doc.Labels, opts.Labels) {
-			return false
-		}
-	}
-	if opts.LabelFormat != "" && doc.LabelFormat != opts.LabelFormat {
-		return false
-	}
-	if opts.Value != "" && !strings.Contains(doc.Value, opts.Value) {
-		return false
-	}
-	return true
+	return c.Done(), nil
 }
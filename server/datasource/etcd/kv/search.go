@@ -0,0 +1,66 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kv
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+)
+
+// loadProjectKvIDs return ids of all cached kvs of the project
+func (kc *Cache) loadProjectKvIDs(domain, project string) []string {
+	prefix := strings.Join([]string{"", domain, project, ""}, "/")
+	var ids []string
+	kc.kvIDCache.Range(func(cacheKey, value any) bool {
+		if !strings.HasPrefix(cacheKey.(string), prefix) {
+			return true
+		}
+		value.(*sync.Map).Range(func(kvID, _ any) bool {
+			ids = append(ids, kvID.(string))
+			return true
+		})
+		return true
+	})
+	return ids
+}
+
+// loadKvDocs return copies of cached kvs, kvs which are not cached are got from etcd
+func (kc *Cache) loadKvDocs(ctx context.Context, req *CacheSearchReq, kvIDs []string) ([]*model.KVDoc, error) {
+	var docs []*model.KVDoc
+	var kvIdsLeft []string
+	for _, kvID := range kvIDs {
+		if doc, ok := kc.LoadKvDoc(kvID); ok {
+			docs = append(docs, doc)
+		} else {
+			kvIdsLeft = append(kvIdsLeft, kvID)
+		}
+	}
+	tpData, err := kc.getKvFromEtcd(ctx, req, kvIdsLeft)
+	if err != nil {
+		return nil, err
+	}
+	docs = append(docs, tpData...)
+	for i, doc := range docs {
+		c := *doc
+		docs[i] = &c
+	}
+	return docs, nil
+}
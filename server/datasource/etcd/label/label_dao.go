@@ -0,0 +1,219 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package label
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/go-chassis/openlog"
+	"github.com/little-cui/etcdadpt"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/datasource"
+	"github.com/apache/servicecomb-kie/server/datasource/etcd/key"
+)
+
+// maxRetries of compare and swap when label sets are modified concurrently
+const maxRetries = 10
+
+var errConflict = errors.New("label set is modified concurrently")
+
+// Dao operate the label catalog in etcd
+type Dao struct {
+}
+
+// Count adds the counts of label sets, label sets are created on first use,
+// it is called after kvs are written, so errors are only logged,
+// a count drifted by such errors is recounted from kvs once it goes negative or the label set is deleted
+func Count(ctx context.Context, docs []*model.LabelDoc) {
+	for _, doc := range docs {
+		negative := false
+		err := swap(ctx, doc.Domain, doc.Project, doc.ID, func(cur *model.LabelDoc) (bool, error) {
+			if cur.ID == "" {
+				*cur = *doc
+				cur.Count = 0
+			}
+			cur.Count += doc.Count
+			negative = cur.Count < 0
+			return !negative, nil
+		})
+		if err == nil && negative {
+			openlog.Warn("label set count is negative, recount it", openlog.WithTags(openlog.Tags{
+				"labels": doc.Format,
+			}))
+			_, err = recount(ctx, doc)
+		}
+		if err != nil {
+			openlog.Error("count label set failed", openlog.WithTags(openlog.Tags{
+				"err":    err.Error(),
+				"labels": doc.Format,
+			}))
+		}
+	}
+}
+
+// recount sets the count of the label set to the number of kvs having it, the label set is created if missing
+func recount(ctx context.Context, doc *model.LabelDoc) (int64, error) {
+	n, err := countKVs(ctx, doc.Domain, doc.Project, doc.Format)
+	if err != nil {
+		return 0, err
+	}
+	err = swap(ctx, doc.Domain, doc.Project, doc.ID, func(cur *model.LabelDoc) (bool, error) {
+		if cur.ID == "" {
+			*cur = *doc
+		}
+		cur.Count = n
+		return true, nil
+	})
+	return n, err
+}
+
+// countKVs counts the kvs of the project having the label format
+func countKVs(ctx context.Context, domain, project, format string) (int64, error) {
+	kvs, _, err := etcdadpt.List(ctx, key.KVList(domain, project))
+	if err != nil {
+		return 0, err
+	}
+	var n int64
+	for _, kv := range kvs {
+		doc := &model.KVDoc{}
+		if err := json.Unmarshal(kv.Value, doc); err != nil {
+			return 0, err
+		}
+		if datasource.KVLabelFormat(doc) == format {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// swap modifies the label set by f and writes it back if it is not modified concurrently,
+// cur is empty if the label set does not exist, and nothing is written if f returns false
+func swap(ctx context.Context, domain, project, id string, f func(cur *model.LabelDoc) (bool, error)) error {
+	k := key.Label(domain, project, id)
+	for i := 0; i < maxRetries; i++ {
+		kv, err := etcdadpt.Get(ctx, k)
+		if err != nil {
+			return err
+		}
+		cur := &model.LabelDoc{}
+		cmp := etcdadpt.NotExistKey(k)
+		if kv != nil {
+			if err := json.Unmarshal(kv.Value, cur); err != nil {
+				return err
+			}
+			cmp = etcdadpt.EqualModRev(k, kv.ModRevision)
+		}
+		write, err := f(cur)
+		if err != nil || !write {
+			return err
+		}
+		b, err := json.Marshal(cur)
+		if err != nil {
+			return err
+		}
+		resp, err := etcdadpt.TxnWithCmp(ctx, []etcdadpt.OpOptions{
+			etcdadpt.OpPut(etcdadpt.WithStrKey(k), etcdadpt.WithValue(b)),
+		}, etcdadpt.If(cmp), nil)
+		if err != nil {
+			return err
+		}
+		if resp.Succeeded {
+			return nil
+		}
+	}
+	return errConflict
+}
+
+func (d *Dao) List(ctx context.Context, project, domain string) ([]*model.LabelDoc, error) {
+	kvs, n, err := etcdadpt.List(ctx, key.LabelList(domain, project))
+	if err != nil {
+		openlog.Error(err.Error())
+		return nil, err
+	}
+	docs := make([]*model.LabelDoc, 0, n)
+	for _, kv := range kvs {
+		doc := &model.LabelDoc{}
+		if err := json.Unmarshal(kv.Value, doc); err != nil {
+			openlog.Error("decode label set error: " + err.Error())
+			continue
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+func (d *Dao) UpdateAlias(ctx context.Context, id, project, domain, alias string) (*model.LabelDoc, error) {
+	var doc *model.LabelDoc
+	err := swap(ctx, domain, project, id, func(cur *model.LabelDoc) (bool, error) {
+		if cur.ID == "" {
+			return false, datasource.ErrLabelNotExists
+		}
+		cur.Alias = alias
+		doc = cur
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// DeleteOrphan recounts the kvs of the label set instead of trusting its count,
+// a kv created concurrently recreates the label set once it is counted
+func (d *Dao) DeleteOrphan(ctx context.Context, id, project, domain string) error {
+	k := key.Label(domain, project, id)
+	kv, err := etcdadpt.Get(ctx, k)
+	if err != nil {
+		openlog.Error(err.Error())
+		return err
+	}
+	if kv == nil {
+		return datasource.ErrLabelNotExists
+	}
+	doc := &model.LabelDoc{}
+	if err := json.Unmarshal(kv.Value, doc); err != nil {
+		return err
+	}
+	n, err := countKVs(ctx, domain, project, doc.Format)
+	if err != nil {
+		openlog.Error("count kvs of label set error: " + err.Error())
+		return err
+	}
+	if n != 0 {
+		if n != doc.Count {
+			if _, err := recount(ctx, doc); err != nil {
+				openlog.Error("recount label set error: " + err.Error())
+			}
+		}
+		return datasource.ErrLabelInUse
+	}
+	resp, err := etcdadpt.TxnWithCmp(ctx, []etcdadpt.OpOptions{etcdadpt.OpDel(etcdadpt.WithStrKey(k))},
+		etcdadpt.If(etcdadpt.EqualModRev(k, kv.ModRevision)), nil)
+	if err != nil {
+		openlog.Error("delete label set error: " + err.Error())
+		return err
+	}
+	if !resp.Succeeded {
+		// a kv of the label set is created concurrently
+		return datasource.ErrLabelInUse
+	}
+	return nil
+}
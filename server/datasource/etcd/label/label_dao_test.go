@@ -0,0 +1,76 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package label_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/datasource"
+	"github.com/apache/servicecomb-kie/server/datasource/etcd"
+	"github.com/apache/servicecomb-kie/server/datasource/etcd/label"
+	kvsvc "github.com/apache/servicecomb-kie/server/service/kv"
+	"github.com/stretchr/testify/assert"
+
+	_ "github.com/apache/servicecomb-kie/test"
+)
+
+func TestCount(t *testing.T) {
+	if _, ok := datasource.GetBroker().(*etcd.Broker); !ok {
+		t.Skip("only for etcd")
+	}
+	ctx := context.TODO()
+	domain, project := "default", "label-drift"
+	labels := map[string]string{"app": "drift"}
+	var kvs []*model.KVDoc
+	for _, k := range []string{"a", "b"} {
+		kv, err := kvsvc.Create(ctx, &model.KVDoc{Key: k, Value: "1", Labels: labels, Domain: domain, Project: project})
+		assert.Nil(t, err)
+		kvs = append(kvs, kv)
+	}
+	count := func() *model.LabelDoc {
+		docs, err := datasource.GetBroker().GetLabelDao().List(ctx, project, domain)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(docs))
+		return docs[0]
+	}
+
+	t.Run("a count going negative should be recounted", func(t *testing.T) {
+		label.Count(ctx, datasource.CountLabels(domain, project, kvs, -3))
+		assert.Equal(t, int64(2), count().Count)
+	})
+	t.Run("delete a label set counted 0 but still used, should be refused and recounted", func(t *testing.T) {
+		label.Count(ctx, datasource.CountLabels(domain, project, kvs, -1))
+		doc := count()
+		assert.Equal(t, int64(0), doc.Count)
+		err := datasource.GetBroker().GetLabelDao().DeleteOrphan(ctx, doc.ID, project, domain)
+		assert.Equal(t, datasource.ErrLabelInUse, err)
+		assert.Equal(t, int64(2), count().Count)
+	})
+	t.Run("delete a label set counted but not used, should pass", func(t *testing.T) {
+		label.Count(ctx, datasource.CountLabels(domain, project, kvs, 1))
+		for _, kv := range kvs {
+			_, err := kvsvc.FindOneAndDelete(ctx, kv.ID, project, domain)
+			assert.NoError(t, err)
+		}
+		doc := count()
+		assert.Equal(t, int64(2), doc.Count)
+		assert.NoError(t, datasource.GetBroker().GetLabelDao().DeleteOrphan(ctx, doc.ID, project, domain))
+	})
+}
@@ -0,0 +1,84 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-chassis/openlog"
+	"github.com/little-cui/etcdadpt"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/datasource"
+	"github.com/apache/servicecomb-kie/server/datasource/etcd/key"
+)
+
+// Dao operate outbox events in etcd
+type Dao struct {
+}
+
+// OpPutEvent return the op to write an outbox event of the kv mutation,
+// it should be executed in the same txn as the mutation
+func OpPutEvent(domain, project string, kv *model.KVDoc, action, node string) (etcdadpt.OpOptions, error) {
+	event, err := datasource.NewOutboxEvent(domain, project, kv, action, node)
+	if err != nil {
+		openlog.Error("fail to create outbox event: " + err.Error())
+		return etcdadpt.OpOptions{}, err
+	}
+	eventBytes, err := json.Marshal(event)
+	if err != nil {
+		openlog.Error("fail to marshal outbox event: " + err.Error())
+		return etcdadpt.OpOptions{}, err
+	}
+	return etcdadpt.OpPut(etcdadpt.WithStrKey(key.Outbox(event.ID, event.Timestamp)), etcdadpt.WithValue(eventBytes)), nil
+}
+
+func (d *Dao) List(ctx context.Context, after *model.OutboxEvent, limit int64) ([]*model.OutboxEvent, error) {
+	start := key.OutboxList()
+	if after != nil {
+		// the first key larger than the one of after
+		start = key.Outbox(after.ID, after.Timestamp) + "\x00"
+	}
+	resp, err := etcdadpt.Instance().Do(ctx, etcdadpt.GET,
+		etcdadpt.WithStrKey(start), etcdadpt.WithStrEndKey(key.OutboxListEnd()), etcdadpt.WithLimit(limit))
+	if err != nil {
+		openlog.Error("list outbox events failed: " + err.Error())
+		return nil, err
+	}
+	events := make([]*model.OutboxEvent, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		event := &model.OutboxEvent{}
+		if err := json.Unmarshal(kv.Value, event); err != nil {
+			openlog.Error("decode outbox event failed: " + err.Error())
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func (d *Dao) Done(ctx context.Context, events []*model.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	ops := make([]etcdadpt.OpOptions, 0, len(events))
+	for _, event := range events {
+		ops = append(ops, etcdadpt.OpDel(etcdadpt.WithStrKey(key.Outbox(event.ID, event.Timestamp))))
+	}
+	return etcdadpt.Txn(ctx, ops)
+}
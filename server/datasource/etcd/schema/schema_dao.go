@@ -0,0 +1,106 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package schema
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-chassis/openlog"
+	"github.com/little-cui/etcdadpt"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/datasource"
+	"github.com/apache/servicecomb-kie/server/datasource/etcd/key"
+)
+
+// Dao operate json schemas of keys in etcd
+type Dao struct {
+}
+
+func (d *Dao) Create(ctx context.Context, schema *model.SchemaDoc) (*model.SchemaDoc, error) {
+	bytes, err := json.Marshal(schema)
+	if err != nil {
+		openlog.Error("fail to marshal schema " + err.Error())
+		return nil, err
+	}
+	ok, err := etcdadpt.InsertBytes(ctx, key.Schema(schema.Domain, schema.Project, schema.ID), bytes)
+	if err != nil {
+		openlog.Error("create schema error: " + err.Error())
+		return nil, err
+	}
+	if !ok {
+		return nil, datasource.ErrSchemaAlreadyExists
+	}
+	return schema, nil
+}
+
+func (d *Dao) Update(ctx context.Context, schema *model.SchemaDoc) error {
+	bytes, err := json.Marshal(schema)
+	if err != nil {
+		openlog.Error("fail to marshal schema " + err.Error())
+		return err
+	}
+	return etcdadpt.PutBytes(ctx, key.Schema(schema.Domain, schema.Project, schema.ID), bytes)
+}
+
+func (d *Dao) Get(ctx context.Context, id, project, domain string) (*model.SchemaDoc, error) {
+	kv, err := etcdadpt.Get(ctx, key.Schema(domain, project, id))
+	if err != nil {
+		openlog.Error(err.Error())
+		return nil, err
+	}
+	if kv == nil {
+		return nil, datasource.ErrSchemaNotExists
+	}
+	schema := &model.SchemaDoc{}
+	if err := json.Unmarshal(kv.Value, schema); err != nil {
+		openlog.Error("decode schema error: " + err.Error())
+		return nil, err
+	}
+	return schema, nil
+}
+
+func (d *Dao) List(ctx context.Context, project, domain string) ([]*model.SchemaDoc, error) {
+	kvs, n, err := etcdadpt.List(ctx, key.SchemaList(domain, project))
+	if err != nil {
+		openlog.Error(err.Error())
+		return nil, err
+	}
+	schemas := make([]*model.SchemaDoc, 0, n)
+	for _, kv := range kvs {
+		schema := &model.SchemaDoc{}
+		if err := json.Unmarshal(kv.Value, schema); err != nil {
+			openlog.Error("decode schema error: " + err.Error())
+			continue
+		}
+		schemas = append(schemas, schema)
+	}
+	return schemas, nil
+}
+
+func (d *Dao) Delete(ctx context.Context, id, project, domain string) error {
+	ok, err := etcdadpt.Delete(ctx, key.Schema(domain, project, id))
+	if err != nil {
+		openlog.Error("delete schema error: " + err.Error())
+		return err
+	}
+	if !ok {
+		return datasource.ErrSchemaNotExists
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package datasource
+
+import (
+	"container/heap"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+)
+
+// fields which kvs can be sorted by
+const (
+	SortKey            = "key"
+	SortUpdateTime     = "update_time"
+	SortUpdateRevision = "update_revision"
+	SortPriority       = "priority"
+)
+
+// ErrInvalidCursor means the cursor is malformed
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is the position of the last kv of a page in the sort order,
+// the next page starts after it, so the pages are stable under concurrent writes.
+// kvs are ordered by the sort field and then id, so the order is total
+type Cursor struct {
+	Sort string `json:"s"`
+	Desc bool   `json:"d,omitempty"`
+	Key  string `json:"k,omitempty"`
+	Num  int64  `json:"n,omitempty"`
+	ID   string `json:"i"`
+}
+
+// IsSortField return true if kvs can be sorted by the field
+func IsSortField(field string) bool {
+	switch field {
+	case SortKey, SortUpdateTime, SortUpdateRevision, SortPriority:
+		return true
+	}
+	return false
+}
+
+// NewCursor return the cursor pointing to the kv
+func NewCursor(kv *model.KVDoc, field string, desc bool) *Cursor {
+	c := &Cursor{Sort: field, Desc: desc, ID: kv.ID}
+	if field == SortKey {
+		c.Key = kv.Key
+	} else {
+		c.Num = SortValue(kv, field)
+	}
+	return c
+}
+
+// Encode return the opaque token of the cursor
+func (c *Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor parse the token returned by Encode
+func DecodeCursor(token string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	c := &Cursor{}
+	if err := json.Unmarshal(b, c); err != nil || !IsSortField(c.Sort) || c.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// SortValue return the numeric sort field of the kv
+func SortValue(kv *model.KVDoc, field string) int64 {
+	switch field {
+	case SortUpdateTime:
+		return kv.UpdateTime
+	case SortUpdateRevision:
+		return kv.UpdateRevision
+	case SortPriority:
+		return int64(kv.Priority)
+	}
+	return 0
+}
+
+// CompareKV compares kvs by the sort field in ascending order, ties are broken by id
+func CompareKV(a, b *model.KVDoc, field string) int {
+	if field == SortKey {
+		if c := strings.Compare(a.Key, b.Key); c != 0 {
+			return c
+		}
+	} else if va, vb := SortValue(a, field), SortValue(b, field); va != vb {
+		if va < vb {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a.ID, b.ID)
+}
+
+func (c *Cursor) doc() *model.KVDoc {
+	return &model.KVDoc{
+		ID:             c.ID,
+		Key:            c.Key,
+		UpdateTime:     c.Num,
+		UpdateRevision: c.Num,
+		Priority:       int(c.Num),
+	}
+}
+
+// CursorPage collects the kvs of the page after the cursor in sort order,
+// it holds at most limit+1 kvs however many kvs are added, or all of them if limit is 0
+type CursorPage struct {
+	field  string
+	desc   bool
+	limit  int
+	cursor *model.KVDoc
+	kvs    []*model.KVDoc
+}
+
+// NewCursorPage return a page of the sort, cursor and limit of options
+func NewCursorPage(opts FindOptions) *CursorPage {
+	p := &CursorPage{field: opts.Sort, desc: opts.SortDesc, limit: int(opts.Limit)}
+	if opts.Cursor != nil {
+		p.cursor = opts.Cursor.doc()
+	}
+	return p
+}
+
+// before return true if kv a is before kv b in the sort order
+func (p *CursorPage) before(a, b *model.KVDoc) bool {
+	c := CompareKV(a, b, p.field)
+	if p.desc {
+		return c > 0
+	}
+	return c < 0
+}
+
+// Add adds the kv if it is after the cursor
+func (p *CursorPage) Add(kv *model.KVDoc) {
+	if p.cursor != nil && !p.before(p.cursor, kv) {
+		return
+	}
+	if p.limit == 0 {
+		p.kvs = append(p.kvs, kv)
+		return
+	}
+	heap.Push(p, kv)
+	if len(p.kvs) > p.limit+1 {
+		heap.Pop(p)
+	}
+}
+
+// Result return the sorted kvs of the page, and the token of the next page if there are more kvs
+func (p *CursorPage) Result() ([]*model.KVDoc, string) {
+	sort.Slice(p.kvs, func(i, j int) bool {
+		return p.before(p.kvs[i], p.kvs[j])
+	})
+	if p.kvs == nil {
+		p.kvs = []*model.KVDoc{}
+	}
+	if p.limit == 0 || len(p.kvs) <= p.limit {
+		return p.kvs, ""
+	}
+	kvs := p.kvs[:p.limit]
+	return kvs, NewCursor(kvs[p.limit-1], p.field, p.desc).Encode()
+}
+
+// heap.Interface, the top of the heap is the last kv in the sort order
+
+func (p *CursorPage) Len() int { return len(p.kvs) }
+
+func (p *CursorPage) Less(i, j int) bool { return p.before(p.kvs[j], p.kvs[i]) }
+
+func (p *CursorPage) Swap(i, j int) { p.kvs[i], p.kvs[j] = p.kvs[j], p.kvs[i] }
+
+func (p *CursorPage) Push(x interface{}) { p.kvs = append(p.kvs, x.(*model.KVDoc)) }
+
+func (p *CursorPage) Pop() interface{} {
+	kv := p.kvs[len(p.kvs)-1]
+	p.kvs = p.kvs[:len(p.kvs)-1]
+	return kv
+}
@@ -25,6 +25,7 @@ import (
 
 	"github.com/apache/servicecomb-kie/pkg/common"
 	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/pkg/selector"
 	"github.com/apache/servicecomb-kie/server/datasource"
 	kvsvc "github.com/apache/servicecomb-kie/server/service/kv"
 	"github.com/apache/servicecomb-kie/server/service/sync"
@@ -108,6 +109,68 @@ func TestList(t *testing.T) {
 	assert.NoError(t, delErr)
 }
 
+func TestListWithSelector(t *testing.T) {
+	ctx := context.TODO()
+	labelsList := []map[string]string{
+		{"env": "prod", "version": "1.0"},
+		{"env": "test", "version": "1.1", "canary": "true"},
+		{"env": "dev", "region": "cn"},
+		{"version": "1.2"},
+	}
+	var kvs []*model.KVDoc
+	for _, labels := range labelsList {
+		kv, err := kvsvc.Create(ctx, &model.KVDoc{
+			Key:     "TestListWithSelector",
+			Value:   "1",
+			Status:  common.StatusEnabled,
+			Labels:  labels,
+			Domain:  "default",
+			Project: "kv-selector-test",
+		})
+		assert.Nil(t, err)
+		kvs = append(kvs, kv)
+	}
+	defer func() {
+		for _, kv := range kvs {
+			_, err := kvsvc.FindOneAndDelete(ctx, kv.ID, "kv-selector-test", "default")
+			assert.NoError(t, err)
+		}
+	}()
+
+	tests := []struct {
+		requirements []string
+		want         []int
+	}{
+		{[]string{"env!=prod"}, []int{1, 2, 3}},
+		{[]string{"version in (1.0,1.1)"}, []int{0, 1}},
+		{[]string{"version notin (1.0,1.1)"}, []int{2, 3}},
+		{[]string{"!canary"}, []int{0, 2, 3}},
+		{[]string{"region"}, []int{2}},
+		{[]string{"env", "env!=prod", "!canary"}, []int{2}},
+	}
+	for _, tt := range tests {
+		var s selector.Selector
+		for _, str := range tt.requirements {
+			r, err := selector.ParseRequirement(str)
+			assert.NoError(t, err)
+			s = append(s, r)
+		}
+		resp, err := datasource.GetBroker().GetKVDao().List(ctx, "kv-selector-test", "default",
+			datasource.WithSelector(s))
+		assert.NoError(t, err)
+		ids := make([]string, 0, len(resp.Data))
+		for _, kv := range resp.Data {
+			ids = append(ids, kv.ID)
+		}
+		want := make([]string, 0, len(tt.want))
+		for _, i := range tt.want {
+			assert.True(t, s.Matches(kvs[i].Labels))
+			want = append(want, kvs[i].ID)
+		}
+		assert.ElementsMatch(t, want, ids, tt.requirements)
+	}
+}
+
 func TestWithSync(t *testing.T) {
 	if test.IsEmbeddedetcdMode() {
 		return
@@ -230,3 +293,83 @@ func TestWithSync(t *testing.T) {
 	})
 
 }
+
+func TestListWithSortAndCursor(t *testing.T) {
+	ctx := context.TODO()
+	keys := []string{"sort-c", "sort-a", "sort-e", "sort-b", "sort-d"}
+	var kvs []*model.KVDoc
+	for _, key := range keys {
+		kv, err := kvsvc.Create(ctx, &model.KVDoc{
+			Key:     key,
+			Value:   "1",
+			Status:  common.StatusEnabled,
+			Labels:  map[string]string{"app": "sort"},
+			Domain:  "default",
+			Project: "kv-sort-test",
+		})
+		assert.Nil(t, err)
+		kvs = append(kvs, kv)
+	}
+	defer func() {
+		for _, kv := range kvs {
+			_, err := kvsvc.FindOneAndDelete(ctx, kv.ID, "kv-sort-test", "default")
+			assert.NoError(t, err)
+		}
+	}()
+
+	tests := []struct {
+		field string
+		desc  bool
+		want  []string
+	}{
+		{datasource.SortKey, false, []string{"sort-a", "sort-b", "sort-c", "sort-d", "sort-e"}},
+		{datasource.SortKey, true, []string{"sort-e", "sort-d", "sort-c", "sort-b", "sort-a"}},
+		{datasource.SortUpdateRevision, true, []string{"sort-d", "sort-b", "sort-e", "sort-a", "sort-c"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		var cursor *datasource.Cursor
+		for pages := 0; pages < 5; pages++ {
+			opts := []datasource.FindOption{datasource.WithSort(tt.field, tt.desc), datasource.WithLimit(2)}
+			if cursor != nil {
+				opts = append(opts, datasource.WithCursor(cursor))
+			}
+			resp, err := datasource.GetBroker().GetKVDao().List(ctx, "kv-sort-test", "default", opts...)
+			assert.NoError(t, err)
+			for _, kv := range resp.Data {
+				got = append(got, kv.Key)
+			}
+			if resp.NextCursor == "" {
+				break
+			}
+			cursor, err = datasource.DecodeCursor(resp.NextCursor)
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, tt.want, got, tt.field)
+	}
+}
+
+func TestCursorPage(t *testing.T) {
+	kvs := []*model.KVDoc{
+		{ID: "3", Priority: 1}, {ID: "1", Priority: 2}, {ID: "2", Priority: 1}, {ID: "4", Priority: 0},
+	}
+	page := datasource.NewCursorPage(datasource.FindOptions{Sort: datasource.SortPriority, Limit: 2})
+	for _, kv := range kvs {
+		page.Add(kv)
+	}
+	data, next := page.Result()
+	assert.Equal(t, []*model.KVDoc{kvs[3], kvs[2]}, data)
+	cursor, err := datasource.DecodeCursor(next)
+	assert.NoError(t, err)
+
+	page = datasource.NewCursorPage(datasource.FindOptions{Sort: datasource.SortPriority, Limit: 2, Cursor: cursor})
+	for _, kv := range kvs {
+		page.Add(kv)
+	}
+	data, next = page.Result()
+	assert.Equal(t, []*model.KVDoc{kvs[0], kvs[1]}, data)
+	assert.Empty(t, next)
+
+	_, err = datasource.DecodeCursor("not a cursor")
+	assert.Equal(t, datasource.ErrInvalidCursor, err)
+}
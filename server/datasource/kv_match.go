@@ -0,0 +1,237 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package datasource
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/go-chassis/openlog"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/pkg/util"
+)
+
+// kvs are matched in memory by backends which do not query by conditions
+
+// IsUniqueFind return true if at most one kv matches the options
+func IsUniqueFind(opts FindOptions) bool {
+	return opts.LabelFormat != "" && opts.Key != ""
+}
+
+// KeyRegex compiles the key option, it supports beginWith() and wildcard()
+func KeyRegex(opts FindOptions) (*regexp.Regexp, error) {
+	var value string
+	if opts.Key == "" {
+		return nil, nil
+	}
+	switch {
+	case strings.HasPrefix(opts.Key, "beginWith("):
+		value = strings.ReplaceAll(getValue(opts.Key), ".", "\\.") + ".*"
+	case strings.HasPrefix(opts.Key, "wildcard("):
+		value = strings.ReplaceAll(getValue(opts.Key), ".", "\\.")
+		value = strings.ReplaceAll(value, "*", ".*")
+	default:
+		value = strings.ReplaceAll(opts.Key, ".", "\\.")
+	}
+	value = "^" + value + "$"
+	if !opts.CaseSensitive {
+		value = "(?i)" + value
+	}
+	regex, err := regexp.Compile(value)
+	if err != nil {
+		openlog.Error("invalid wildcard expr: " + value + ", error: " + err.Error())
+		return nil, err
+	}
+	return regex, nil
+}
+
+// Extract key values
+func getValue(str string) string {
+	rex := regexp.MustCompile(`\(([^)]+)\)`)
+	res := rex.FindStringSubmatch(str)
+	return res[len(res)-1]
+}
+
+// MatchKV checks status, key, labels and value of the kv
+func MatchKV(doc *model.KVDoc, opts FindOptions, regex *regexp.Regexp) bool {
+	if opts.Status != "" && doc.Status != opts.Status {
+		return false
+	}
+	if !MatchIDs(doc, opts) {
+		return false
+	}
+	if regex != nil && !regex.MatchString(doc.Key) {
+		return false
+	}
+	if len(opts.Labels) != 0 {
+		if opts.ExactLabels && !util.IsEquivalentLabel(opts.Labels, doc.Labels) {
+			return false
+		}
+		if !opts.ExactLabels && !util.IsContainLabel(doc.Labels, opts.Labels) {
+			return false
+		}
+	}
+	if len(opts.Selector) != 0 && !opts.Selector.Matches(doc.Labels) {
+		return false
+	}
+	if opts.LabelFormat != "" && doc.LabelFormat != opts.LabelFormat {
+		return false
+	}
+	if opts.Value != "" && !strings.Contains(doc.Value, opts.Value) {
+		return false
+	}
+	return true
+}
+
+// MatchIDs return true if ids are not limited, or the id of the kv is one of them
+func MatchIDs(doc *model.KVDoc, opts FindOptions) bool {
+	if len(opts.IDs) == 0 {
+		return true
+	}
+	for _, id := range opts.IDs {
+		if doc.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchRegex holds the compiled regexes of a search
+type SearchRegex struct {
+	key   *regexp.Regexp
+	value *regexp.Regexp
+}
+
+// NewSearchRegex return nil if the options are not a search
+func NewSearchRegex(opts FindOptions) (*SearchRegex, error) {
+	if !opts.IsSearch() {
+		return nil, nil
+	}
+	s := &SearchRegex{}
+	var err error
+	if s.key, err = compileSearchRegex(opts.KeyRegex, opts.CaseSensitive); err != nil {
+		return nil, err
+	}
+	if s.value, err = compileSearchRegex(opts.ValueRegex, opts.CaseSensitive); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func compileSearchRegex(expr string, caseSensitive bool) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	if !caseSensitive {
+		expr = "(?i)" + expr
+	}
+	return regexp.Compile(expr)
+}
+
+// Match checks the search conditions, a nil SearchRegex matches all kvs
+func (s *SearchRegex) Match(doc *model.KVDoc, opts FindOptions) bool {
+	if s == nil {
+		return true
+	}
+	if s.key != nil && !s.key.MatchString(doc.Key) {
+		return false
+	}
+	if s.value != nil && !s.value.MatchString(doc.Value) {
+		return false
+	}
+	if opts.ValueType != "" && doc.ValueType != opts.ValueType {
+		return false
+	}
+	if opts.UpdatedAfter != 0 && doc.UpdateTime < opts.UpdatedAfter {
+		return false
+	}
+	if opts.UpdatedBefore != 0 && doc.UpdateTime > opts.UpdatedBefore {
+		return false
+	}
+	return true
+}
+
+// KVCollector collects matched kvs which are allowed,
+// in sort mode, it only holds the page after the cursor instead of all matched kvs
+type KVCollector struct {
+	allow  func(*model.KVDoc) bool
+	page   *CursorPage
+	result *model.KVResponse
+}
+
+// NewKVCollector return a collector, allow is nil if all kvs are allowed
+func NewKVCollector(opts FindOptions, allow func(*model.KVDoc) bool) *KVCollector {
+	c := &KVCollector{
+		allow:  allow,
+		result: &model.KVResponse{Data: []*model.KVDoc{}},
+	}
+	if opts.Sort != "" {
+		c.page = NewCursorPage(opts)
+	}
+	return c
+}
+
+// Add return false if the kv is not allowed
+func (c *KVCollector) Add(doc *model.KVDoc) bool {
+	if c.allow != nil && !c.allow(doc) {
+		return false
+	}
+	c.result.Total++
+	if c.page != nil {
+		c.page.Add(doc)
+		return true
+	}
+	c.result.Data = append(c.result.Data, doc)
+	return true
+}
+
+// Done return the collected kvs
+func (c *KVCollector) Done() *model.KVResponse {
+	if c.page != nil {
+		c.result.Data, c.result.NextCursor = c.page.Result()
+		if c.result.Data == nil {
+			c.result.Data = []*model.KVDoc{}
+		}
+	}
+	return c.result
+}
+
+// PageKVs sorts kvs by priority and revision, and return the page of offset and limit,
+// kvs in sort mode are already sorted and paged by cursor
+func PageKVs(result *model.KVResponse, opts FindOptions) *model.KVResponse {
+	if opts.Sort != "" {
+		return result
+	}
+	ReverseByPriorityAndUpdateRev(result.Data)
+
+	if opts.Limit == 0 {
+		return result
+	}
+	total := int64(result.Total)
+	if opts.Offset >= total {
+		result.Data = []*model.KVDoc{}
+		return result
+	}
+	end := opts.Offset + opts.Limit
+	if end > total {
+		end = total
+	}
+	result.Data = result.Data[opts.Offset:end]
+	return result
+}
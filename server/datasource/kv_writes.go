@@ -0,0 +1,78 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package datasource
+
+import (
+	"github.com/go-chassis/cari/sync"
+	"github.com/go-chassis/openlog"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+)
+
+// KVWrites are the sync tasks, tombstones and outbox events of kv mutations,
+// backends without transaction ops prepare them before the mutations and save them together
+type KVWrites struct {
+	Options    WriteOptions
+	Tasks      []*sync.Task
+	Tombstones []*sync.Tombstone
+	Events     []*model.OutboxEvent
+}
+
+func NewKVWrites(options ...WriteOption) *KVWrites {
+	return &KVWrites{Options: NewWriteOptions(options...)}
+}
+
+// Put adds the task and the event of creating or updating the kv
+func (w *KVWrites) Put(kv *model.KVDoc, action string) error {
+	if w.Options.SyncEnable {
+		task, err := sync.NewTask(kv.Domain, kv.Project, action, ConfigResource, kv)
+		if err != nil {
+			openlog.Error("fail to create task" + err.Error())
+			return err
+		}
+		w.Tasks = append(w.Tasks, task)
+	}
+	return w.event(kv, OutboxActionPut)
+}
+
+// Delete adds the task, the tombstone and the event of deleting the kv
+func (w *KVWrites) Delete(kv *model.KVDoc) error {
+	if w.Options.SyncEnable {
+		task, err := sync.NewTask(kv.Domain, kv.Project, sync.DeleteAction, ConfigResource, kv)
+		if err != nil {
+			openlog.Error("fail to create task" + err.Error())
+			return err
+		}
+		w.Tasks = append(w.Tasks, task)
+		w.Tombstones = append(w.Tombstones, sync.NewTombstone(kv.Domain, kv.Project, ConfigResource, TombstoneID(kv)))
+	}
+	return w.event(kv, OutboxActionDelete)
+}
+
+func (w *KVWrites) event(kv *model.KVDoc, action string) error {
+	if !w.Options.OutboxEnable {
+		return nil
+	}
+	event, err := NewOutboxEvent(kv.Domain, kv.Project, kv, action, w.Options.Node)
+	if err != nil {
+		openlog.Error("fail to create outbox event: " + err.Error())
+		return err
+	}
+	w.Events = append(w.Events, event)
+	return nil
+}
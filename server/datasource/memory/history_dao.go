@@ -0,0 +1,138 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/datasource"
+	"github.com/apache/servicecomb-kie/server/datasource/auth"
+)
+
+// HistoryDao keeps revisions of kvs in memory
+type HistoryDao struct {
+}
+
+// GetHistory get all history by kv id
+func (s *HistoryDao) GetHistory(ctx context.Context, kvID, project, domain string, options ...datasource.FindOption) (*model.KVResponse, error) {
+	kvdoc, err := datasource.GetBroker().GetKVDao().Get(ctx, &model.GetKVRequest{
+		Domain:  domain,
+		Project: project,
+		ID:      kvID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.CheckGetKV(ctx, kvdoc); err != nil {
+		return nil, err
+	}
+
+	opts := datasource.FindOptions{}
+	for _, o := range options {
+		o(&opts)
+	}
+	histories := db.listHistories(domain, project, kvID)
+	return &model.KVResponse{
+		Data:  pagingResult(histories, opts.Offset, opts.Limit),
+		Total: len(histories),
+	}, nil
+}
+
+func (s *store) listHistories(domain, project, kvID string) []*model.KVDoc {
+	s.RLock()
+	defer s.RUnlock()
+	revisions := s.histories[join(domain, project, kvID)]
+	histories := make([]*model.KVDoc, 0, len(revisions))
+	for _, kv := range revisions {
+		histories = append(histories, cloneKV(kv))
+	}
+	return histories
+}
+
+func pagingResult(histories []*model.KVDoc, offset, limit int64) []*model.KVDoc {
+	total := int64(len(histories))
+	if limit != 0 && offset >= total {
+		return []*model.KVDoc{}
+	}
+
+	datasource.ReverseByPriorityAndUpdateRev(histories)
+
+	if limit == 0 {
+		return histories
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	return histories[offset:end]
+}
+
+// AddHistory add kv history, the oldest revisions beyond datasource.MaxHistoryNum are deleted
+func (s *HistoryDao) AddHistory(ctx context.Context, kv *model.KVDoc) error {
+	db.Lock()
+	defer db.Unlock()
+	k := join(kv.Domain, kv.Project, kv.ID)
+	revisions, ok := db.histories[k]
+	if !ok {
+		revisions = make(map[int64]*model.KVDoc)
+		db.histories[k] = revisions
+	}
+	revisions[kv.UpdateRevision] = cloneKV(kv)
+	if len(revisions) <= datasource.MaxHistoryNum {
+		return nil
+	}
+	histories := make([]*model.KVDoc, 0, len(revisions))
+	for _, h := range revisions {
+		histories = append(histories, h)
+	}
+	datasource.ReverseByPriorityAndUpdateRev(histories)
+	for _, h := range histories[datasource.MaxHistoryNum:] {
+		delete(revisions, h.UpdateRevision)
+	}
+	return nil
+}
+
+// DelayDeletionTime deletes all revisions of the kvs, there is no TTL in memory
+func (s *HistoryDao) DelayDeletionTime(ctx context.Context, kvIDs []string, project, domain string) error {
+	db.Lock()
+	defer db.Unlock()
+	for _, kvID := range kvIDs {
+		delete(db.histories, join(domain, project, kvID))
+	}
+	return nil
+}
+
+// carryHistories copies the revisions of old to kv, the caller must hold the lock
+func (s *store) carryHistories(old, kv *model.KVDoc) {
+	revisions := s.histories[join(old.Domain, old.Project, old.ID)]
+	if len(revisions) == 0 {
+		return
+	}
+	k := join(kv.Domain, kv.Project, kv.ID)
+	carried, ok := s.histories[k]
+	if !ok {
+		carried = make(map[int64]*model.KVDoc, len(revisions))
+		s.histories[k] = carried
+	}
+	for rev, h := range revisions {
+		c := cloneKV(h)
+		c.ID, c.Domain, c.Project = kv.ID, kv.Domain, kv.Project
+		carried[rev] = c
+	}
+}
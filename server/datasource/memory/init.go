@@ -0,0 +1,86 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package memory is a datasource keeping all data in memory, for tests and single node development,
+// data is lost once kie stops
+package memory
+
+import (
+	cdb "github.com/go-chassis/cari/db"
+	dconfig "github.com/go-chassis/cari/db/config"
+
+	edatasource "github.com/apache/servicecomb-service-center/eventbase/datasource"
+
+	"github.com/apache/servicecomb-kie/server/datasource"
+	rbacdao "github.com/apache/servicecomb-kie/server/datasource/rbac"
+)
+
+// Kind is the db kind of the memory datasource
+const Kind = "memory"
+
+type Broker struct {
+}
+
+func NewFrom(c *datasource.Config) (datasource.Broker, error) {
+	return &Broker{}, nil
+}
+func (*Broker) GetRevisionDao() datasource.RevisionDao {
+	return &RevisionDao{}
+}
+func (*Broker) GetKVDao() datasource.KVDao {
+	return &KVDao{}
+}
+func (*Broker) GetHistoryDao() datasource.HistoryDao {
+	return &HistoryDao{}
+}
+func (*Broker) GetTrackDao() datasource.TrackDao {
+	return &TrackDao{}
+}
+func (*Broker) GetRbacDao() rbacdao.Dao {
+	return &RbacDao{}
+}
+func (*Broker) GetOutboxDao() datasource.OutboxDao {
+	return &OutboxDao{}
+}
+func (*Broker) GetSchemaDao() datasource.SchemaDao {
+	return &SchemaDao{}
+}
+func (*Broker) GetLabelDao() datasource.LabelDao {
+	return &LabelDao{}
+}
+
+// syncSource keeps sync tasks and tombstones written with kvs
+type syncSource struct {
+}
+
+func (syncSource) TaskDao() edatasource.TaskDao {
+	return &TaskDao{}
+}
+func (syncSource) TombstoneDao() edatasource.TombstoneDao {
+	return &TombstoneDao{}
+}
+
+func init() {
+	datasource.RegisterPlugin(Kind, NewFrom)
+	// there is no db client to connect
+	cdb.Install(Kind, func(c *dconfig.Config) error {
+		return nil
+	})
+	edatasource.RegisterPlugin(Kind, func() edatasource.DataSource {
+		return syncSource{}
+	})
+}
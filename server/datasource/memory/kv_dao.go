@@ -0,0 +1,390 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+
+	"github.com/go-chassis/cari/sync"
+	"github.com/go-chassis/openlog"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/datasource"
+	"github.com/apache/servicecomb-kie/server/datasource/auth"
+)
+
+// KVDao operate kvs in memory
+type KVDao struct {
+}
+
+// saveWrites must be called with the lock of the store held
+func (s *store) saveWrites(w *datasource.KVWrites) {
+	for _, task := range w.Tasks {
+		s.tasks[taskKey(task)] = task
+	}
+	for _, tombstone := range w.Tombstones {
+		s.tombstones[tombstoneKey(tombstone)] = tombstone
+	}
+	s.outbox = append(s.outbox, w.Events...)
+}
+
+func (s *KVDao) Create(ctx context.Context, kv *model.KVDoc, options ...datasource.WriteOption) (*model.KVDoc, error) {
+	if err := auth.CheckCreateKV(ctx, kv); err != nil {
+		return nil, err
+	}
+	w := datasource.NewKVWrites(options...)
+	if err := w.Put(kv, sync.CreateAction); err != nil {
+		return nil, err
+	}
+	db.Lock()
+	if db.getKV(kv.Domain, kv.Project, kv.ID) != nil {
+		db.Unlock()
+		openlog.Error("create error", openlog.WithTags(openlog.Tags{
+			"err": datasource.ErrKVAlreadyExists.Error(),
+			"kv":  kv,
+		}))
+		return nil, datasource.ErrKVAlreadyExists
+	}
+	db.putKV(kv)
+	db.saveWrites(w)
+	db.countLabels(datasource.CountLabels(kv.Domain, kv.Project, []*model.KVDoc{kv}, 1))
+	db.Unlock()
+	return kv, nil
+}
+
+// Update update key value
+func (s *KVDao) Update(ctx context.Context, kv *model.KVDoc, options ...datasource.WriteOption) error {
+	old := db.loadKV(kv.Domain, kv.Project, kv.ID)
+	if old == nil {
+		return datasource.ErrKeyNotExists
+	}
+	if err := auth.CheckUpdateKV(ctx, old); err != nil {
+		return err
+	}
+	old.LabelFormat = kv.LabelFormat
+	old.Value = kv.Value
+	old.Status = kv.Status
+	old.Checker = kv.Checker
+	old.Priority = kv.Priority
+	old.UpdateTime = kv.UpdateTime
+	old.UpdateRevision = kv.UpdateRevision
+
+	w := datasource.NewKVWrites(options...)
+	if err := w.Put(old, sync.UpdateAction); err != nil {
+		return err
+	}
+	db.Lock()
+	defer db.Unlock()
+	if db.getKV(kv.Domain, kv.Project, kv.ID) == nil {
+		return datasource.ErrKeyNotExists
+	}
+	db.putKV(old)
+	db.saveWrites(w)
+	return nil
+}
+
+// Exist supports you query a key value by label map or labels id
+func (s *KVDao) Exist(ctx context.Context, key, project, domain string, options ...datasource.FindOption) (bool, error) {
+	kvs, err := s.GetByKey(ctx, key, project, domain, options...)
+	if err == datasource.ErrKeyNotExists {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return len(kvs) == 1, nil
+}
+
+func (s *KVDao) GetByKey(ctx context.Context, key, project, domain string, options ...datasource.FindOption) ([]*model.KVDoc, error) {
+	opts := datasource.FindOptions{Key: key}
+	for _, o := range options {
+		o(&opts)
+	}
+	kvs, err := s.list(ctx, project, domain, nil,
+		datasource.WithExactLabels(),
+		datasource.WithLabels(opts.Labels),
+		datasource.WithLabelFormat(opts.LabelFormat),
+		datasource.WithKey(key),
+		datasource.WithCaseSensitive())
+	if err != nil {
+		openlog.Error("check kv exist: " + err.Error())
+		return nil, err
+	}
+	if datasource.IsUniqueFind(opts) && len(kvs.Data) == 0 {
+		return nil, datasource.ErrKeyNotExists
+	}
+	if len(kvs.Data) != 1 {
+		return nil, datasource.ErrTooMany
+	}
+	return kvs.Data, nil
+}
+
+// FindOneAndDelete deletes one kv by id and return the deleted kv as these appeared before deletion
+func (s *KVDao) FindOneAndDelete(ctx context.Context, kvID, project, domain string, options ...datasource.WriteOption) (*model.KVDoc, error) {
+	kv, err := getKVToDelete(ctx, domain, project, kvID)
+	if err != nil {
+		return nil, err
+	}
+	w := datasource.NewKVWrites(options...)
+	if err := w.Delete(kv); err != nil {
+		return nil, err
+	}
+	db.Lock()
+	defer db.Unlock()
+	if db.getKV(domain, project, kvID) == nil {
+		return nil, datasource.ErrKeyNotExists
+	}
+	db.deleteKV(domain, project, kvID)
+	db.saveWrites(w)
+	db.countLabels(datasource.CountLabels(domain, project, []*model.KVDoc{kv}, -1))
+	return kv, nil
+}
+
+// getKVToDelete checks the permission out of the lock, because permissions are read from the store too
+func getKVToDelete(ctx context.Context, domain, project, kvID string) (*model.KVDoc, error) {
+	kv := db.loadKV(domain, project, kvID)
+	if kv == nil {
+		return nil, datasource.ErrKeyNotExists
+	}
+	if err := auth.CheckDeleteKV(ctx, kv); err != nil {
+		return nil, err
+	}
+	return kv, nil
+}
+
+// FindManyAndDelete deletes multiple kvs and return the deleted kv list as these appeared before deletion
+func (s *KVDao) FindManyAndDelete(ctx context.Context, kvIDs []string, project, domain string, options ...datasource.WriteOption) ([]*model.KVDoc, int64, error) {
+	w := datasource.NewKVWrites(options...)
+	var found []*model.KVDoc
+	for _, id := range kvIDs {
+		kv, err := getKVToDelete(ctx, domain, project, id)
+		if err == datasource.ErrKeyNotExists {
+			continue
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		found = append(found, kv)
+	}
+	db.Lock()
+	defer db.Unlock()
+	var docs []*model.KVDoc
+	for _, kv := range found {
+		// deleted concurrently
+		if db.getKV(domain, project, kv.ID) == nil {
+			continue
+		}
+		if err := w.Delete(kv); err != nil {
+			return nil, 0, err
+		}
+		docs = append(docs, kv)
+	}
+	if len(docs) == 0 {
+		return nil, 0, datasource.ErrKeyNotExists
+	}
+	for _, kv := range docs {
+		db.deleteKV(domain, project, kv.ID)
+	}
+	db.saveWrites(w)
+	db.countLabels(datasource.CountLabels(domain, project, docs, -1))
+	return docs, int64(len(docs)), nil
+}
+
+// Move replaces kv old by kv of another label set, the revisions of old are carried over to kv
+func (s *KVDao) Move(ctx context.Context, old, kv *model.KVDoc, override bool, options ...datasource.WriteOption) error {
+	if err := auth.CheckDeleteKV(ctx, old); err != nil {
+		return err
+	}
+	if err := auth.CheckCreateKV(ctx, kv); err != nil {
+		return err
+	}
+	w := datasource.NewKVWrites(options...)
+	if err := w.Delete(old); err != nil {
+		return err
+	}
+	if err := w.Put(kv, sync.CreateAction); err != nil {
+		return err
+	}
+	db.Lock()
+	defer db.Unlock()
+	cur := db.getKV(old.Domain, old.Project, old.ID)
+	if cur == nil {
+		return datasource.ErrKeyNotExists
+	}
+	if cur.UpdateRevision != old.UpdateRevision {
+		return datasource.ErrKVConflict
+	}
+	target := db.getKV(kv.Domain, kv.Project, kv.ID)
+	if target != nil && !override {
+		return datasource.ErrKVAlreadyExists
+	}
+	db.deleteKV(old.Domain, old.Project, old.ID)
+	db.putKV(kv)
+	db.carryHistories(old, kv)
+	db.saveWrites(w)
+	db.countLabels(datasource.CountLabels(old.Domain, old.Project, []*model.KVDoc{old}, -1))
+	if target == nil {
+		db.countLabels(datasource.CountLabels(kv.Domain, kv.Project, []*model.KVDoc{kv}, 1))
+	}
+	return nil
+}
+
+// Batch writes kvs at once, nothing is written if any of them conflicts
+func (s *KVDao) Batch(ctx context.Context, txn *datasource.KVTxn, options ...datasource.WriteOption) error {
+	for _, kv := range txn.Creates {
+		if err := auth.CheckCreateKV(ctx, kv); err != nil {
+			return err
+		}
+	}
+	for _, kv := range txn.Updates {
+		if err := auth.CheckUpdateKV(ctx, kv); err != nil {
+			return err
+		}
+	}
+	for _, kv := range txn.Deletes {
+		if err := auth.CheckDeleteKV(ctx, kv); err != nil {
+			return err
+		}
+	}
+	w := datasource.NewKVWrites(options...)
+	for _, kv := range txn.Creates {
+		if err := w.Put(kv, sync.CreateAction); err != nil {
+			return err
+		}
+	}
+	for _, kv := range txn.Updates {
+		if err := w.Put(kv, sync.UpdateAction); err != nil {
+			return err
+		}
+	}
+	for _, kv := range txn.Deletes {
+		if err := w.Delete(kv); err != nil {
+			return err
+		}
+	}
+	db.Lock()
+	defer db.Unlock()
+	for _, kv := range txn.Creates {
+		if db.getKV(kv.Domain, kv.Project, kv.ID) != nil {
+			return datasource.ErrKVConflict
+		}
+	}
+	for _, kvs := range [][]*model.KVDoc{txn.Updates, txn.Deletes} {
+		for _, kv := range kvs {
+			if !db.unchanged(kv, txn.Revisions) {
+				return datasource.ErrKVConflict
+			}
+		}
+	}
+	for _, kv := range txn.Creates {
+		db.putKV(kv)
+	}
+	for _, kv := range txn.Updates {
+		db.putKV(kv)
+	}
+	for _, kv := range txn.Deletes {
+		db.deleteKV(kv.Domain, kv.Project, kv.ID)
+	}
+	db.saveWrites(w)
+	if len(txn.Creates) != 0 {
+		db.countLabels(datasource.CountLabels(txn.Creates[0].Domain, txn.Creates[0].Project, txn.Creates, 1))
+	}
+	if len(txn.Deletes) != 0 {
+		db.countLabels(datasource.CountLabels(txn.Deletes[0].Domain, txn.Deletes[0].Project, txn.Deletes, -1))
+	}
+	return nil
+}
+
+// unchanged checks the kv exists, and its update revision is the same as the one in revisions if present
+func (s *store) unchanged(kv *model.KVDoc, revisions map[string]int64) bool {
+	cur := s.getKV(kv.Domain, kv.Project, kv.ID)
+	if cur == nil {
+		return false
+	}
+	rev, ok := revisions[kv.ID]
+	return !ok || cur.UpdateRevision == rev
+}
+
+// Get get kv by kv id
+func (s *KVDao) Get(ctx context.Context, req *model.GetKVRequest) (*model.KVDoc, error) {
+	kv := db.loadKV(req.Domain, req.Project, req.ID)
+	if kv == nil {
+		return nil, datasource.ErrKeyNotExists
+	}
+	if err := auth.CheckGetKV(ctx, kv); err != nil {
+		return nil, err
+	}
+	return kv, nil
+}
+
+// Total return the kv number of the project, or of the domain if project is empty
+func (s *KVDao) Total(ctx context.Context, project, domain string) (int64, error) {
+	db.RLock()
+	defer db.RUnlock()
+	if project != "" {
+		return int64(len(db.projectKVs(domain, project, false))), nil
+	}
+	var total int64
+	for _, kvs := range db.kvs {
+		for _, kv := range kvs {
+			if kv.Domain == domain {
+				total++
+			}
+		}
+	}
+	return total, nil
+}
+
+// List get kv list by key and criteria
+func (s *KVDao) List(ctx context.Context, project, domain string, options ...datasource.FindOption) (*model.KVResponse, error) {
+	return s.list(ctx, project, domain, auth.KVFilter(ctx), options...)
+}
+
+// list get kv list by key and criteria, kvs which are not allowed are filtered out
+func (s *KVDao) list(ctx context.Context, project, domain string, allow func(*model.KVDoc) bool,
+	options ...datasource.FindOption) (*model.KVResponse, error) {
+	opts := datasource.NewDefaultFindOpts()
+	for _, o := range options {
+		o(&opts)
+	}
+	regex, err := datasource.KeyRegex(opts)
+	if err != nil {
+		return nil, err
+	}
+	search, err := datasource.NewSearchRegex(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	db.RLock()
+	defer db.RUnlock()
+	kvs := db.projectKVs(domain, project, false)
+	c := datasource.NewKVCollector(opts, allow)
+	for _, id := range sortedIDs(kvs) {
+		doc := kvs[id]
+		if !datasource.MatchKV(doc, opts, regex) || !search.Match(doc, opts) {
+			continue
+		}
+		doc = cloneKV(doc)
+		datasource.ClearPart(doc)
+		if c.Add(doc) && datasource.IsUniqueFind(opts) {
+			break
+		}
+	}
+	return datasource.PageKVs(c.Done(), opts), nil
+}
@@ -0,0 +1,94 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/datasource"
+	"github.com/apache/servicecomb-kie/server/datasource/memory"
+	_ "github.com/apache/servicecomb-kie/test"
+)
+
+func TestKVDao(t *testing.T) {
+	memory.Reset()
+	ctx := context.TODO()
+	dao := &memory.KVDao{}
+	kv := &model.KVDoc{
+		ID:          "1",
+		Key:         "timeout",
+		Value:       "1s",
+		Labels:      map[string]string{"app": "mall"},
+		LabelFormat: "app=mall",
+		Domain:      "default",
+		Project:     "memory-test",
+	}
+
+	t.Run("create kv twice, should return ErrKVAlreadyExists", func(t *testing.T) {
+		_, err := dao.Create(ctx, kv)
+		assert.NoError(t, err)
+		_, err = dao.Create(ctx, kv)
+		assert.Equal(t, datasource.ErrKVAlreadyExists, err)
+
+		kv.Value = "changed"
+		got, err := dao.Get(ctx, &model.GetKVRequest{ID: "1", Domain: "default", Project: "memory-test"})
+		assert.NoError(t, err)
+		assert.Equal(t, "1s", got.Value)
+	})
+	t.Run("batch with a conflict, should write nothing", func(t *testing.T) {
+		created := &model.KVDoc{ID: "2", Key: "retry", Value: "3", Domain: "default", Project: "memory-test"}
+		err := dao.Batch(ctx, &datasource.KVTxn{
+			Creates:   []*model.KVDoc{created},
+			Updates:   []*model.KVDoc{kv},
+			Revisions: map[string]int64{"1": 100},
+		})
+		assert.Equal(t, datasource.ErrKVConflict, err)
+		_, err = dao.Get(ctx, &model.GetKVRequest{ID: "2", Domain: "default", Project: "memory-test"})
+		assert.Equal(t, datasource.ErrKeyNotExists, err)
+
+		err = dao.Batch(ctx, &datasource.KVTxn{Creates: []*model.KVDoc{created}})
+		assert.NoError(t, err)
+		n, err := dao.Total(ctx, "memory-test", "default")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), n)
+	})
+	t.Run("list by key and labels, should clear domain and project", func(t *testing.T) {
+		resp, err := dao.List(ctx, "memory-test", "default",
+			datasource.WithKey("beginWith(time)"), datasource.WithLabels(map[string]string{"app": "mall"}))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, resp.Total)
+		assert.Equal(t, "timeout", resp.Data[0].Key)
+		assert.Empty(t, resp.Data[0].Project)
+	})
+	t.Run("delete kv, should decrease the count of the label set", func(t *testing.T) {
+		labels, err := (&memory.LabelDao{}).List(ctx, "memory-test", "default")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(labels))
+
+		_, err = dao.FindOneAndDelete(ctx, "1", "memory-test", "default")
+		assert.NoError(t, err)
+		id := datasource.LabelID("default", "memory-test", "app=mall")
+		assert.NoError(t, (&memory.LabelDao{}).DeleteOrphan(ctx, id, "memory-test", "default"))
+		_, err = dao.FindOneAndDelete(ctx, "1", "memory-test", "default")
+		assert.Equal(t, datasource.ErrKeyNotExists, err)
+	})
+}
@@ -0,0 +1,96 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/datasource"
+)
+
+// LabelDao operate the label catalog in memory
+type LabelDao struct {
+}
+
+// countLabels adds the counts of label sets, label sets are created on first use,
+// it must be called with the lock of the store held
+func (s *store) countLabels(docs []*model.LabelDoc) {
+	for _, doc := range docs {
+		k := join(doc.Domain, doc.Project)
+		labels, ok := s.labels[k]
+		if !ok {
+			labels = make(map[string]*model.LabelDoc)
+			s.labels[k] = labels
+		}
+		cur, ok := labels[doc.ID]
+		if !ok {
+			c := *doc
+			c.Count = 0
+			cur = &c
+			labels[doc.ID] = cur
+		}
+		cur.Count += doc.Count
+		if cur.Count < 0 {
+			cur.Count = 0
+		}
+	}
+}
+
+func (d *LabelDao) List(ctx context.Context, project, domain string) ([]*model.LabelDoc, error) {
+	db.RLock()
+	defer db.RUnlock()
+	labels := db.labels[join(domain, project)]
+	docs := make([]*model.LabelDoc, 0, len(labels))
+	for _, doc := range labels {
+		c := *doc
+		docs = append(docs, &c)
+	}
+	sort.Slice(docs, func(i, j int) bool {
+		return docs[i].ID < docs[j].ID
+	})
+	return docs, nil
+}
+
+func (d *LabelDao) UpdateAlias(ctx context.Context, id, project, domain, alias string) (*model.LabelDoc, error) {
+	db.Lock()
+	defer db.Unlock()
+	doc, ok := db.labels[join(domain, project)][id]
+	if !ok {
+		return nil, datasource.ErrLabelNotExists
+	}
+	doc.Alias = alias
+	c := *doc
+	return &c, nil
+}
+
+func (d *LabelDao) DeleteOrphan(ctx context.Context, id, project, domain string) error {
+	db.Lock()
+	defer db.Unlock()
+	labels := db.labels[join(domain, project)]
+	doc, ok := labels[id]
+	if !ok {
+		return datasource.ErrLabelNotExists
+	}
+	if doc.Count != 0 {
+		return datasource.ErrLabelInUse
+	}
+	delete(labels, id)
+	return nil
+}
@@ -0,0 +1,72 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+)
+
+// OutboxDao keeps outbox events in memory
+type OutboxDao struct {
+}
+
+func (d *OutboxDao) List(ctx context.Context, after *model.OutboxEvent, limit int64) ([]*model.OutboxEvent, error) {
+	db.RLock()
+	defer db.RUnlock()
+	events := make([]*model.OutboxEvent, 0)
+	for _, event := range db.outbox {
+		if limit > 0 && int64(len(events)) >= limit {
+			break
+		}
+		if after != nil && !later(event, after) {
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// later return true if event a is after event b, events are ordered by timestamp then id
+func later(a, b *model.OutboxEvent) bool {
+	if a.Timestamp != b.Timestamp {
+		return a.Timestamp > b.Timestamp
+	}
+	return a.ID > b.ID
+}
+
+func (d *OutboxDao) Done(ctx context.Context, events []*model.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	done := make(map[string]bool, len(events))
+	for _, event := range events {
+		done[event.ID] = true
+	}
+	db.Lock()
+	defer db.Unlock()
+	pending := make([]*model.OutboxEvent, 0, len(db.outbox))
+	for _, event := range db.outbox {
+		if !done[event.ID] {
+			pending = append(pending, event)
+		}
+	}
+	db.outbox = pending
+	return nil
+}
@@ -0,0 +1,60 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+	"errors"
+
+	crbac "github.com/go-chassis/cari/rbac"
+)
+
+// RbacDao reads roles and accounts in memory, they are written by PutRole and PutAccount
+type RbacDao struct {
+}
+
+func (re *RbacDao) GetRole(ctx context.Context, name string) (*crbac.Role, error) {
+	db.RLock()
+	defer db.RUnlock()
+	role, ok := db.roles[name]
+	if !ok {
+		return nil, errors.New("role not exist")
+	}
+	return role, nil
+}
+
+func (re *RbacDao) AccountExist(ctx context.Context, name string) (bool, error) {
+	db.RLock()
+	defer db.RUnlock()
+	_, ok := db.accounts[name]
+	return ok, nil
+}
+
+// PutRole saves the role, there is no role management api in kie, so roles are seeded by callers
+func PutRole(role *crbac.Role) {
+	db.Lock()
+	defer db.Unlock()
+	db.roles[role.Name] = role
+}
+
+// PutAccount saves the account, there is no account management api in kie, so accounts are seeded by callers
+func PutAccount(account *crbac.Account) {
+	db.Lock()
+	defer db.Unlock()
+	db.accounts[account.Name] = account
+}
@@ -0,0 +1,66 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+
+	"github.com/apache/servicecomb-kie/server/datasource"
+)
+
+// RevisionDao keeps revision counters in memory
+type RevisionDao struct {
+}
+
+// GetRevision return current revision number, revisions reserved but not handed out are left out
+func (s *RevisionDao) GetRevision(ctx context.Context, domain string) (int64, error) {
+	db.RLock()
+	counter := db.revisions[domain]
+	db.RUnlock()
+	return datasource.Revisions().Visible(domain, counter), nil
+}
+
+// GetProjectRevision return current revision number of the project
+func (s *RevisionDao) GetProjectRevision(ctx context.Context, project, domain string) (int64, error) {
+	db.RLock()
+	stored := db.projectRevisions[join(domain, project)]
+	db.RUnlock()
+	return datasource.Revisions().ProjectRevision(domain, project, stored), nil
+}
+
+// ApplyRevision increase revision number of the domain and the project, return the domain one,
+// the domain revision is the project revision as well
+func (s *RevisionDao) ApplyRevision(ctx context.Context, project, domain string) (int64, error) {
+	return datasource.Revisions().NextForProject(ctx, domain, project, reserve, storeProject)
+}
+
+// storeProject sets rev as the revision of the project
+func storeProject(ctx context.Context, domain, project string, rev int64) error {
+	db.Lock()
+	defer db.Unlock()
+	db.projectRevisions[join(domain, project)] = rev
+	return nil
+}
+
+// reserve adds n to the domain counter
+func reserve(ctx context.Context, domain string, n int64) (int64, error) {
+	db.Lock()
+	defer db.Unlock()
+	db.revisions[domain] += n
+	return db.revisions[domain], nil
+}
@@ -0,0 +1,98 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/datasource"
+)
+
+// SchemaDao operate json schemas of keys in memory
+type SchemaDao struct {
+}
+
+func (d *SchemaDao) Create(ctx context.Context, schema *model.SchemaDoc) (*model.SchemaDoc, error) {
+	db.Lock()
+	defer db.Unlock()
+	schemas := db.projectSchemas(schema.Domain, schema.Project)
+	if _, ok := schemas[schema.ID]; ok {
+		return nil, datasource.ErrSchemaAlreadyExists
+	}
+	c := *schema
+	schemas[schema.ID] = &c
+	return schema, nil
+}
+
+func (d *SchemaDao) Update(ctx context.Context, schema *model.SchemaDoc) error {
+	db.Lock()
+	defer db.Unlock()
+	c := *schema
+	db.projectSchemas(schema.Domain, schema.Project)[schema.ID] = &c
+	return nil
+}
+
+func (d *SchemaDao) Get(ctx context.Context, id, project, domain string) (*model.SchemaDoc, error) {
+	db.RLock()
+	defer db.RUnlock()
+	schema, ok := db.schemas[join(domain, project)][id]
+	if !ok {
+		return nil, datasource.ErrSchemaNotExists
+	}
+	c := *schema
+	return &c, nil
+}
+
+func (d *SchemaDao) List(ctx context.Context, project, domain string) ([]*model.SchemaDoc, error) {
+	db.RLock()
+	defer db.RUnlock()
+	schemas := db.schemas[join(domain, project)]
+	result := make([]*model.SchemaDoc, 0, len(schemas))
+	for _, schema := range schemas {
+		c := *schema
+		result = append(result, &c)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
+
+func (d *SchemaDao) Delete(ctx context.Context, id, project, domain string) error {
+	db.Lock()
+	defer db.Unlock()
+	schemas := db.schemas[join(domain, project)]
+	if _, ok := schemas[id]; !ok {
+		return datasource.ErrSchemaNotExists
+	}
+	delete(schemas, id)
+	return nil
+}
+
+// projectSchemas return schemas of the project, it must be called with the lock of the store held
+func (s *store) projectSchemas(domain, project string) map[string]*model.SchemaDoc {
+	k := join(domain, project)
+	schemas, ok := s.schemas[k]
+	if !ok {
+		schemas = make(map[string]*model.SchemaDoc)
+		s.schemas[k] = schemas
+	}
+	return schemas
+}
@@ -0,0 +1,155 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	crbac "github.com/go-chassis/cari/rbac"
+	csync "github.com/go-chassis/cari/sync"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/datasource"
+)
+
+// store holds all data of the memory broker, writes hold the lock while checking and saving,
+// so they are atomic the same as transactions of other backends.
+// permissions are checked out of the lock, because roles are kept in the store too
+type store struct {
+	sync.RWMutex
+	data
+}
+
+type data struct {
+	// kvs are indexed by domain/project, then id
+	kvs map[string]map[string]*model.KVDoc
+	// histories are indexed by domain/project/kv id, then update revision
+	histories map[string]map[int64]*model.KVDoc
+	// tracks are indexed by domain/project, then revision/session id
+	tracks map[string]map[string]*model.PollingDetail
+	// revisions are indexed by domain, revisions of projects are indexed by domain/project
+	revisions        map[string]int64
+	projectRevisions map[string]int64
+	// outbox events are in the order of writes
+	outbox     []*model.OutboxEvent
+	schemas    map[string]map[string]*model.SchemaDoc
+	labels     map[string]map[string]*model.LabelDoc
+	roles      map[string]*crbac.Role
+	accounts   map[string]*crbac.Account
+	tasks      map[string]*csync.Task
+	tombstones map[string]*csync.Tombstone
+}
+
+var db = newStore()
+
+func newStore() *store {
+	return &store{data: newData()}
+}
+
+func newData() data {
+	return data{
+		kvs:              make(map[string]map[string]*model.KVDoc),
+		histories:        make(map[string]map[int64]*model.KVDoc),
+		tracks:           make(map[string]map[string]*model.PollingDetail),
+		revisions:        make(map[string]int64),
+		projectRevisions: make(map[string]int64),
+		schemas:          make(map[string]map[string]*model.SchemaDoc),
+		labels:           make(map[string]map[string]*model.LabelDoc),
+		roles:            make(map[string]*crbac.Role),
+		accounts:         make(map[string]*crbac.Account),
+		tasks:            make(map[string]*csync.Task),
+		tombstones:       make(map[string]*csync.Tombstone),
+	}
+}
+
+// Reset drops all data, it is for tests
+func Reset() {
+	db.Lock()
+	domains := make([]string, 0, len(db.revisions))
+	for domain := range db.revisions {
+		domains = append(domains, domain)
+	}
+	db.data = newData()
+	db.Unlock()
+	// the allocator calls into the store with its lock held, so blocks are dropped after the store is unlocked
+	for _, domain := range domains {
+		datasource.Revisions().Drop(domain)
+	}
+}
+
+func join(parts ...string) string {
+	return strings.Join(parts, "/")
+}
+
+// projectKVs return kvs of the project, the map is created if create is true
+func (s *store) projectKVs(domain, project string, create bool) map[string]*model.KVDoc {
+	k := join(domain, project)
+	kvs, ok := s.kvs[k]
+	if !ok && create {
+		kvs = make(map[string]*model.KVDoc)
+		s.kvs[k] = kvs
+	}
+	return kvs
+}
+
+func (s *store) getKV(domain, project, id string) *model.KVDoc {
+	return s.projectKVs(domain, project, false)[id]
+}
+
+// loadKV return a copy of the kv, or nil if it does not exist
+func (s *store) loadKV(domain, project, id string) *model.KVDoc {
+	s.RLock()
+	defer s.RUnlock()
+	kv := s.getKV(domain, project, id)
+	if kv == nil {
+		return nil
+	}
+	return cloneKV(kv)
+}
+
+func (s *store) putKV(kv *model.KVDoc) {
+	s.projectKVs(kv.Domain, kv.Project, true)[kv.ID] = cloneKV(kv)
+}
+
+func (s *store) deleteKV(domain, project, id string) {
+	delete(s.projectKVs(domain, project, false), id)
+}
+
+// cloneKV copies the kv, so that callers can not change the stored one
+func cloneKV(kv *model.KVDoc) *model.KVDoc {
+	c := *kv
+	if kv.Labels != nil {
+		c.Labels = make(map[string]string, len(kv.Labels))
+		for k, v := range kv.Labels {
+			c.Labels[k] = v
+		}
+	}
+	return &c
+}
+
+// sortedIDs return ids in order, the same as the key order of etcd
+func sortedIDs(kvs map[string]*model.KVDoc) []string {
+	ids := make([]string, 0, len(kvs))
+	for id := range kvs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,179 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/go-chassis/cari/sync"
+
+	edatasource "github.com/apache/servicecomb-service-center/eventbase/datasource"
+	emodel "github.com/apache/servicecomb-service-center/eventbase/model"
+)
+
+// TaskDao keeps sync tasks in memory
+type TaskDao struct {
+}
+
+// taskKey orders tasks of the project by timestamp, the same as etcd
+func taskKey(task *sync.Task) string {
+	return join(task.Domain, task.Project, strconv.FormatInt(task.Timestamp, 10), task.ID)
+}
+
+func tombstoneKey(tombstone *sync.Tombstone) string {
+	return join(tombstone.Domain, tombstone.Project, tombstone.ResourceType, tombstone.ResourceID)
+}
+
+func (d *TaskDao) Create(ctx context.Context, task *sync.Task) (*sync.Task, error) {
+	db.Lock()
+	defer db.Unlock()
+	k := taskKey(task)
+	if _, ok := db.tasks[k]; ok {
+		return nil, edatasource.ErrTaskAlreadyExists
+	}
+	c := *task
+	db.tasks[k] = &c
+	return task, nil
+}
+
+func (d *TaskDao) Update(ctx context.Context, task *sync.Task) error {
+	db.Lock()
+	defer db.Unlock()
+	cur, ok := db.tasks[taskKey(task)]
+	if !ok {
+		return edatasource.ErrTaskNotExists
+	}
+	cur.Status = task.Status
+	return nil
+}
+
+func (d *TaskDao) Delete(ctx context.Context, tasks ...*sync.Task) error {
+	db.Lock()
+	defer db.Unlock()
+	for _, task := range tasks {
+		delete(db.tasks, taskKey(task))
+	}
+	return nil
+}
+
+func (d *TaskDao) List(ctx context.Context, options ...edatasource.TaskFindOption) ([]*sync.Task, error) {
+	opts := edatasource.NewTaskFindOptions()
+	for _, o := range options {
+		o(&opts)
+	}
+	db.RLock()
+	defer db.RUnlock()
+	keys := make([]string, 0, len(db.tasks))
+	for k := range db.tasks {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	tasks := make([]*sync.Task, 0)
+	for _, k := range keys {
+		task := db.tasks[k]
+		if opts.Domain != "" && task.Domain != opts.Domain {
+			continue
+		}
+		if opts.Project != "" && task.Project != opts.Project {
+			continue
+		}
+		if opts.Action != "" && task.Action != opts.Action {
+			continue
+		}
+		if opts.ResourceType != "" && task.ResourceType != opts.ResourceType {
+			continue
+		}
+		if opts.Status != "" && task.Status != opts.Status {
+			continue
+		}
+		c := *task
+		tasks = append(tasks, &c)
+	}
+	return tasks, nil
+}
+
+// TombstoneDao keeps tombstones of deleted kvs in memory
+type TombstoneDao struct {
+}
+
+func (d *TombstoneDao) Get(ctx context.Context, req *emodel.GetTombstoneRequest) (*sync.Tombstone, error) {
+	db.RLock()
+	defer db.RUnlock()
+	tombstone, ok := db.tombstones[join(req.Domain, req.Project, req.ResourceType, req.ResourceID)]
+	if !ok {
+		return nil, edatasource.ErrTombstoneNotExists
+	}
+	c := *tombstone
+	return &c, nil
+}
+
+func (d *TombstoneDao) Create(ctx context.Context, tombstone *sync.Tombstone) (*sync.Tombstone, error) {
+	db.Lock()
+	defer db.Unlock()
+	k := tombstoneKey(tombstone)
+	if _, ok := db.tombstones[k]; ok {
+		return nil, edatasource.ErrTombstoneAlreadyExists
+	}
+	c := *tombstone
+	db.tombstones[k] = &c
+	return tombstone, nil
+}
+
+func (d *TombstoneDao) Delete(ctx context.Context, tombstones ...*sync.Tombstone) error {
+	db.Lock()
+	defer db.Unlock()
+	for _, tombstone := range tombstones {
+		delete(db.tombstones, tombstoneKey(tombstone))
+	}
+	return nil
+}
+
+func (d *TombstoneDao) List(ctx context.Context, options ...edatasource.TombstoneFindOption) ([]*sync.Tombstone, error) {
+	opts := edatasource.NewTombstoneFindOptions()
+	for _, o := range options {
+		o(&opts)
+	}
+	db.RLock()
+	defer db.RUnlock()
+	keys := make([]string, 0, len(db.tombstones))
+	for k := range db.tombstones {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	tombstones := make([]*sync.Tombstone, 0)
+	for _, k := range keys {
+		tombstone := db.tombstones[k]
+		if opts.Domain != "" && tombstone.Domain != opts.Domain {
+			continue
+		}
+		if opts.Project != "" && tombstone.Project != opts.Project {
+			continue
+		}
+		if opts.ResourceType != "" && tombstone.ResourceType != opts.ResourceType {
+			continue
+		}
+		if opts.BeforeTimestamp != 0 && tombstone.Timestamp > opts.BeforeTimestamp {
+			continue
+		}
+		c := *tombstone
+		tombstones = append(tombstones, &c)
+	}
+	return tombstones, nil
+}
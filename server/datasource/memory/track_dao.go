@@ -0,0 +1,83 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/datasource"
+)
+
+// TrackDao keeps polling details in memory
+type TrackDao struct {
+}
+
+// CreateOrUpdate create a record or update exist record
+// If revision and session_id exists then update else insert
+func (s *TrackDao) CreateOrUpdate(ctx context.Context, detail *model.PollingDetail) (*model.PollingDetail, error) {
+	db.Lock()
+	defer db.Unlock()
+	k := join(detail.Domain, detail.Project)
+	tracks, ok := db.tracks[k]
+	if !ok {
+		tracks = make(map[string]*model.PollingDetail)
+		db.tracks[k] = tracks
+	}
+	c := *detail
+	tracks[join(detail.Revision, detail.SessionID)] = &c
+	return detail, nil
+}
+
+// GetPollingDetail is to get a track data
+func (s *TrackDao) GetPollingDetail(ctx context.Context, detail *model.PollingDetail) ([]*model.PollingDetail, error) {
+	db.RLock()
+	defer db.RUnlock()
+	tracks := db.tracks[join(detail.Domain, detail.Project)]
+	keys := make([]string, 0, len(tracks))
+	for k := range tracks {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	records := make([]*model.PollingDetail, 0, len(keys))
+	for _, k := range keys {
+		doc := tracks[k]
+		if detail.SessionID != "" && doc.SessionID != detail.SessionID {
+			continue
+		}
+		if detail.IP != "" && doc.IP != detail.IP {
+			continue
+		}
+		if detail.UserAgent != "" && doc.UserAgent != detail.UserAgent {
+			continue
+		}
+		if detail.URLPath != "" && doc.URLPath != detail.URLPath {
+			continue
+		}
+		if detail.Revision != "" && doc.Revision != detail.Revision {
+			continue
+		}
+		c := *doc
+		records = append(records, &c)
+	}
+	if len(records) == 0 {
+		return nil, datasource.ErrRecordNotExists
+	}
+	return records, nil
+}
@@ -26,6 +26,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"github.com/apache/servicecomb-kie/server/datasource"
 	"github.com/apache/servicecomb-kie/server/datasource/mongo/model"
 )
 
@@ -35,38 +36,55 @@ const revision = "revision_counter"
 type Dao struct {
 }
 
-// GetRevision return current revision number
+// GetRevision return current revision number, revisions reserved but not handed out are left out
 func (s *Dao) GetRevision(ctx context.Context, domain string) (int64, error) {
-	collection := mongo.GetClient().GetDB().Collection(model.CollectionCounter)
-	filter := bson.M{"name": revision, "domain": domain}
-	cur, err := collection.Find(ctx, filter)
+	counter, err := getCounter(ctx, bson.M{"name": revision, "domain": domain})
 	if err != nil {
-		if err.Error() == context.DeadlineExceeded.Error() {
-			msg := "operation timeout"
-			openlog.Error(msg)
-			return 0, errors.New(msg)
-		}
 		return 0, err
 	}
-	defer cur.Close(ctx)
-	c := &Counter{}
-	for cur.Next(ctx) {
-		if err := cur.Decode(c); err != nil {
-			openlog.Error("decode error: " + err.Error())
-			return 0, err
-		}
+	return datasource.Revisions().Visible(domain, counter), nil
+}
+
+// GetProjectRevision return current revision number of the project
+func (s *Dao) GetProjectRevision(ctx context.Context, project, domain string) (int64, error) {
+	stored, err := getCounter(ctx, bson.M{"name": projectRevision(project), "domain": domain})
+	if err != nil {
+		return 0, err
 	}
-	return c.Count, nil
+	return datasource.Revisions().ProjectRevision(domain, project, stored), nil
 }
 
-// ApplyRevision increase revision number and return modified value
-func (s *Dao) ApplyRevision(ctx context.Context, domain string) (int64, error) {
+// ApplyRevision increase revision number of the domain and the project, return the domain one,
+// the domain revision is handed out from the reserved block, and it is the project revision as well
+func (s *Dao) ApplyRevision(ctx context.Context, project, domain string) (int64, error) {
+	return datasource.Revisions().NextForProject(ctx, domain, project, reserve, storeProject)
+}
+
+// storeProject sets rev as the revision of the project, the project counter is created by the first write of the project
+func storeProject(ctx context.Context, domain, project string, rev int64) error {
+	collection := mongo.GetClient().GetDB().Collection(model.CollectionCounter)
+	filter := bson.M{"name": projectRevision(project), "domain": domain}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "count", Value: rev}}}}
+	_, err := collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil && mongo.IsDuplicateKey(err) {
+		// created concurrently, the retry is a plain update
+		_, err = collection.UpdateOne(ctx, filter, update)
+	}
+	if err != nil {
+		openlog.Error("set project revision error: " + err.Error())
+		return err
+	}
+	return nil
+}
+
+// reserve adds n to the domain counter
+func reserve(ctx context.Context, domain string, n int64) (int64, error) {
 	collection := mongo.GetClient().GetDB().Collection(model.CollectionCounter)
 	filter := bson.M{"name": revision, "domain": domain}
 	sr := collection.FindOneAndUpdate(ctx, filter,
 		bson.D{
 			{Key: "$inc", Value: bson.D{
-				{Key: "count", Value: 1},
+				{Key: "count", Value: n},
 			}}}, options.FindOneAndUpdate().SetReturnDocument(options.After))
 	if sr.Err() != nil {
 		return 0, sr.Err()
@@ -79,3 +97,30 @@ func (s *Dao) ApplyRevision(ctx context.Context, domain string) (int64, error) {
 	}
 	return c.Count, nil
 }
+
+// projectRevision is the counter name of the project, the name and domain of counters are unique
+func projectRevision(project string) string {
+	return revision + "/" + project
+}
+
+func getCounter(ctx context.Context, filter bson.M) (int64, error) {
+	collection := mongo.GetClient().GetDB().Collection(model.CollectionCounter)
+	cur, err := collection.Find(ctx, filter)
+	if err != nil {
+		if err.Error() == context.DeadlineExceeded.Error() {
+			msg := "operation timeout"
+			openlog.Error(msg)
+			return 0, errors.New(msg)
+		}
+		return 0, err
+	}
+	defer cur.Close(ctx)
+	c := &Counter{}
+	for cur.Next(ctx) {
+		if err := cur.Decode(c); err != nil {
+			openlog.Error("decode error: " + err.Error())
+			return 0, err
+		}
+	}
+	return c.Count, nil
+}
@@ -32,7 +32,10 @@ import (
 	"github.com/apache/servicecomb-kie/server/datasource/mongo/counter"
 	"github.com/apache/servicecomb-kie/server/datasource/mongo/history"
 	"github.com/apache/servicecomb-kie/server/datasource/mongo/kv"
+	"github.com/apache/servicecomb-kie/server/datasource/mongo/label"
 	"github.com/apache/servicecomb-kie/server/datasource/mongo/model"
+	"github.com/apache/servicecomb-kie/server/datasource/mongo/outbox"
+	"github.com/apache/servicecomb-kie/server/datasource/mongo/schema"
 	"github.com/apache/servicecomb-kie/server/datasource/mongo/track"
 )
 
@@ -62,6 +65,15 @@ func (*Broker) GetTrackDao() datasource.TrackDao {
 func (*Broker) GetRbacDao() rbacdao.Dao {
 	return &rbac.Dao{}
 }
+func (*Broker) GetOutboxDao() datasource.OutboxDao {
+	return &outbox.Dao{}
+}
+func (*Broker) GetSchemaDao() datasource.SchemaDao {
+	return &schema.Dao{}
+}
+func (*Broker) GetLabelDao() datasource.LabelDao {
+	return &label.Dao{}
+}
 
 func ensureDB() error {
 	err := ensureRevisionCounter()
@@ -69,6 +81,9 @@ func ensureDB() error {
 	ensureKVRevision()
 	ensureView()
 	ensureKVLongPolling()
+	ensureOutbox()
+	ensureSchema()
+	ensureLabel()
 	return err
 }
 
@@ -142,6 +157,32 @@ func ensureKVLongPolling() {
 	dmongo.EnsureCollection(model.CollectionPollingDetail, validator, []mongo.IndexModel{timestampIndex, kvLongPollingIndex})
 }
 
+func ensureOutbox() {
+	idIndex := buildIndexDoc("id")
+	idIndex.Options = options.Index().SetUnique(true)
+	timestampIndex := buildIndexDoc("timestamp")
+	dmongo.EnsureCollection(model.CollectionOutbox, nil, []mongo.IndexModel{idIndex, timestampIndex})
+}
+
+func ensureSchema() {
+	jsonSchema := bson.M{
+		"bsonType": "object",
+		"required": []string{"id", "key", "domain", "project", "schema"},
+	}
+	validator := bson.M{
+		"$jsonSchema": jsonSchema,
+	}
+	schemaIndex := buildIndexDoc("id")
+	schemaIndex.Options = options.Index().SetUnique(true)
+	dmongo.EnsureCollection(model.CollectionSchema, validator, []mongo.IndexModel{schemaIndex, buildIndexDoc("domain", "project")})
+}
+
+func ensureLabel() {
+	labelIndex := buildIndexDoc("id", "domain", "project")
+	labelIndex.Options = options.Index().SetUnique(true)
+	dmongo.EnsureCollection(model.CollectionLabel, nil, []mongo.IndexModel{labelIndex, buildIndexDoc("domain", "project")})
+}
+
 func buildIndexDoc(keys ...string) mongo.IndexModel {
 	keysDoc := bsonx.Doc{}
 	for _, key := range keys {
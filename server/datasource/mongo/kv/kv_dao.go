@@ -19,6 +19,7 @@ package kv
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
@@ -31,9 +32,12 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/pkg/selector"
 	"github.com/apache/servicecomb-kie/pkg/util"
 	"github.com/apache/servicecomb-kie/server/datasource"
+	"github.com/apache/servicecomb-kie/server/datasource/mongo/label"
 	mmodel "github.com/apache/servicecomb-kie/server/datasource/mongo/model"
+	"github.com/apache/servicecomb-kie/server/datasource/mongo/outbox"
 )
 
 const (
@@ -47,11 +51,18 @@ type Dao struct {
 
 func (s *Dao) Create(ctx context.Context, kv *model.KVDoc, options ...datasource.WriteOption) (*model.KVDoc, error) {
 	opts := datasource.NewWriteOptions(options...)
-	if opts.SyncEnable {
+	var err error
+	if opts.InTxn() {
 		// if syncEnable is true, will create kv with task
-		return txnCreate(ctx, kv)
+		kv, err = txnCreate(ctx, kv, opts)
+	} else {
+		kv, err = create(ctx, kv)
 	}
-	return create(ctx, kv)
+	if err != nil {
+		return nil, err
+	}
+	label.Count(ctx, datasource.CountLabels(kv.Domain, kv.Project, []*model.KVDoc{kv}, 1))
+	return kv, nil
 }
 
 func create(ctx context.Context, kv *model.KVDoc) (*model.KVDoc, error) {
@@ -68,7 +79,7 @@ func create(ctx context.Context, kv *model.KVDoc) (*model.KVDoc, error) {
 }
 
 // txnCreate is to start transaction when creating KV, will create task in a transaction operation
-func txnCreate(ctx context.Context, kv *model.KVDoc) (*model.KVDoc, error) {
+func txnCreate(ctx context.Context, kv *model.KVDoc, opts datasource.WriteOptions) (*model.KVDoc, error) {
 	taskSession, err := dmongo.GetClient().GetDB().Client().StartSession()
 	if err != nil {
 		return nil, err
@@ -94,33 +105,41 @@ func txnCreate(ctx context.Context, kv *model.KVDoc) (*model.KVDoc, error) {
 			}
 			return err
 		}
-		task, err := sync.NewTask(kv.Domain, kv.Project, sync.CreateAction, datasource.ConfigResource, kv)
-		if err != nil {
-			openlog.Error("fail to create task" + err.Error())
-			errAbort := taskSession.AbortTransaction(sessionContext)
-			if errAbort != nil {
-				openlog.Error("fail to abort transaction", openlog.WithTags(openlog.Tags{
-					"err": errAbort.Error(),
-					"kv":  kv,
-				}))
+		if opts.SyncEnable {
+			task, err := sync.NewTask(kv.Domain, kv.Project, sync.CreateAction, datasource.ConfigResource, kv)
+			if err != nil {
+				openlog.Error("fail to create task" + err.Error())
+				errAbort := taskSession.AbortTransaction(sessionContext)
+				if errAbort != nil {
+					openlog.Error("fail to abort transaction", openlog.WithTags(openlog.Tags{
+						"err": errAbort.Error(),
+						"kv":  kv,
+					}))
+				}
+				return err
 			}
-			return err
-		}
-		collection = dmongo.GetClient().GetDB().Collection(mmodel.CollectionTask)
-		_, err = collection.InsertOne(sessionContext, task)
-		if err != nil {
-			openlog.Error("create task error", openlog.WithTags(openlog.Tags{
-				"err":  err.Error(),
-				"task": task,
-			}))
-			errAbort := taskSession.AbortTransaction(sessionContext)
-			if errAbort != nil {
-				openlog.Error("abort transaction", openlog.WithTags(openlog.Tags{
-					"err":  errAbort.Error(),
+			collection = dmongo.GetClient().GetDB().Collection(mmodel.CollectionTask)
+			_, err = collection.InsertOne(sessionContext, task)
+			if err != nil {
+				openlog.Error("create task error", openlog.WithTags(openlog.Tags{
+					"err":  err.Error(),
 					"task": task,
 				}))
+				errAbort := taskSession.AbortTransaction(sessionContext)
+				if errAbort != nil {
+					openlog.Error("abort transaction", openlog.WithTags(openlog.Tags{
+						"err":  errAbort.Error(),
+						"task": task,
+					}))
+				}
+				return err
+			}
+		}
+		if opts.OutboxEnable {
+			if err := outbox.InsertEvents(sessionContext, kv.Domain, kv.Project, []*model.KVDoc{kv}, datasource.OutboxActionPut, opts.Node); err != nil {
+				abort(sessionContext, taskSession)
+				return err
 			}
-			return err
 		}
 		if err = taskSession.CommitTransaction(sessionContext); err != nil {
 			return err
@@ -137,8 +156,8 @@ func txnCreate(ctx context.Context, kv *model.KVDoc) (*model.KVDoc, error) {
 func (s *Dao) Update(ctx context.Context, kv *model.KVDoc, options ...datasource.WriteOption) error {
 	opts := datasource.NewWriteOptions(options...)
 	// if syncEnable is true, will create kv with task
-	if opts.SyncEnable {
-		return txnUpdate(ctx, kv)
+	if opts.InTxn() {
+		return txnUpdate(ctx, kv, opts)
 	}
 	return update(ctx, kv)
 }
@@ -161,7 +180,7 @@ func update(ctx context.Context, kv *model.KVDoc) error {
 }
 
 // txnUpdate is to start transaction when updating kV, will create task in a transaction operation
-func txnUpdate(ctx context.Context, kv *model.KVDoc) error {
+func txnUpdate(ctx context.Context, kv *model.KVDoc, opts datasource.WriteOptions) error {
 	taskSession, err := dmongo.GetClient().GetDB().Client().StartSession()
 	if err != nil {
 		return err
@@ -207,33 +226,41 @@ func txnUpdate(ctx context.Context, kv *model.KVDoc) error {
 			openlog.Error("decode error: " + err.Error())
 			return err
 		}
-		task, err := sync.NewTask(kv.Domain, kv.Project, sync.UpdateAction, datasource.ConfigResource, curKV)
-		if err != nil {
-			openlog.Error("fail to create task" + err.Error())
-			errAbort := taskSession.AbortTransaction(sessionContext)
-			if errAbort != nil {
-				openlog.Error("abort transaction", openlog.WithTags(openlog.Tags{
-					"err": errAbort.Error(),
-					"kv":  kv,
-				}))
+		if opts.SyncEnable {
+			task, err := sync.NewTask(kv.Domain, kv.Project, sync.UpdateAction, datasource.ConfigResource, curKV)
+			if err != nil {
+				openlog.Error("fail to create task" + err.Error())
+				errAbort := taskSession.AbortTransaction(sessionContext)
+				if errAbort != nil {
+					openlog.Error("abort transaction", openlog.WithTags(openlog.Tags{
+						"err": errAbort.Error(),
+						"kv":  kv,
+					}))
+				}
+				return err
 			}
-			return err
-		}
-		collection = dmongo.GetClient().GetDB().Collection(mmodel.CollectionTask)
-		_, err = collection.InsertOne(sessionContext, task)
-		if err != nil {
-			openlog.Error("create task error", openlog.WithTags(openlog.Tags{
-				"err":  err.Error(),
-				"task": task,
-			}))
-			errAbort := taskSession.AbortTransaction(sessionContext)
-			if errAbort != nil {
-				openlog.Error("abort transaction", openlog.WithTags(openlog.Tags{
-					"err":  errAbort.Error(),
+			collection = dmongo.GetClient().GetDB().Collection(mmodel.CollectionTask)
+			_, err = collection.InsertOne(sessionContext, task)
+			if err != nil {
+				openlog.Error("create task error", openlog.WithTags(openlog.Tags{
+					"err":  err.Error(),
 					"task": task,
 				}))
+				errAbort := taskSession.AbortTransaction(sessionContext)
+				if errAbort != nil {
+					openlog.Error("abort transaction", openlog.WithTags(openlog.Tags{
+						"err":  errAbort.Error(),
+						"task": task,
+					}))
+				}
+				return err
+			}
+		}
+		if opts.OutboxEnable {
+			if err := outbox.InsertEvents(sessionContext, kv.Domain, kv.Project, []*model.KVDoc{curKV}, datasource.OutboxActionPut, opts.Node); err != nil {
+				abort(sessionContext, taskSession)
+				return err
 			}
-			return err
 		}
 		if err = taskSession.CommitTransaction(sessionContext); err != nil {
 			return err
@@ -271,6 +298,9 @@ func findKV(ctx context.Context, domain string, project string, opts datasource.
 			filter["key"] = bson.M{"$regex": "^" + value + "$", "$options": "$i"}
 		}
 	}
+	if len(opts.IDs) != 0 {
+		filter["id"] = bson.M{"$in": opts.IDs}
+	}
 	if opts.Value != "" {
 		filter["value"] = bson.M{"$regex": opts.Value}
 	}
@@ -279,6 +309,16 @@ func findKV(ctx context.Context, domain string, project string, opts datasource.
 			filter["labels."+k] = v
 		}
 	}
+	conditions := bson.A{}
+	if len(opts.Selector) != 0 {
+		conditions = append(conditions, selectorFilter(opts.Selector)...)
+	}
+	if opts.IsSearch() {
+		conditions = append(conditions, searchFilter(opts)...)
+	}
+	if len(conditions) != 0 {
+		filter["$and"] = conditions
+	}
 	opt := options.Find().SetSort(map[string]interface{}{
 		"update_revision": -1,
 	})
@@ -286,7 +326,11 @@ func findKV(ctx context.Context, domain string, project string, opts datasource.
 		opt = opt.SetLimit(opts.Limit)
 		opt = opt.SetSkip(opts.Offset)
 	}
-	curTotal, err := collection.CountDocuments(ctx, filter)
+	countOpt := options.Count()
+	if opts.IsSearch() {
+		countOpt.SetMaxTime(datasource.SearchMaxTime)
+	}
+	curTotal, err := collection.CountDocuments(ctx, filter, countOpt)
 	if err != nil {
 		if err.Error() == context.DeadlineExceeded.Error() {
 			openlog.Error(MsgFindKvFailed, openlog.WithTags(openlog.Tags{
@@ -294,11 +338,20 @@ func findKV(ctx context.Context, domain string, project string, opts datasource.
 			}))
 			return nil, 0, fmt.Errorf(FmtErrFindKvFailed, opts.Timeout)
 		}
-		return nil, 0, err
+		return nil, 0, searchError(err)
 	}
 	if opts.Status != "" {
 		filter["status"] = opts.Status
 	}
+	if opts.Sort != "" {
+		opt = sortedFindOptions(opts)
+		if opts.Cursor != nil {
+			filter = bson.M{"$and": bson.A{filter, cursorFilter(opts)}}
+		}
+	}
+	if opts.IsSearch() {
+		opt.SetMaxTime(datasource.SearchMaxTime)
+	}
 	cur, err := collection.Find(ctx, filter, opt)
 	if err != nil {
 		if err.Error() == context.DeadlineExceeded.Error() {
@@ -307,10 +360,99 @@ func findKV(ctx context.Context, domain string, project string, opts datasource.
 			}))
 			return nil, 0, fmt.Errorf(FmtErrFindKvFailed, opts.Timeout)
 		}
-		return nil, 0, err
+		return nil, 0, searchError(err)
 	}
 	return cur, int(curTotal), err
 }
+
+// searchError tells searches killed by the max time from other errors
+func searchError(err error) error {
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) && cmdErr.IsMaxTimeMSExpiredError() {
+		return datasource.ErrSearchTimeout
+	}
+	return err
+}
+
+// sortedFindOptions sort kvs by the field and then id, and find one more kv than limit to tell if there is a next page
+func sortedFindOptions(opts datasource.FindOptions) *options.FindOptions {
+	order := 1
+	if opts.SortDesc {
+		order = -1
+	}
+	opt := options.Find().SetSort(bson.D{{Key: opts.Sort, Value: order}, {Key: "id", Value: order}})
+	if opts.Limit > 0 {
+		opt = opt.SetLimit(opts.Limit + 1)
+	}
+	return opt
+}
+
+// cursorFilter matches kvs after the cursor in the sort order
+func cursorFilter(opts datasource.FindOptions) bson.M {
+	op := "$gt"
+	if opts.SortDesc {
+		op = "$lt"
+	}
+	var value interface{} = opts.Cursor.Num
+	if opts.Sort == datasource.SortKey {
+		value = opts.Cursor.Key
+	}
+	return bson.M{"$or": bson.A{
+		bson.M{opts.Sort: bson.M{op: value}},
+		bson.M{opts.Sort: value, "id": bson.M{op: opts.Cursor.ID}},
+	}}
+}
+
+// selectorFilter convert label selector to mongo query,
+// $ne and $nin also match documents without the label, the same as the selector
+func selectorFilter(s selector.Selector) bson.A {
+	conditions := make(bson.A, 0, len(s))
+	for _, r := range s {
+		var c interface{}
+		switch r.Operator {
+		case selector.OpEquals:
+			c = r.Values[0]
+		case selector.OpNotEquals:
+			c = bson.M{"$ne": r.Values[0]}
+		case selector.OpIn:
+			c = bson.M{"$in": r.Values}
+		case selector.OpNotIn:
+			c = bson.M{"$nin": r.Values}
+		case selector.OpExists:
+			c = bson.M{"$exists": true}
+		case selector.OpNotExists:
+			c = bson.M{"$exists": false}
+		}
+		conditions = append(conditions, bson.M{"labels." + r.Key: c})
+	}
+	return conditions
+}
+
+// searchFilter convert search options to mongo query, regexes are matched by mongo $regex
+func searchFilter(opts datasource.FindOptions) bson.A {
+	regexOptions := "i"
+	if opts.CaseSensitive {
+		regexOptions = ""
+	}
+	conditions := bson.A{}
+	if opts.KeyRegex != "" {
+		conditions = append(conditions, bson.M{"key": bson.M{"$regex": opts.KeyRegex, "$options": regexOptions}})
+	}
+	if opts.ValueRegex != "" {
+		conditions = append(conditions, bson.M{"value": bson.M{"$regex": opts.ValueRegex, "$options": regexOptions}})
+	}
+	if opts.ValueType != "" {
+		conditions = append(conditions, bson.M{"value_type": opts.ValueType})
+	}
+	if opts.UpdatedAfter != 0 {
+		conditions = append(conditions, bson.M{"update_time": bson.M{"$gte": opts.UpdatedAfter}})
+	}
+	if opts.UpdatedBefore != 0 {
+		conditions = append(conditions, bson.M{"update_time": bson.M{"$lte": opts.UpdatedBefore}})
+	}
+	return conditions
+}
+
 func findOneKey(ctx context.Context, filter bson.M) ([]*model.KVDoc, error) {
 	collection := dmongo.GetClient().GetDB().Collection(mmodel.CollectionKV)
 	sr := collection.FindOne(ctx, filter)
@@ -366,11 +508,19 @@ func (s *Dao) Exist(ctx context.Context, key, project, domain string, options ..
 // domain=tenant
 func (s *Dao) FindOneAndDelete(ctx context.Context, kvID, project, domain string, options ...datasource.WriteOption) (*model.KVDoc, error) {
 	opts := datasource.NewWriteOptions(options...)
-	if opts.SyncEnable {
+	var kv *model.KVDoc
+	var err error
+	if opts.InTxn() {
 		// if syncEnable is ture, will delete kv, create task and create tombstone
-		return txnFindOneAndDelete(ctx, kvID, project, domain)
+		kv, err = txnFindOneAndDelete(ctx, kvID, project, domain, opts)
+	} else {
+		kv, err = findOneAndDelete(ctx, kvID, project, domain)
+	}
+	if err != nil {
+		return nil, err
 	}
-	return findOneAndDelete(ctx, kvID, project, domain)
+	label.Count(ctx, datasource.CountLabels(domain, project, []*model.KVDoc{kv}, -1))
+	return kv, nil
 }
 
 func findOneAndDelete(ctx context.Context, kvID, project, domain string) (*model.KVDoc, error) {
@@ -392,7 +542,7 @@ func findOneAndDelete(ctx context.Context, kvID, project, domain string) (*model
 }
 
 // txnFindOneAndDelete is to start transaction when delete KV, will create task and tombstone in a transaction operation
-func txnFindOneAndDelete(ctx context.Context, kvID, project, domain string) (*model.KVDoc, error) {
+func txnFindOneAndDelete(ctx context.Context, kvID, project, domain string, opts datasource.WriteOptions) (*model.KVDoc, error) {
 	curKV := &model.KVDoc{}
 	taskSession, err := dmongo.GetClient().GetDB().Client().StartSession()
 	if err != nil {
@@ -433,50 +583,58 @@ func txnFindOneAndDelete(ctx context.Context, kvID, project, domain string) (*mo
 			}
 			return err
 		}
-		task, err := sync.NewTask(domain, project, sync.DeleteAction, datasource.ConfigResource, curKV)
-		if err != nil {
-			openlog.Error("fail to create task" + err.Error())
-			errAbort := taskSession.AbortTransaction(sessionContext)
-			if errAbort != nil {
-				openlog.Error("abort transaction", openlog.WithTags(openlog.Tags{
-					"err": errAbort.Error(),
-				}))
-				return errAbort
+		if opts.SyncEnable {
+			task, err := sync.NewTask(domain, project, sync.DeleteAction, datasource.ConfigResource, curKV)
+			if err != nil {
+				openlog.Error("fail to create task" + err.Error())
+				errAbort := taskSession.AbortTransaction(sessionContext)
+				if errAbort != nil {
+					openlog.Error("abort transaction", openlog.WithTags(openlog.Tags{
+						"err": errAbort.Error(),
+					}))
+					return errAbort
+				}
+				return err
 			}
-			return err
-		}
-		collection = dmongo.GetClient().GetDB().Collection(mmodel.CollectionTask)
-		_, err = collection.InsertOne(sessionContext, task)
-		if err != nil {
-			openlog.Error("create task error", openlog.WithTags(openlog.Tags{
-				"err":  err.Error(),
-				"task": task,
-			}))
-			errAbort := taskSession.AbortTransaction(sessionContext)
-			if errAbort != nil {
-				openlog.Error("abort transaction", openlog.WithTags(openlog.Tags{
-					"err":  errAbort.Error(),
+			collection = dmongo.GetClient().GetDB().Collection(mmodel.CollectionTask)
+			_, err = collection.InsertOne(sessionContext, task)
+			if err != nil {
+				openlog.Error("create task error", openlog.WithTags(openlog.Tags{
+					"err":  err.Error(),
 					"task": task,
 				}))
+				errAbort := taskSession.AbortTransaction(sessionContext)
+				if errAbort != nil {
+					openlog.Error("abort transaction", openlog.WithTags(openlog.Tags{
+						"err":  errAbort.Error(),
+						"task": task,
+					}))
+				}
+				return err
 			}
-			return err
-		}
-		tombstone := sync.NewTombstone(domain, project, datasource.ConfigResource, datasource.TombstoneID(curKV))
-		collection = dmongo.GetClient().GetDB().Collection(mmodel.CollectionTombstone)
-		_, err = collection.InsertOne(sessionContext, tombstone)
-		if err != nil {
-			openlog.Error("create tombstone error", openlog.WithTags(openlog.Tags{
-				"err":       err.Error(),
-				"tombstone": tombstone,
-			}))
-			errAbort := taskSession.AbortTransaction(sessionContext)
-			if errAbort != nil {
-				openlog.Error("abort transaction", openlog.WithTags(openlog.Tags{
-					"err":       errAbort.Error(),
+			tombstone := sync.NewTombstone(domain, project, datasource.ConfigResource, datasource.TombstoneID(curKV))
+			collection = dmongo.GetClient().GetDB().Collection(mmodel.CollectionTombstone)
+			_, err = collection.InsertOne(sessionContext, tombstone)
+			if err != nil {
+				openlog.Error("create tombstone error", openlog.WithTags(openlog.Tags{
+					"err":       err.Error(),
 					"tombstone": tombstone,
 				}))
+				errAbort := taskSession.AbortTransaction(sessionContext)
+				if errAbort != nil {
+					openlog.Error("abort transaction", openlog.WithTags(openlog.Tags{
+						"err":       errAbort.Error(),
+						"tombstone": tombstone,
+					}))
+				}
+				return err
+			}
+		}
+		if opts.OutboxEnable {
+			if err := outbox.InsertEvents(sessionContext, domain, project, []*model.KVDoc{curKV}, datasource.OutboxActionDelete, opts.Node); err != nil {
+				abort(sessionContext, taskSession)
+				return err
 			}
-			return err
 		}
 		if err = taskSession.CommitTransaction(sessionContext); err != nil {
 			return err
@@ -492,11 +650,20 @@ func txnFindOneAndDelete(ctx context.Context, kvID, project, domain string) (*mo
 // FindManyAndDelete deletes multiple kvs and return the deleted kv list as these appeared before deletion
 func (s *Dao) FindManyAndDelete(ctx context.Context, kvIDs []string, project, domain string, options ...datasource.WriteOption) ([]*model.KVDoc, int64, error) {
 	opts := datasource.NewWriteOptions(options...)
-	if opts.SyncEnable {
+	var kvs []*model.KVDoc
+	var n int64
+	var err error
+	if opts.InTxn() {
 		// if sync enable is true, will delete kvs, create tasks and tombstones
-		return txnFindManyAndDelete(ctx, kvIDs, project, domain)
+		kvs, n, err = txnFindManyAndDelete(ctx, kvIDs, project, domain, opts)
+	} else {
+		kvs, n, err = findManyAndDelete(ctx, kvIDs, project, domain)
+	}
+	if err != nil {
+		return nil, 0, err
 	}
-	return findManyAndDelete(ctx, kvIDs, project, domain)
+	label.Count(ctx, datasource.CountLabels(domain, project, kvs, -1))
+	return kvs, n, nil
 }
 
 func findManyAndDelete(ctx context.Context, kvIDs []string, project, domain string) ([]*model.KVDoc, int64, error) {
@@ -521,7 +688,7 @@ func findManyAndDelete(ctx context.Context, kvIDs []string, project, domain stri
 }
 
 // txnFindManyAndDelete is to start transaction when delete KVs, will create tasks and tombstones in a transaction operation
-func txnFindManyAndDelete(ctx context.Context, kvIDs []string, project, domain string) ([]*model.KVDoc, int64, error) {
+func txnFindManyAndDelete(ctx context.Context, kvIDs []string, project, domain string, opts datasource.WriteOptions) ([]*model.KVDoc, int64, error) {
 	filter := bson.D{
 		{Key: "id", Value: bson.M{"$in": kvIDs}},
 		{Key: "project", Value: project},
@@ -564,42 +731,50 @@ func txnFindManyAndDelete(ctx context.Context, kvIDs []string, project, domain s
 			return err
 		}
 		deletedCount = dr.DeletedCount
-		tasksDoc := make([]interface{}, deletedCount)
-		tombstonesDoc := make([]interface{}, deletedCount)
-		for i := 0; i < int(deletedCount); i++ {
-			kv := kvs[i]
-			task, _ := sync.NewTask(domain, project, sync.DeleteAction, datasource.ConfigResource, kv)
-			tombstone := sync.NewTombstone(domain, project, datasource.ConfigResource, datasource.TombstoneID(kv))
-			tasksDoc[i] = task
-			tombstonesDoc[i] = tombstone
-		}
-		collection = dmongo.GetClient().GetDB().Collection(mmodel.CollectionTask)
-		_, err = collection.InsertMany(sessionContext, tasksDoc)
-		if err != nil {
-			openlog.Error("create tasks error", openlog.WithTags(openlog.Tags{
-				"err": err.Error(),
-			}))
-			errAbort := taskSession.AbortTransaction(sessionContext)
-			if errAbort != nil {
-				openlog.Error("abort transaction", openlog.WithTags(openlog.Tags{
-					"err": errAbort.Error(),
+		if opts.SyncEnable {
+			tasksDoc := make([]interface{}, deletedCount)
+			tombstonesDoc := make([]interface{}, deletedCount)
+			for i := 0; i < int(deletedCount); i++ {
+				kv := kvs[i]
+				task, _ := sync.NewTask(domain, project, sync.DeleteAction, datasource.ConfigResource, kv)
+				tombstone := sync.NewTombstone(domain, project, datasource.ConfigResource, datasource.TombstoneID(kv))
+				tasksDoc[i] = task
+				tombstonesDoc[i] = tombstone
+			}
+			collection = dmongo.GetClient().GetDB().Collection(mmodel.CollectionTask)
+			_, err = collection.InsertMany(sessionContext, tasksDoc)
+			if err != nil {
+				openlog.Error("create tasks error", openlog.WithTags(openlog.Tags{
+					"err": err.Error(),
 				}))
+				errAbort := taskSession.AbortTransaction(sessionContext)
+				if errAbort != nil {
+					openlog.Error("abort transaction", openlog.WithTags(openlog.Tags{
+						"err": errAbort.Error(),
+					}))
+				}
+				return err
 			}
-			return err
-		}
-		collection = dmongo.GetClient().GetDB().Collection(mmodel.CollectionTombstone)
-		_, err = collection.InsertMany(sessionContext, tombstonesDoc)
-		if err != nil {
-			openlog.Error("create tombstone error", openlog.WithTags(openlog.Tags{
-				"err": err.Error(),
-			}))
-			errAbort := taskSession.AbortTransaction(sessionContext)
-			if errAbort != nil {
-				openlog.Error("abort transaction", openlog.WithTags(openlog.Tags{
-					"err": errAbort.Error(),
+			collection = dmongo.GetClient().GetDB().Collection(mmodel.CollectionTombstone)
+			_, err = collection.InsertMany(sessionContext, tombstonesDoc)
+			if err != nil {
+				openlog.Error("create tombstone error", openlog.WithTags(openlog.Tags{
+					"err": err.Error(),
 				}))
+				errAbort := taskSession.AbortTransaction(sessionContext)
+				if errAbort != nil {
+					openlog.Error("abort transaction", openlog.WithTags(openlog.Tags{
+						"err": errAbort.Error(),
+					}))
+				}
+				return err
+			}
+		}
+		if opts.OutboxEnable {
+			if err := outbox.InsertEvents(sessionContext, domain, project, kvs[:deletedCount], datasource.OutboxActionDelete, opts.Node); err != nil {
+				abort(sessionContext, taskSession)
+				return err
 			}
-			return err
 		}
 		if err = taskSession.CommitTransaction(sessionContext); err != nil {
 			return err
@@ -612,6 +787,266 @@ func txnFindManyAndDelete(ctx context.Context, kvIDs []string, project, domain s
 	return kvs, deletedCount, nil
 }
 
+// Move replaces kv old by kv of another label set in a transaction,
+// the revisions of old are carried over to kv in the same transaction
+func (s *Dao) Move(ctx context.Context, old, kv *model.KVDoc, override bool, options ...datasource.WriteOption) error {
+	opts := datasource.NewWriteOptions(options...)
+	session, err := dmongo.GetClient().GetDB().Client().StartSession()
+	if err != nil {
+		openlog.Error("fail to start session" + err.Error())
+		return err
+	}
+	if err = session.StartTransaction(); err != nil {
+		openlog.Error("fail to start transaction" + err.Error())
+		return err
+	}
+	defer session.EndSession(ctx)
+	var overwritten bool
+	if err = mongo.WithSession(ctx, session, func(sessionContext mongo.SessionContext) error {
+		collection := dmongo.GetClient().GetDB().Collection(mmodel.CollectionKV)
+		if override {
+			dr, err := collection.DeleteOne(sessionContext, bson.M{"id": kv.ID, "project": kv.Project, "domain": kv.Domain})
+			if err != nil {
+				abort(sessionContext, session)
+				return err
+			}
+			overwritten = dr.DeletedCount != 0
+		}
+		if _, err := collection.InsertOne(sessionContext, kv); err != nil {
+			abort(sessionContext, session)
+			if dmongo.IsDuplicateKey(err) {
+				return datasource.ErrKVAlreadyExists
+			}
+			return err
+		}
+		// old is moved only if it is not changed since it was read
+		dr, err := collection.DeleteOne(sessionContext, bson.M{"id": old.ID, "project": old.Project, "domain": old.Domain,
+			"update_revision": old.UpdateRevision})
+		if err != nil {
+			abort(sessionContext, session)
+			return err
+		}
+		if dr.DeletedCount == 0 {
+			abort(sessionContext, session)
+			n, err := collection.CountDocuments(ctx, bson.M{"id": old.ID, "project": old.Project, "domain": old.Domain})
+			if err != nil {
+				return err
+			}
+			if n == 0 {
+				return datasource.ErrKeyNotExists
+			}
+			return datasource.ErrKVConflict
+		}
+		if err := carryHistories(sessionContext, old, kv); err != nil {
+			abort(sessionContext, session)
+			return err
+		}
+		if opts.SyncEnable {
+			deleteTask, err := sync.NewTask(old.Domain, old.Project, sync.DeleteAction, datasource.ConfigResource, old)
+			if err != nil {
+				abort(sessionContext, session)
+				return err
+			}
+			createTask, err := sync.NewTask(kv.Domain, kv.Project, sync.CreateAction, datasource.ConfigResource, kv)
+			if err != nil {
+				abort(sessionContext, session)
+				return err
+			}
+			_, err = dmongo.GetClient().GetDB().Collection(mmodel.CollectionTask).InsertMany(sessionContext,
+				[]interface{}{deleteTask, createTask})
+			if err != nil {
+				abort(sessionContext, session)
+				return err
+			}
+			tombstone := sync.NewTombstone(old.Domain, old.Project, datasource.ConfigResource, datasource.TombstoneID(old))
+			_, err = dmongo.GetClient().GetDB().Collection(mmodel.CollectionTombstone).InsertOne(sessionContext, tombstone)
+			if err != nil {
+				abort(sessionContext, session)
+				return err
+			}
+		}
+		if opts.OutboxEnable {
+			if err := outbox.InsertEvents(sessionContext, old.Domain, old.Project, []*model.KVDoc{old}, datasource.OutboxActionDelete, opts.Node); err != nil {
+				abort(sessionContext, session)
+				return err
+			}
+			if err := outbox.InsertEvents(sessionContext, kv.Domain, kv.Project, []*model.KVDoc{kv}, datasource.OutboxActionPut, opts.Node); err != nil {
+				abort(sessionContext, session)
+				return err
+			}
+		}
+		return session.CommitTransaction(sessionContext)
+	}); err != nil {
+		openlog.Error("move kv error: " + err.Error())
+		return err
+	}
+	label.Count(ctx, datasource.CountLabels(old.Domain, old.Project, []*model.KVDoc{old}, -1))
+	if !overwritten {
+		label.Count(ctx, datasource.CountLabels(kv.Domain, kv.Project, []*model.KVDoc{kv}, 1))
+	}
+	return nil
+}
+
+// carryHistories copies the revisions of old to kv in the session
+func carryHistories(ctx mongo.SessionContext, old, kv *model.KVDoc) error {
+	collection := dmongo.GetClient().GetDB().Collection(mmodel.CollectionKVRevision)
+	cur, err := collection.Find(ctx, bson.M{"id": old.ID, "project": old.Project, "domain": old.Domain})
+	if err != nil {
+		return err
+	}
+	var histories []*model.KVDoc
+	if err := cur.All(ctx, &histories); err != nil {
+		return err
+	}
+	if len(histories) == 0 {
+		return nil
+	}
+	docs := make([]interface{}, 0, len(histories))
+	for _, h := range histories {
+		h.ID, h.Domain, h.Project = kv.ID, kv.Domain, kv.Project
+		docs = append(docs, h)
+	}
+	_, err = collection.InsertMany(ctx, docs)
+	return err
+}
+
+// Batch writes kvs in one transaction
+func (s *Dao) Batch(ctx context.Context, txn *datasource.KVTxn, options ...datasource.WriteOption) error {
+	if len(txn.Creates) == 0 && len(txn.Updates) == 0 && len(txn.Deletes) == 0 {
+		return nil
+	}
+	opts := datasource.NewWriteOptions(options...)
+	session, err := dmongo.GetClient().GetDB().Client().StartSession()
+	if err != nil {
+		openlog.Error("fail to start session" + err.Error())
+		return err
+	}
+	if err = session.StartTransaction(); err != nil {
+		openlog.Error("fail to start transaction" + err.Error())
+		return err
+	}
+	defer session.EndSession(ctx)
+	if err = mongo.WithSession(ctx, session, func(sessionContext mongo.SessionContext) error {
+		if err := batchWrite(sessionContext, txn, opts); err != nil {
+			abort(sessionContext, session)
+			return err
+		}
+		return session.CommitTransaction(sessionContext)
+	}); err != nil {
+		openlog.Error("batch write kvs error: " + err.Error())
+		return err
+	}
+	if len(txn.Creates) != 0 {
+		label.Count(ctx, datasource.CountLabels(txn.Creates[0].Domain, txn.Creates[0].Project, txn.Creates, 1))
+	}
+	if len(txn.Deletes) != 0 {
+		label.Count(ctx, datasource.CountLabels(txn.Deletes[0].Domain, txn.Deletes[0].Project, txn.Deletes, -1))
+	}
+	return nil
+}
+
+func batchWrite(sessionContext mongo.SessionContext, txn *datasource.KVTxn, opts datasource.WriteOptions) error {
+	collection := dmongo.GetClient().GetDB().Collection(mmodel.CollectionKV)
+	var tasks, tombstones []interface{}
+	addTask := func(kv *model.KVDoc, action string) error {
+		if !opts.SyncEnable {
+			return nil
+		}
+		task, err := sync.NewTask(kv.Domain, kv.Project, action, datasource.ConfigResource, kv)
+		if err != nil {
+			return err
+		}
+		tasks = append(tasks, task)
+		if action == sync.DeleteAction {
+			tombstones = append(tombstones, sync.NewTombstone(kv.Domain, kv.Project, datasource.ConfigResource, datasource.TombstoneID(kv)))
+		}
+		return nil
+	}
+	filter := func(kv *model.KVDoc) bson.M {
+		f := bson.M{"id": kv.ID, "project": kv.Project, "domain": kv.Domain}
+		if rev, ok := txn.Revisions[kv.ID]; ok {
+			f["update_revision"] = rev
+		}
+		return f
+	}
+	for _, kv := range txn.Creates {
+		if _, err := collection.InsertOne(sessionContext, kv); err != nil {
+			if dmongo.IsDuplicateKey(err) {
+				return datasource.ErrKVConflict
+			}
+			return err
+		}
+		if err := addTask(kv, sync.CreateAction); err != nil {
+			return err
+		}
+	}
+	for _, kv := range txn.Updates {
+		ur, err := collection.UpdateOne(sessionContext, filter(kv), bson.D{
+			{Key: "$set", Value: bson.D{
+				{Key: "value", Value: kv.Value},
+				{Key: "status", Value: kv.Status},
+				{Key: "priority", Value: kv.Priority},
+				{Key: "checker", Value: kv.Checker},
+				{Key: "update_time", Value: kv.UpdateTime},
+				{Key: "update_revision", Value: kv.UpdateRevision},
+			}},
+		})
+		if err != nil {
+			return err
+		}
+		if ur.MatchedCount == 0 {
+			return datasource.ErrKVConflict
+		}
+		if err := addTask(kv, sync.UpdateAction); err != nil {
+			return err
+		}
+	}
+	for _, kv := range txn.Deletes {
+		dr, err := collection.DeleteOne(sessionContext, filter(kv))
+		if err != nil {
+			return err
+		}
+		if dr.DeletedCount == 0 {
+			return datasource.ErrKVConflict
+		}
+		if err := addTask(kv, sync.DeleteAction); err != nil {
+			return err
+		}
+	}
+	if len(tasks) != 0 {
+		if _, err := dmongo.GetClient().GetDB().Collection(mmodel.CollectionTask).InsertMany(sessionContext, tasks); err != nil {
+			return err
+		}
+	}
+	if len(tombstones) != 0 {
+		if _, err := dmongo.GetClient().GetDB().Collection(mmodel.CollectionTombstone).InsertMany(sessionContext, tombstones); err != nil {
+			return err
+		}
+	}
+	if !opts.OutboxEnable {
+		return nil
+	}
+	puts := append(append([]*model.KVDoc{}, txn.Creates...), txn.Updates...)
+	if len(puts) != 0 {
+		if err := outbox.InsertEvents(sessionContext, puts[0].Domain, puts[0].Project, puts, datasource.OutboxActionPut, opts.Node); err != nil {
+			return err
+		}
+	}
+	if len(txn.Deletes) != 0 {
+		return outbox.InsertEvents(sessionContext, txn.Deletes[0].Domain, txn.Deletes[0].Project, txn.Deletes, datasource.OutboxActionDelete, opts.Node)
+	}
+	return nil
+}
+
+// abort aborts the transaction, and only logs the error because the caller returns its own error
+func abort(sessionContext mongo.SessionContext, session mongo.Session) {
+	if err := session.AbortTransaction(sessionContext); err != nil {
+		openlog.Error("abort transaction", openlog.WithTags(openlog.Tags{
+			"err": err.Error(),
+		}))
+	}
+}
+
 func findKeys(ctx context.Context, filter interface{}, withoutLabel bool) ([]*model.KVDoc, error) {
 	collection := dmongo.GetClient().GetDB().Collection(mmodel.CollectionKV)
 	cur, err := collection.Find(ctx, filter)
@@ -735,6 +1170,13 @@ func (s *Dao) List(ctx context.Context, project, domain string, options ...datas
 		datasource.ClearPart(curKV)
 		result.Data = append(result.Data, curKV)
 	}
+	if err := cur.Err(); err != nil {
+		return nil, searchError(err)
+	}
 	result.Total = total
+	if opts.Sort != "" && opts.Limit > 0 && int64(len(result.Data)) > opts.Limit {
+		result.Data = result.Data[:opts.Limit]
+		result.NextCursor = datasource.NewCursor(result.Data[opts.Limit-1], opts.Sort, opts.SortDesc).Encode()
+	}
 	return result, nil
 }
@@ -0,0 +1,169 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package label
+
+import (
+	"context"
+
+	dmongo "github.com/go-chassis/cari/db/mongo"
+	"github.com/go-chassis/openlog"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/datasource"
+	mmodel "github.com/apache/servicecomb-kie/server/datasource/mongo/model"
+)
+
+// Dao operate the label catalog in mongodb
+type Dao struct {
+}
+
+// Count adds the counts of label sets, label sets are created on first use,
+// it is called after kvs are written, so errors are only logged,
+// a count drifted by such errors is recounted from kvs once it goes negative or the label set is deleted
+func Count(ctx context.Context, docs []*model.LabelDoc) {
+	collection := dmongo.GetClient().GetDB().Collection(mmodel.CollectionLabel)
+	for _, doc := range docs {
+		sr := collection.FindOneAndUpdate(ctx, bson.M{"id": doc.ID, "domain": doc.Domain, "project": doc.Project},
+			bson.D{
+				{Key: "$inc", Value: bson.D{{Key: "count", Value: doc.Count}}},
+				{Key: "$setOnInsert", Value: bson.D{
+					{Key: "labels", Value: doc.Labels},
+					{Key: "format", Value: doc.Format},
+				}},
+			}, options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After))
+		cur := &model.LabelDoc{}
+		err := sr.Err()
+		if err == nil {
+			err = sr.Decode(cur)
+		}
+		if err == nil && cur.Count < 0 {
+			openlog.Warn("label set count is negative, recount it", openlog.WithTags(openlog.Tags{
+				"labels": doc.Format,
+			}))
+			_, err = recount(ctx, doc)
+		}
+		if err != nil {
+			openlog.Error("count label set failed", openlog.WithTags(openlog.Tags{
+				"err":    err.Error(),
+				"labels": doc.Format,
+			}))
+		}
+	}
+}
+
+// recount sets the count of the label set to the number of kvs having it, the label set is created if missing
+func recount(ctx context.Context, doc *model.LabelDoc) (int64, error) {
+	n, err := dmongo.GetClient().GetDB().Collection(mmodel.CollectionKV).CountDocuments(ctx,
+		bson.M{"domain": doc.Domain, "project": doc.Project, "label_format": doc.Format})
+	if err != nil {
+		return 0, err
+	}
+	_, err = dmongo.GetClient().GetDB().Collection(mmodel.CollectionLabel).UpdateOne(ctx,
+		bson.M{"id": doc.ID, "domain": doc.Domain, "project": doc.Project},
+		bson.D{
+			{Key: "$set", Value: bson.D{{Key: "count", Value: n}}},
+			{Key: "$setOnInsert", Value: bson.D{
+				{Key: "labels", Value: doc.Labels},
+				{Key: "format", Value: doc.Format},
+			}},
+		}, options.Update().SetUpsert(true))
+	return n, err
+}
+
+func (d *Dao) List(ctx context.Context, project, domain string) ([]*model.LabelDoc, error) {
+	collection := dmongo.GetClient().GetDB().Collection(mmodel.CollectionLabel)
+	cur, err := collection.Find(ctx, bson.M{"domain": domain, "project": project})
+	if err != nil {
+		openlog.Error("list label sets error: " + err.Error())
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	docs := make([]*model.LabelDoc, 0)
+	for cur.Next(ctx) {
+		doc := &model.LabelDoc{}
+		if err := cur.Decode(doc); err != nil {
+			openlog.Error("decode label set error: " + err.Error())
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+func (d *Dao) UpdateAlias(ctx context.Context, id, project, domain, alias string) (*model.LabelDoc, error) {
+	collection := dmongo.GetClient().GetDB().Collection(mmodel.CollectionLabel)
+	sr := collection.FindOneAndUpdate(ctx, bson.M{"id": id, "domain": domain, "project": project},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "alias", Value: alias}}}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After))
+	if sr.Err() != nil {
+		if sr.Err() == mongo.ErrNoDocuments {
+			return nil, datasource.ErrLabelNotExists
+		}
+		openlog.Error("update label alias error: " + sr.Err().Error())
+		return nil, sr.Err()
+	}
+	doc := &model.LabelDoc{}
+	if err := sr.Decode(doc); err != nil {
+		openlog.Error("decode label set error: " + err.Error())
+		return nil, err
+	}
+	return doc, nil
+}
+
+// DeleteOrphan recounts the kvs of the label set instead of trusting its count,
+// a kv created concurrently recreates the label set once it is counted
+func (d *Dao) DeleteOrphan(ctx context.Context, id, project, domain string) error {
+	collection := dmongo.GetClient().GetDB().Collection(mmodel.CollectionLabel)
+	filter := bson.M{"id": id, "domain": domain, "project": project}
+	doc := &model.LabelDoc{}
+	if err := collection.FindOne(ctx, filter).Decode(doc); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return datasource.ErrLabelNotExists
+		}
+		openlog.Error("find label set error: " + err.Error())
+		return err
+	}
+	doc.Domain, doc.Project = domain, project
+	n, err := dmongo.GetClient().GetDB().Collection(mmodel.CollectionKV).CountDocuments(ctx,
+		bson.M{"domain": domain, "project": project, "label_format": doc.Format})
+	if err != nil {
+		openlog.Error("count kvs of label set error: " + err.Error())
+		return err
+	}
+	if n != 0 {
+		if n != doc.Count {
+			if _, err := recount(ctx, doc); err != nil {
+				openlog.Error("recount label set error: " + err.Error())
+			}
+		}
+		return datasource.ErrLabelInUse
+	}
+	// the count is unchanged unless a kv of the label set is counted concurrently
+	dr, err := collection.DeleteOne(ctx, bson.M{"id": id, "domain": domain, "project": project, "count": doc.Count})
+	if err != nil {
+		openlog.Error("delete label set error: " + err.Error())
+		return err
+	}
+	if dr.DeletedCount == 0 {
+		return datasource.ErrLabelInUse
+	}
+	return nil
+}
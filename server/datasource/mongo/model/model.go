@@ -25,4 +25,7 @@ const (
 	CollectionView          = "view"
 	CollectionTask          = "task"
 	CollectionTombstone     = "tombstone"
+	CollectionOutbox        = "outbox"
+	CollectionSchema        = "schema"
+	CollectionLabel         = "label"
 )
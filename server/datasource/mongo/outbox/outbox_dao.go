@@ -0,0 +1,97 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package outbox
+
+import (
+	"context"
+
+	dmongo "github.com/go-chassis/cari/db/mongo"
+	"github.com/go-chassis/openlog"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/datasource"
+	mmodel "github.com/apache/servicecomb-kie/server/datasource/mongo/model"
+)
+
+// Dao operate outbox events in mongodb
+type Dao struct {
+}
+
+// InsertEvents write outbox events of the kv mutations,
+// ctx should be a session context, so that events are written in the same transaction as the mutations
+func InsertEvents(ctx context.Context, domain, project string, kvs []*model.KVDoc, action, node string) error {
+	if len(kvs) == 0 {
+		return nil
+	}
+	docs := make([]interface{}, 0, len(kvs))
+	for _, kv := range kvs {
+		event, err := datasource.NewOutboxEvent(domain, project, kv, action, node)
+		if err != nil {
+			openlog.Error("fail to create outbox event: " + err.Error())
+			return err
+		}
+		docs = append(docs, event)
+	}
+	_, err := dmongo.GetClient().GetDB().Collection(mmodel.CollectionOutbox).InsertMany(ctx, docs)
+	if err != nil {
+		openlog.Error("create outbox events error: " + err.Error())
+	}
+	return err
+}
+
+func (d *Dao) List(ctx context.Context, after *model.OutboxEvent, limit int64) ([]*model.OutboxEvent, error) {
+	collection := dmongo.GetClient().GetDB().Collection(mmodel.CollectionOutbox)
+	filter := bson.M{}
+	if after != nil {
+		filter = bson.M{"$or": bson.A{
+			bson.M{"timestamp": bson.M{"$gt": after.Timestamp}},
+			bson.M{"timestamp": after.Timestamp, "id": bson.M{"$gt": after.ID}},
+		}}
+	}
+	sort := bson.D{{Key: "timestamp", Value: 1}, {Key: "id", Value: 1}}
+	cur, err := collection.Find(ctx, filter, options.Find().SetSort(sort).SetLimit(limit))
+	if err != nil {
+		openlog.Error("list outbox events failed: " + err.Error())
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	events := make([]*model.OutboxEvent, 0)
+	for cur.Next(ctx) {
+		event := &model.OutboxEvent{}
+		if err := cur.Decode(event); err != nil {
+			openlog.Error("decode outbox event failed: " + err.Error())
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, cur.Err()
+}
+
+func (d *Dao) Done(ctx context.Context, events []*model.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	collection := dmongo.GetClient().GetDB().Collection(mmodel.CollectionOutbox)
+	_, err := collection.DeleteMany(ctx, bson.M{"id": bson.M{"$in": ids}})
+	return err
+}
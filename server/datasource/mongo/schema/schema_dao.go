@@ -0,0 +1,115 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package schema
+
+import (
+	"context"
+
+	dmongo "github.com/go-chassis/cari/db/mongo"
+	"github.com/go-chassis/openlog"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/datasource"
+	mmodel "github.com/apache/servicecomb-kie/server/datasource/mongo/model"
+)
+
+// Dao operate json schemas of keys in mongodb
+type Dao struct {
+}
+
+func (d *Dao) Create(ctx context.Context, schema *model.SchemaDoc) (*model.SchemaDoc, error) {
+	collection := dmongo.GetClient().GetDB().Collection(mmodel.CollectionSchema)
+	_, err := collection.InsertOne(ctx, schema)
+	if err != nil {
+		if dmongo.IsDuplicateKey(err) {
+			return nil, datasource.ErrSchemaAlreadyExists
+		}
+		openlog.Error("create schema error: " + err.Error())
+		return nil, err
+	}
+	return schema, nil
+}
+
+func (d *Dao) Update(ctx context.Context, schema *model.SchemaDoc) error {
+	collection := dmongo.GetClient().GetDB().Collection(mmodel.CollectionSchema)
+	ur, err := collection.UpdateOne(ctx, bson.M{"id": schema.ID, "domain": schema.Domain, "project": schema.Project},
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: "schema", Value: schema.Schema},
+			{Key: "update_time", Value: schema.UpdateTime},
+		}}})
+	if err != nil {
+		openlog.Error("update schema error: " + err.Error())
+		return err
+	}
+	if ur.MatchedCount == 0 {
+		return datasource.ErrSchemaNotExists
+	}
+	return nil
+}
+
+func (d *Dao) Get(ctx context.Context, id, project, domain string) (*model.SchemaDoc, error) {
+	collection := dmongo.GetClient().GetDB().Collection(mmodel.CollectionSchema)
+	sr := collection.FindOne(ctx, bson.M{"id": id, "domain": domain, "project": project})
+	if sr.Err() != nil {
+		if sr.Err() == mongo.ErrNoDocuments {
+			return nil, datasource.ErrSchemaNotExists
+		}
+		openlog.Error(sr.Err().Error())
+		return nil, sr.Err()
+	}
+	schema := &model.SchemaDoc{}
+	if err := sr.Decode(schema); err != nil {
+		openlog.Error("decode schema error: " + err.Error())
+		return nil, err
+	}
+	return schema, nil
+}
+
+func (d *Dao) List(ctx context.Context, project, domain string) ([]*model.SchemaDoc, error) {
+	collection := dmongo.GetClient().GetDB().Collection(mmodel.CollectionSchema)
+	cur, err := collection.Find(ctx, bson.M{"domain": domain, "project": project})
+	if err != nil {
+		openlog.Error(err.Error())
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	schemas := make([]*model.SchemaDoc, 0)
+	for cur.Next(ctx) {
+		schema := &model.SchemaDoc{}
+		if err := cur.Decode(schema); err != nil {
+			openlog.Error("decode schema error: " + err.Error())
+			return nil, err
+		}
+		schemas = append(schemas, schema)
+	}
+	return schemas, cur.Err()
+}
+
+func (d *Dao) Delete(ctx context.Context, id, project, domain string) error {
+	collection := dmongo.GetClient().GetDB().Collection(mmodel.CollectionSchema)
+	dr, err := collection.DeleteOne(ctx, bson.M{"id": id, "domain": domain, "project": project})
+	if err != nil {
+		openlog.Error("delete schema error: " + err.Error())
+		return err
+	}
+	if dr.DeletedCount == 0 {
+		return datasource.ErrSchemaNotExists
+	}
+	return nil
+}
@@ -19,11 +19,32 @@ package datasource
 
 import (
 	"time"
+
+	"github.com/apache/servicecomb-kie/pkg/selector"
 )
 
 const DefaultTimeout = 60 * time.Second
 
+// search limits, regexes are matched by backtracking engines like PCRE of mongodb,
+// so that they are limited in length and matching time
+const (
+	MaxSearchRegexLength = 128
+	SearchMaxTime        = 5 * time.Second
+)
+
 type Config struct {
+	// RevisionBlock is the number of revisions reserved from the domain counter at once
+	RevisionBlock int64
+}
+
+// InitOption is option to init the datasource
+type InitOption func(*Config)
+
+// WithRevisionBlock reserves n revisions at once, so that writes share one round trip to the counter
+func WithRevisionBlock(n int64) InitOption {
+	return func(c *Config) {
+		c.RevisionBlock = n
+	}
 }
 
 // NewDefaultFindOpts return default options
@@ -54,6 +75,15 @@ func NewWriteOptions(option ...WriteOption) WriteOptions {
 // WriteOptions is option for create ,update and delete kv
 type WriteOptions struct {
 	SyncEnable bool
+	// OutboxEnable writes a kv change event to outbox in the same transaction,
+	// Node is the kie node which is responsible for delivering the event
+	OutboxEnable bool
+	Node         string
+}
+
+// InTxn return true if the write must be done in a transaction with tasks or outbox events
+func (o WriteOptions) InTxn() bool {
+	return o.SyncEnable || o.OutboxEnable
 }
 
 // FindOptions is option to find key value
@@ -62,9 +92,12 @@ type FindOptions struct {
 	Status      string
 	Depth       int
 	ID          string
+	IDs         []string
 	Key         string
 	Value       string
 	Labels      map[string]string
+	// Selector is the requirements of labels besides equality
+	Selector    selector.Selector
 	LabelFormat string
 	ClearLabel  bool
 	Timeout     time.Duration
@@ -73,6 +106,25 @@ type FindOptions struct {
 	// Limit the page size of the response, dot not paging if limit=0
 	Limit         int64
 	CaseSensitive bool
+	// KeyRegex and ValueRegex are regular expressions in RE2 syntax without nested repetitions,
+	// they are case insensitive unless CaseSensitive is set.
+	// mongodb matches them by PCRE, where "$" also matches before a trailing newline
+	// and "\s" also matches vertical tab
+	KeyRegex   string
+	ValueRegex string
+	ValueType  string
+	// UpdatedAfter and UpdatedBefore are unix seconds, 0 means unlimited
+	UpdatedAfter  int64
+	UpdatedBefore int64
+	// Sort is the field to sort kvs by, kvs are paged by Cursor and Limit instead of Offset if it is set
+	Sort     string
+	SortDesc bool
+	Cursor   *Cursor
+}
+
+// IsSearch return true if kvs are searched by regex, value type or update time
+func (o FindOptions) IsSearch() bool {
+	return o.KeyRegex != "" || o.ValueRegex != "" || o.ValueType != "" || o.UpdatedAfter != 0 || o.UpdatedBefore != 0
 }
 
 // WriteOption is functional option to create, update and delete kv
@@ -88,6 +140,14 @@ func WithSync(enabled bool) WriteOption {
 	}
 }
 
+// WithOutbox indicates that kv change events should be written to outbox, and delivered by the node
+func WithOutbox(node string) WriteOption {
+	return func(o *WriteOptions) {
+		o.OutboxEnable = true
+		o.Node = node
+	}
+}
+
 // WithCaseSensitive tell model service whether to match case of letters or not.
 func WithCaseSensitive() FindOption {
 	return func(o *FindOptions) {
@@ -109,6 +169,13 @@ func WithID(id string) FindOption {
 	}
 }
 
+// WithIDs find kvs by ids in one query
+func WithIDs(ids []string) FindOption {
+	return func(o *FindOptions) {
+		o.IDs = ids
+	}
+}
+
 // WithKey find by key
 func WithKey(key string) FindOption {
 	return func(o *FindOptions) {
@@ -123,6 +190,57 @@ func WithValue(value string) FindOption {
 	}
 }
 
+// WithSelector find by label selector
+func WithSelector(s selector.Selector) FindOption {
+	return func(o *FindOptions) {
+		o.Selector = s
+	}
+}
+
+// WithKeyRegex find by regex of key
+func WithKeyRegex(regex string) FindOption {
+	return func(o *FindOptions) {
+		o.KeyRegex = regex
+	}
+}
+
+// WithValueRegex find by regex of value
+func WithValueRegex(regex string) FindOption {
+	return func(o *FindOptions) {
+		o.ValueRegex = regex
+	}
+}
+
+// WithValueType find by value type
+func WithValueType(valueType string) FindOption {
+	return func(o *FindOptions) {
+		o.ValueType = valueType
+	}
+}
+
+// WithUpdateTimeRange find kvs updated in [after, before], in unix seconds
+func WithUpdateTimeRange(after, before int64) FindOption {
+	return func(o *FindOptions) {
+		o.UpdatedAfter = after
+		o.UpdatedBefore = before
+	}
+}
+
+// WithSort sort kvs by the field, in descending order if desc is true
+func WithSort(field string, desc bool) FindOption {
+	return func(o *FindOptions) {
+		o.Sort = field
+		o.SortDesc = desc
+	}
+}
+
+// WithCursor return kvs after the cursor
+func WithCursor(c *Cursor) FindOption {
+	return func(o *FindOptions) {
+		o.Cursor = c
+	}
+}
+
 // WithStatus enabled/disabled
 func WithStatus(status string) FindOption {
 	return func(o *FindOptions) {
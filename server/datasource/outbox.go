@@ -0,0 +1,59 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package datasource
+
+import (
+	"context"
+	"time"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/gofrs/uuid"
+)
+
+// actions of outbox events, they are the same as the actions of kv change events
+const (
+	OutboxActionPut    = "put"
+	OutboxActionDelete = "del"
+)
+
+// OutboxDao persists kv change events until they are delivered to the bus,
+// events are written by KVDao in the same transaction as kv mutations
+type OutboxDao interface {
+	// List return at most limit pending events after the given one, the earliest first,
+	// after is nil to list from the earliest event, so that callers can page past events they skip
+	List(ctx context.Context, after *model.OutboxEvent, limit int64) ([]*model.OutboxEvent, error)
+	// Done removes delivered events
+	Done(ctx context.Context, events []*model.OutboxEvent) error
+}
+
+// NewOutboxEvent return a pending event of the kv mutation
+func NewOutboxEvent(domain, project string, kv *model.KVDoc, action, node string) (*model.OutboxEvent, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return nil, err
+	}
+	return &model.OutboxEvent{
+		ID:        id.String(),
+		Node:      node,
+		Key:       kv.Key,
+		Labels:    kv.Labels,
+		Action:    action,
+		Domain:    domain,
+		Project:   project,
+		Timestamp: time.Now().UnixNano(),
+	}, nil
+}
@@ -0,0 +1,169 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package datasource
+
+import (
+	"context"
+	"sync"
+)
+
+// ReserveFunc adds n to the revision counter of the domain, and returns the new value,
+// so revisions from value-n+1 to value are reserved for the caller
+type ReserveFunc func(ctx context.Context, domain string, n int64) (int64, error)
+
+// RevisionAllocator hands out revisions from blocks reserved from the domain counter,
+// so that a write does not need a round trip to the counter, which is a hotspot of bulk writes.
+// revisions reserved but not handed out are skipped, the revision of a domain may have gaps.
+// blocks larger than 1 are for single node deployments, the block size of 1 reserves a revision for each write
+type RevisionAllocator struct {
+	mu     sync.Mutex
+	size   int64
+	blocks map[string]*revisionBlock
+	// projects is the last revision handed out to each project
+	projects map[string]int64
+}
+
+type revisionBlock struct {
+	next int64
+	last int64
+	// projects is the projects whose revision is stored as the last of the block
+	projects map[string]bool
+}
+
+// StoreProjectFunc stores rev as the revision of the project
+type StoreProjectFunc func(ctx context.Context, domain, project string, rev int64) error
+
+var revisions = NewRevisionAllocator(1)
+
+// Revisions returns the allocator shared by backends
+func Revisions() *RevisionAllocator {
+	return revisions
+}
+
+// NewRevisionAllocator returns an allocator reserving size revisions at once
+func NewRevisionAllocator(size int64) *RevisionAllocator {
+	if size < 1 {
+		size = 1
+	}
+	return &RevisionAllocator{
+		size:     size,
+		blocks:   make(map[string]*revisionBlock),
+		projects: make(map[string]int64),
+	}
+}
+
+// SetBlockSize changes the number of revisions reserved at once, reserved blocks are dropped
+func (a *RevisionAllocator) SetBlockSize(size int64) {
+	if size < 1 {
+		size = 1
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.size = size
+	a.blocks = make(map[string]*revisionBlock)
+	a.projects = make(map[string]int64)
+}
+
+// BlockSize returns the number of revisions reserved at once
+func (a *RevisionAllocator) BlockSize() int64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.size
+}
+
+// Drop drops the reserved block of the domain, the next revision is from a new block
+func (a *RevisionAllocator) Drop(domain string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.blocks, domain)
+}
+
+// Next returns the next revision of the domain, it calls reserve for a new block if the block is used up
+func (a *RevisionAllocator) Next(ctx context.Context, domain string, reserve ReserveFunc) (int64, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.next(ctx, domain, reserve)
+}
+
+// NextForProject returns the next revision of the domain like Next, and it is the revision of the project as well.
+// the project revision is stored as the last revision of the block on the first write of the project in the block,
+// so that it costs one round trip for a block instead of a write, and a restarted node never reads a revision
+// of the project older than a write
+func (a *RevisionAllocator) NextForProject(ctx context.Context, domain, project string, reserve ReserveFunc,
+	store StoreProjectFunc) (int64, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	rev, err := a.next(ctx, domain, reserve)
+	if err != nil {
+		return 0, err
+	}
+	b := a.blocks[domain]
+	if !b.projects[project] {
+		if err := store(ctx, domain, project, b.last); err != nil {
+			return 0, err
+		}
+		if b.projects == nil {
+			b.projects = make(map[string]bool)
+		}
+		b.projects[project] = true
+	}
+	a.projects[domain+"/"+project] = rev
+	return rev, nil
+}
+
+// ProjectRevision returns the revision of the project from the stored one,
+// the stored one is ahead of writes in the block, so the revision handed out last is returned instead
+func (a *RevisionAllocator) ProjectRevision(domain, project string, stored int64) int64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.size == 1 {
+		return stored
+	}
+	if rev, ok := a.projects[domain+"/"+project]; ok && rev <= stored {
+		return rev
+	}
+	return stored
+}
+
+// Visible returns the revision readers can see from the counter of the domain,
+// revisions this node reserved but not handed out yet are left out,
+// so that readers never see a revision larger than the one of a later write
+func (a *RevisionAllocator) Visible(domain string, counter int64) int64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	b, ok := a.blocks[domain]
+	if !ok || b.last != counter {
+		return counter
+	}
+	return b.next - 1
+}
+
+func (a *RevisionAllocator) next(ctx context.Context, domain string, reserve ReserveFunc) (int64, error) {
+	b, ok := a.blocks[domain]
+	if !ok || b.next > b.last {
+		last, err := reserve(ctx, domain, a.size)
+		if err != nil {
+			return 0, err
+		}
+		b = &revisionBlock{next: last - a.size + 1, last: last}
+		a.blocks[domain] = b
+	}
+	rev := b.next
+	b.next++
+	return rev, nil
+}
@@ -33,3 +33,111 @@ func TestIncreaseAndGetRevision(t *testing.T) {
 	_, err := dao.GetRevision(context.TODO(), "default")
 	assert.NoError(t, err)
 }
+
+func TestProjectRevision(t *testing.T) {
+	dao := datasource.GetBroker().GetRevisionDao()
+	ctx := context.TODO()
+	quiet, err := dao.GetProjectRevision(ctx, "quiet", "default")
+	assert.NoError(t, err)
+	noisy, err := dao.GetProjectRevision(ctx, "noisy", "default")
+	assert.NoError(t, err)
+	domain, err := dao.GetRevision(ctx, "default")
+	assert.NoError(t, err)
+
+	rev, err := dao.ApplyRevision(ctx, "noisy", "default")
+	assert.NoError(t, err)
+	assert.Equal(t, domain+1, rev)
+	latest, err := dao.GetProjectRevision(ctx, "noisy", "default")
+	assert.NoError(t, err)
+	assert.True(t, latest > noisy)
+	assert.Equal(t, rev, latest, "the project revision is the domain revision of the last write")
+	latest, err = dao.GetProjectRevision(ctx, "quiet", "default")
+	assert.NoError(t, err)
+	assert.Equal(t, quiet, latest)
+}
+
+func TestRevisionAllocator(t *testing.T) {
+	var counter int64
+	calls := 0
+	reserve := func(ctx context.Context, domain string, n int64) (int64, error) {
+		calls++
+		counter += n
+		return counter, nil
+	}
+	a := datasource.NewRevisionAllocator(3)
+	for want := int64(1); want <= 4; want++ {
+		rev, err := a.Next(context.TODO(), "default", reserve)
+		assert.NoError(t, err)
+		assert.Equal(t, want, rev)
+	}
+	assert.Equal(t, 2, calls)
+
+	a.Drop("default")
+	rev, err := a.Next(context.TODO(), "default", reserve)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), rev, "revisions 5 and 6 are skipped")
+	assert.Equal(t, 3, calls)
+}
+
+func TestApplyRevisionInBlock(t *testing.T) {
+	dao := datasource.GetBroker().GetRevisionDao()
+	ctx := context.TODO()
+	datasource.Revisions().SetBlockSize(10)
+	defer datasource.Revisions().SetBlockSize(1)
+	first, err := dao.ApplyRevision(ctx, "block", "default")
+	assert.NoError(t, err)
+	second, err := dao.ApplyRevision(ctx, "block", "default")
+	assert.NoError(t, err)
+	assert.Equal(t, first+1, second)
+	visible, err := dao.GetRevision(ctx, "default")
+	assert.NoError(t, err)
+	assert.True(t, visible >= second, "the handed out revision is visible")
+}
+
+func TestRevisionVisible(t *testing.T) {
+	var counter int64
+	reserve := func(ctx context.Context, domain string, n int64) (int64, error) {
+		counter += n
+		return counter, nil
+	}
+	a := datasource.NewRevisionAllocator(10)
+	for i := 0; i < 2; i++ {
+		_, err := a.Next(context.TODO(), "default", reserve)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int64(2), a.Visible("default", counter), "reserved revisions are left out")
+	assert.Equal(t, counter+5, a.Visible("default", counter+5), "revisions reserved by others are visible")
+	assert.Equal(t, int64(7), a.Visible("other", 7))
+}
+
+func TestRevisionAllocatorProject(t *testing.T) {
+	var counter int64
+	reserve := func(ctx context.Context, domain string, n int64) (int64, error) {
+		counter += n
+		return counter, nil
+	}
+	stored := make(map[string][]int64)
+	store := func(ctx context.Context, domain, project string, rev int64) error {
+		stored[project] = append(stored[project], rev)
+		return nil
+	}
+	a := datasource.NewRevisionAllocator(3)
+	var revs []int64
+	for _, project := range []string{"a", "a", "b", "a"} {
+		rev, err := a.NextForProject(context.TODO(), "default", project, reserve, store)
+		assert.NoError(t, err)
+		revs = append(revs, rev)
+	}
+	assert.Equal(t, []int64{1, 2, 3, 4}, revs)
+	assert.Equal(t, []int64{3, 6}, stored["a"], "the last of the block is stored once a block")
+	assert.Equal(t, []int64{3}, stored["b"])
+	assert.Equal(t, int64(4), a.ProjectRevision("default", "a", 6), "writes in the block are not stored")
+	assert.Equal(t, int64(3), a.ProjectRevision("default", "b", 3))
+	assert.Equal(t, int64(5), a.ProjectRevision("default", "c", 5), "projects not written by the node are stored")
+
+	a.SetBlockSize(1)
+	rev, err := a.NextForProject(context.TODO(), "default", "a", reserve, store)
+	assert.NoError(t, err)
+	assert.Equal(t, rev, stored["a"][2], "every write is stored without blocks")
+	assert.Equal(t, rev+1, a.ProjectRevision("default", "a", rev+1), "writes of other nodes are read")
+}
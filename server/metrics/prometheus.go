@@ -14,6 +14,17 @@ import (
 const domain = "default"
 const project = "default"
 
+const (
+	eventQueueDepth    = "servicecomb_kie_event_queue_depth"
+	eventFlushDuration = "servicecomb_kie_event_flush_duration_seconds"
+	cacheHits          = "servicecomb_kie_long_polling_cache_hits_total"
+	cacheMisses        = "servicecomb_kie_long_polling_cache_misses_total"
+	cacheSize          = "servicecomb_kie_long_polling_cache_size"
+)
+
+// enabled is false until metrics are created, reports are ignored before that
+var enabled bool
+
 func InitMetric() error {
 	err := metrics.CreateGauge(metrics.GaugeOpts{
 		Key:    "servicecomb_kie_config_count",
@@ -24,6 +35,13 @@ func InitMetric() error {
 		openlog.Error("init servicecomb_kie_config_count Gauge fail:" + err.Error())
 		return err
 	}
+	if err = initEventMetric(); err != nil {
+		return err
+	}
+	if err = initCacheMetric(); err != nil {
+		return err
+	}
+	enabled = true
 	reportIntervalstr := archaius.GetString("servicecomb.metrics.interval", "5s")
 	reportInterval, _ := time.ParseDuration(reportIntervalstr)
 	reportTicker := time.NewTicker(reportInterval)
@@ -39,6 +57,49 @@ func InitMetric() error {
 	return nil
 }
 
+func initEventMetric() error {
+	err := metrics.CreateGauge(metrics.GaugeOpts{
+		Key:  eventQueueDepth,
+		Help: "use to show the number of kv change events waiting to be fired",
+	})
+	if err != nil {
+		openlog.Error("init " + eventQueueDepth + " Gauge fail:" + err.Error())
+		return err
+	}
+	err = metrics.CreateSummary(metrics.SummaryOpts{
+		Key:        eventFlushDuration,
+		Help:       "use to show the latency of firing one batch of kv change events",
+		Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+	})
+	if err != nil {
+		openlog.Error("init " + eventFlushDuration + " Summary fail:" + err.Error())
+		return err
+	}
+	return nil
+}
+
+func initCacheMetric() error {
+	for _, key := range []string{cacheHits, cacheMisses} {
+		err := metrics.CreateCounter(metrics.CounterOpts{
+			Key:  key,
+			Help: "use to show the number of long polling cache lookups",
+		})
+		if err != nil {
+			openlog.Error("init " + key + " Counter fail:" + err.Error())
+			return err
+		}
+	}
+	err := metrics.CreateGauge(metrics.GaugeOpts{
+		Key:  cacheSize,
+		Help: "use to show the number of cached long polling results",
+	})
+	if err != nil {
+		openlog.Error("init " + cacheSize + " Gauge fail:" + err.Error())
+		return err
+	}
+	return nil
+}
+
 func getTotalConfigCount(project, domain string) {
 	total, err := datasource.GetBroker().GetKVDao().Total(context.TODO(), project, domain)
 	if err != nil {
@@ -52,3 +113,53 @@ func getTotalConfigCount(project, domain string) {
 		return
 	}
 }
+
+// ReportEventQueueDepth set the number of kv change events waiting to be fired
+func ReportEventQueueDepth(n int) {
+	if !enabled {
+		return
+	}
+	if err := metrics.GaugeSet(eventQueueDepth, float64(n), nil); err != nil {
+		openlog.Error("set event queue depth fail:" + err.Error())
+	}
+}
+
+// ReportEventFlush observe the latency of firing one batch of kv change events
+func ReportEventFlush(d time.Duration) {
+	if !enabled {
+		return
+	}
+	if err := metrics.SummaryObserve(eventFlushDuration, d.Seconds(), nil); err != nil {
+		openlog.Error("observe event flush duration fail:" + err.Error())
+	}
+}
+
+// ReportCacheHit count a long polling cache hit
+func ReportCacheHit() {
+	if !enabled {
+		return
+	}
+	if err := metrics.CounterAdd(cacheHits, 1, nil); err != nil {
+		openlog.Error("count cache hit fail:" + err.Error())
+	}
+}
+
+// ReportCacheMiss count a long polling cache miss
+func ReportCacheMiss() {
+	if !enabled {
+		return
+	}
+	if err := metrics.CounterAdd(cacheMisses, 1, nil); err != nil {
+		openlog.Error("count cache miss fail:" + err.Error())
+	}
+}
+
+// ReportCacheSize set the number of cached long polling results
+func ReportCacheSize(n int) {
+	if !enabled {
+		return
+	}
+	if err := metrics.GaugeSet(cacheSize, float64(n), nil); err != nil {
+		openlog.Error("set cache size fail:" + err.Error())
+	}
+}
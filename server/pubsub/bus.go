@@ -43,10 +43,20 @@ const (
 
 var topics sync.Map
 
+// topicsMux guards the creation and removal of topics, to keep topics and the index consistent
+var topicsMux sync.Mutex
+var index = newTopicIndex()
+
 func Topics() *sync.Map {
 	return &topics
 }
 
+// MatchTopics return names of the topics which match the event,
+// it only looks up the topics indexed by domain, project and labels of the event
+func MatchTopics(event *KVChangeEvent) []string {
+	return index.match(event)
+}
+
 // Bus is message bug
 type Bus struct {
 	agent *agent.Agent
@@ -105,8 +115,10 @@ func Start() {
 		openlog.Fatal("can not sync key value change events to other kie nodes" + err.Error())
 	}
 	openlog.Info("kie message bus started")
+	runHandlers()
 	eh := &ClusterEventHandler{}
 	bus.agent.RegisterEventHandler(eh)
+	go reportEventStats()
 
 	if config.Configurations.PeerAddr != "" {
 		err := join([]string{config.Configurations.PeerAddr})
@@ -141,11 +153,14 @@ func AddObserver(o *Observer, topic *Topic) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	topicsMux.Lock()
+	defer topicsMux.Unlock()
 	observers, ok := topics.Load(t)
 	if !ok {
 		var observers = &sync.Map{}
 		observers.Store(o.UUID, o)
 		topics.Store(t, observers)
+		index.add(t, topic)
 		openlog.Info("new topic:" + t)
 		return t, nil
 	}
@@ -155,12 +170,51 @@ func AddObserver(o *Observer, topic *Topic) (string, error) {
 	return t, nil
 }
 
+// RemoveObserver remove the observer from topic, and remove the topic if it has no observer any more
 func RemoveObserver(uuid string, topic *Topic) {
 	t, err := topic.Encode()
 	if err != nil {
 		openlog.Error(err.Error())
+		return
+	}
+	topicsMux.Lock()
+	defer topicsMux.Unlock()
+	observers, ok := topics.Load(t)
+	if !ok {
+		return
 	}
-	observers, _ := topics.Load(t)
 	m := observers.(*sync.Map)
 	m.Delete(uuid)
+	if isEmpty(m) {
+		topics.Delete(t)
+		index.remove(t, topic)
+		openlog.Debug("remove topic:" + t)
+	}
+}
+
+// RemoveTopicIfEmpty remove the topic and its index entries if all of its observers are removed
+func RemoveTopicIfEmpty(t string) {
+	topicsMux.Lock()
+	defer topicsMux.Unlock()
+	observers, ok := topics.Load(t)
+	if !ok || !isEmpty(observers.(*sync.Map)) {
+		return
+	}
+	topic, err := ParseTopic(t)
+	if err != nil {
+		openlog.Error(err.Error())
+		return
+	}
+	topics.Delete(t)
+	index.remove(t, topic)
+	openlog.Debug("remove topic:" + t)
+}
+
+func isEmpty(m *sync.Map) bool {
+	empty := true
+	m.Range(func(_, _ interface{}) bool {
+		empty = false
+		return false
+	})
+	return empty
 }
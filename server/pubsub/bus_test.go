@@ -24,6 +24,7 @@ import (
 
 	"github.com/apache/servicecomb-kie/server/pubsub"
 	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestInit(t *testing.T) {
@@ -53,3 +54,89 @@ func TestInit(t *testing.T) {
 	e := <-o.Event
 	t.Log(e.Key)
 }
+
+func TestMatchTopics(t *testing.T) {
+	newObserver := func() *pubsub.Observer {
+		id, _ := uuid.NewV4()
+		return &pubsub.Observer{
+			UUID:  id.String(),
+			Event: make(chan *pubsub.KVChangeEvent, 1),
+		}
+	}
+	appTopic := &pubsub.Topic{Project: "match", DomainID: "default",
+		Labels: map[string]string{"app": "mall"}}
+	svcTopic := &pubsub.Topic{Project: "match", DomainID: "default",
+		Labels: map[string]string{"app": "mall", "service": "cart"}}
+	exactTopic := &pubsub.Topic{Project: "match", DomainID: "default", MatchType: "exact",
+		Labels: map[string]string{"app": "mall"}}
+	allTopic := &pubsub.Topic{Project: "match", DomainID: "default"}
+	otherTopic := &pubsub.Topic{Project: "other", DomainID: "default",
+		Labels: map[string]string{"app": "mall"}}
+	names := make(map[*pubsub.Topic]string)
+	observers := make(map[*pubsub.Topic]*pubsub.Observer)
+	for _, topic := range []*pubsub.Topic{appTopic, svcTopic, exactTopic, allTopic, otherTopic} {
+		o := newObserver()
+		name, err := pubsub.AddObserver(o, topic)
+		assert.NoError(t, err)
+		names[topic] = name
+		observers[topic] = o
+	}
+
+	t.Run("event with app and service labels should match sub set topics", func(t *testing.T) {
+		matched := pubsub.MatchTopics(&pubsub.KVChangeEvent{Key: "timeout", Project: "match", DomainID: "default",
+			Labels: map[string]string{"app": "mall", "service": "cart"}})
+		assert.ElementsMatch(t, []string{names[appTopic], names[svcTopic], names[allTopic]}, matched)
+	})
+	t.Run("event with app label should match exact topic", func(t *testing.T) {
+		matched := pubsub.MatchTopics(&pubsub.KVChangeEvent{Key: "timeout", Project: "match", DomainID: "default",
+			Labels: map[string]string{"app": "mall"}})
+		assert.ElementsMatch(t, []string{names[appTopic], names[exactTopic], names[allTopic]}, matched)
+	})
+	t.Run("event of other project should only match topic of that project", func(t *testing.T) {
+		matched := pubsub.MatchTopics(&pubsub.KVChangeEvent{Key: "timeout", Project: "other", DomainID: "default",
+			Labels: map[string]string{"app": "mall"}})
+		assert.ElementsMatch(t, []string{names[otherTopic]}, matched)
+	})
+	t.Run("topic without observers should be removed", func(t *testing.T) {
+		pubsub.RemoveObserver(observers[otherTopic].UUID, otherTopic)
+		matched := pubsub.MatchTopics(&pubsub.KVChangeEvent{Key: "timeout", Project: "other", DomainID: "default",
+			Labels: map[string]string{"app": "mall"}})
+		assert.Empty(t, matched)
+		_, ok := pubsub.Topics().Load(names[otherTopic])
+		assert.False(t, ok)
+	})
+	t.Run("resolve topic should match event with fewer labels", func(t *testing.T) {
+		resolveTopic := &pubsub.Topic{Project: "resolve", DomainID: "default", Resolve: true,
+			Labels: map[string]string{"app": "mall", "service": "cart"}}
+		o := newObserver()
+		name, err := pubsub.AddObserver(o, resolveTopic)
+		assert.NoError(t, err)
+		defer pubsub.RemoveObserver(o.UUID, resolveTopic)
+		matched := pubsub.MatchTopics(&pubsub.KVChangeEvent{Key: "db.host", Project: "resolve", DomainID: "default",
+			Labels: map[string]string{"app": "mall"}})
+		assert.ElementsMatch(t, []string{name}, matched)
+		matched = pubsub.MatchTopics(&pubsub.KVChangeEvent{Key: "db.host", Project: "resolve", DomainID: "default"})
+		assert.ElementsMatch(t, []string{name}, matched)
+		matched = pubsub.MatchTopics(&pubsub.KVChangeEvent{Key: "db.host", Project: "resolve", DomainID: "default",
+			Labels: map[string]string{"app": "mall", "version": "1.0"}})
+		assert.Empty(t, matched)
+	})
+	t.Run("combination topic should be matched once by any combination", func(t *testing.T) {
+		combinationTopic := &pubsub.Topic{Project: "combination", DomainID: "default", Combinations: []map[string]string{
+			{"app": "mall"}, {"app": "mall", "service": "cart"}}}
+		o := newObserver()
+		name, err := pubsub.AddObserver(o, combinationTopic)
+		assert.NoError(t, err)
+		matched := pubsub.MatchTopics(&pubsub.KVChangeEvent{Key: "timeout", Project: "combination", DomainID: "default",
+			Labels: map[string]string{"app": "mall", "service": "cart"}})
+		assert.Equal(t, []string{name}, matched)
+		matched = pubsub.MatchTopics(&pubsub.KVChangeEvent{Key: "timeout", Project: "combination", DomainID: "default",
+			Labels: map[string]string{"app": "shop"}})
+		assert.Empty(t, matched)
+
+		pubsub.RemoveObserver(o.UUID, combinationTopic)
+		matched = pubsub.MatchTopics(&pubsub.KVChangeEvent{Key: "timeout", Project: "combination", DomainID: "default",
+			Labels: map[string]string{"app": "mall"}})
+		assert.Empty(t, matched)
+	})
+}
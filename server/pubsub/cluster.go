@@ -0,0 +1,182 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package pubsub
+
+import (
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/datasource"
+	"github.com/go-chassis/openlog"
+	"github.com/hashicorp/serf/serf"
+)
+
+// tags to gossip event stats of a node
+const (
+	TagEventsReceived = "kie_events_received"
+	TagLastEventTime  = "kie_last_event_time"
+)
+
+// statsReportInterval is the interval to gossip event stats,
+// tags update is broadcast to all nodes, so do not report every event
+const statsReportInterval = 10 * time.Second
+
+// errors of cluster operations
+var (
+	ErrRemoveLocalNode = errors.New("can not remove the local node")
+	ErrNodeNotExists   = errors.New("node does not exist")
+	ErrNotAlive        = errors.New("the local node has left the cluster")
+)
+
+var (
+	eventsReceived int64
+	lastEventTime  int64
+)
+
+func recordEvent() {
+	atomic.AddInt64(&eventsReceived, 1)
+	atomic.StoreInt64(&lastEventTime, time.Now().Unix())
+}
+
+// Members return all nodes known by the local node, including failed and left nodes
+func Members() []*model.ClusterMember {
+	local := bus.agent.Serf().LocalMember().Name
+	members := bus.agent.Serf().Members()
+	result := make([]*model.ClusterMember, 0, len(members))
+	for _, m := range members {
+		cm := &model.ClusterMember{
+			Name:   m.Name,
+			Addr:   m.Addr.String() + ":" + strconv.Itoa(int(m.Port)),
+			Status: m.Status.String(),
+			Tags:   make(map[string]string, len(m.Tags)),
+			Local:  m.Name == local,
+		}
+		for k, v := range m.Tags {
+			switch k {
+			case TagEventsReceived:
+				cm.EventsReceived, _ = strconv.ParseInt(v, 10, 64)
+			case TagLastEventTime:
+				cm.LastEventTime, _ = strconv.ParseInt(v, 10, 64)
+			default:
+				cm.Tags[k] = v
+			}
+		}
+		if cm.Local {
+			// gossiped stats of the local node may be out of date
+			cm.EventsReceived = atomic.LoadInt64(&eventsReceived)
+			cm.LastEventTime = atomic.LoadInt64(&lastEventTime)
+		}
+		result = append(result, cm)
+	}
+	return result
+}
+
+// LocalNode return the name of the local node
+func LocalNode() string {
+	return bus.agent.Serf().LocalMember().Name
+}
+
+// Join join the local node to a cluster by addresses of existing nodes,
+// return the number of nodes successfully contacted
+func Join(addresses []string) (int, error) {
+	return bus.agent.Join(addresses, false)
+}
+
+// Leave leaves the cluster gracefully, other nodes see the local node as left instead of failed,
+// it stops exchanging events with them, and can not join again until it restarts
+func Leave() error {
+	if bus.agent.Serf().State() != serf.SerfAlive {
+		return ErrNotAlive
+	}
+	return bus.agent.Leave()
+}
+
+// ForceLeave remove a failed node from the cluster, an alive node can not be removed,
+// if prune is true, the node is erased from the member list immediately
+func ForceLeave(node string, prune bool) error {
+	if node == bus.agent.Serf().LocalMember().Name {
+		return ErrRemoveLocalNode
+	}
+	if !isMember(node) {
+		return ErrNodeNotExists
+	}
+	if prune {
+		return bus.agent.ForceLeavePrune(node)
+	}
+	return bus.agent.ForceLeave(node)
+}
+
+// limitRevisionBlock reserves one revision at once once other nodes join,
+// nodes handing out revisions of their own blocks would break the order of revisions in the domain
+func limitRevisionBlock() {
+	if datasource.Revisions().BlockSize() == 1 || aliveMembers() < 2 {
+		return
+	}
+	openlog.Warn("other kie nodes joined, revision block is limited to 1")
+	datasource.Revisions().SetBlockSize(1)
+}
+
+func aliveMembers() int {
+	n := 0
+	for _, m := range bus.agent.Serf().Members() {
+		if m.Status == serf.StatusAlive {
+			n++
+		}
+	}
+	return n
+}
+
+func isMember(node string) bool {
+	for _, m := range bus.agent.Serf().Members() {
+		if m.Name == node {
+			return true
+		}
+	}
+	return false
+}
+
+// reportEventStats gossips event stats of the local node as tags
+func reportEventStats() {
+	ticker := time.NewTicker(statsReportInterval)
+	defer ticker.Stop()
+	var reported int64
+	for {
+		select {
+		case <-ticker.C:
+		case <-bus.agent.ShutdownCh():
+			return
+		}
+		received := atomic.LoadInt64(&eventsReceived)
+		if received == reported {
+			continue
+		}
+		tags := make(map[string]string)
+		for k, v := range bus.agent.Serf().LocalMember().Tags {
+			tags[k] = v
+		}
+		tags[TagEventsReceived] = strconv.FormatInt(received, 10)
+		tags[TagLastEventTime] = strconv.FormatInt(atomic.LoadInt64(&lastEventTime), 10)
+		if err := bus.agent.SetTags(tags); err != nil {
+			openlog.Warn("can not report event stats: " + err.Error())
+			continue
+		}
+		reported = received
+	}
+}
@@ -27,11 +27,28 @@ import (
 
 var handlers = make(map[string]agent.EventHandler)
 
+// Runnable is implemented by handlers which run background tasks,
+// Run is called after bus started and configurations are loaded
+type Runnable interface {
+	Run()
+}
+
 func RegisterHandler(typ string, h agent.EventHandler) {
 	handlers[typ] = h
 	openlog.Info("register handler for:" + typ)
 }
 
+func runHandlers() {
+	for typ, h := range handlers {
+		r, ok := h.(Runnable)
+		if !ok {
+			continue
+		}
+		r.Run()
+		openlog.Info("run handler for:" + typ)
+	}
+}
+
 // ClusterEventHandler handler serf custom event, it is singleton
 type ClusterEventHandler struct {
 }
@@ -41,7 +58,10 @@ func (h *ClusterEventHandler) HandleEvent(e serf.Event) {
 	openlog.Debug("receive event:" + e.EventType().String())
 	switch e.EventType().String() {
 	case "user":
+		recordEvent()
 		h.DispatchEvent(e)
+	case "member-join":
+		limitRevisionBlock()
 	}
 
 }
@@ -1,9 +1,14 @@
 package notifier
 
 import (
+	"fmt"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/apache/servicecomb-kie/pkg/stringutil"
+	"github.com/apache/servicecomb-kie/server/config"
+	"github.com/apache/servicecomb-kie/server/metrics"
 	"github.com/apache/servicecomb-kie/server/pubsub"
 	"github.com/go-chassis/openlog"
 	"github.com/hashicorp/serf/serf"
@@ -11,70 +16,129 @@ import (
 
 // KVHandler handler serf custom event, it is singleton
 type KVHandler struct {
-	BatchSize          int
-	BatchInterval      time.Duration
-	Immediate          bool
-	pendingEvents      sync.Map
-	pendingEventsCount int
+	BatchSize     int
+	BatchInterval time.Duration
+	Immediate     bool
+
+	mu            sync.Mutex
+	pendingEvents map[string]*pubsub.KVChangeEvent
+	full          chan struct{}
+}
+
+// NewKVHandler return a handler which fires events immediately
+func NewKVHandler() *KVHandler {
+	return &KVHandler{
+		BatchInterval: pubsub.DefaultEventBatchInterval,
+		BatchSize:     pubsub.DefaultEventBatchSize,
+		Immediate:     true,
+		pendingEvents: make(map[string]*pubsub.KVChangeEvent),
+		full:          make(chan struct{}, 1),
+	}
+}
+
+// Run load event config, and start flush task if events should be coalesced
+func (h *KVHandler) Run() {
+	c := config.GetEvent()
+	if !c.Coalesce {
+		openlog.Info("fire kv change events immediately")
+		return
+	}
+	if c.BatchSize > 0 {
+		h.BatchSize = c.BatchSize
+	}
+	if c.BatchInterval != "" {
+		d, err := time.ParseDuration(c.BatchInterval)
+		if err != nil || d <= 0 {
+			openlog.Warn(fmt.Sprintf("invalid event batch interval [%s], use default [%s]",
+				c.BatchInterval, pubsub.DefaultEventBatchInterval))
+		} else {
+			h.BatchInterval = d
+		}
+	}
+	h.Immediate = false
+	openlog.Info(fmt.Sprintf("coalesce kv change events, batch size [%d], batch interval [%s]",
+		h.BatchSize, h.BatchInterval))
+	go h.RunFlushTask()
 }
 
+// RunFlushTask fire pending events every batch interval, or once pending events reach batch size
 func (h *KVHandler) RunFlushTask() {
+	ticker := time.NewTicker(h.BatchInterval)
+	defer ticker.Stop()
 	for {
-		if h.pendingEventsCount >= h.BatchSize {
-			h.fireEvents()
+		select {
+		case <-ticker.C:
+		case <-h.full:
 		}
-		<-time.After(h.BatchInterval)
 		h.fireEvents()
 	}
-
 }
+
 func (h *KVHandler) HandleEvent(e serf.Event) {
 	ue := e.(serf.UserEvent)
 	ke, err := pubsub.NewKVChangeEvent(ue.Payload)
 	if err != nil {
 		openlog.Error("invalid json:" + string(ue.Payload))
+		return
 	}
 	openlog.Debug("kv event:" + ke.Key)
-	if h.Immediate { //never retain event, not recommended
+	if h.Immediate { //never retain event
 		h.FindTopicAndFire(ke)
 	} else {
 		h.mergeAndSave(ke)
 	}
 
 }
+
+// mergeAndSave keeps only the latest event of the same key, action and labels,
+// events of different keys or actions are kept, watchers are still woken up once in a batch,
+// because they are removed after being notified
 func (h *KVHandler) mergeAndSave(ke *pubsub.KVChangeEvent) {
-	id := ke.String()
-	_, ok := h.pendingEvents.Load(id)
+	id := coalesceID(ke)
+	h.mu.Lock()
+	_, ok := h.pendingEvents[id]
+	h.pendingEvents[id] = ke
+	n := len(h.pendingEvents)
+	metrics.ReportEventQueueDepth(n)
+	h.mu.Unlock()
 	if ok {
-		openlog.Debug("ignore same event: " + id)
+		openlog.Debug("coalesce event: " + id)
 		return
 	}
-	h.pendingEvents.Store(id, ke)
-	h.pendingEventsCount++
+	if n >= h.BatchSize {
+		select {
+		case h.full <- struct{}{}:
+		default:
+		}
+	}
 }
+
 func (h *KVHandler) fireEvents() {
-	h.pendingEvents.Range(func(key, value interface{}) bool {
-		ke := value.(*pubsub.KVChangeEvent)
+	h.mu.Lock()
+	events := h.pendingEvents
+	h.pendingEvents = make(map[string]*pubsub.KVChangeEvent, len(events))
+	metrics.ReportEventQueueDepth(0)
+	h.mu.Unlock()
+	if len(events) == 0 {
+		return
+	}
+	start := time.Now()
+	for _, ke := range events {
 		h.FindTopicAndFire(ke)
-		h.pendingEvents.Delete(key)
-		h.pendingEventsCount--
-		return true
-	})
+	}
+	metrics.ReportEventFlush(time.Since(start))
+	openlog.Debug(fmt.Sprintf("fired [%d] events", len(events)))
 }
 
 func (h *KVHandler) FindTopicAndFire(ke *pubsub.KVChangeEvent) {
-	topic := pubsub.Topics()
-	topic.Range(func(key, value interface{}) bool { //range all topics
-		t, err := pubsub.ParseTopic(key.(string))
-		if err != nil {
-			openlog.Error("can not parse topic " + key.(string) + ": " + err.Error())
-			return true
-		}
-		if t.Match(ke) {
-			notifyAndRemoveObservers(value, ke)
+	for _, t := range pubsub.MatchTopics(ke) {
+		observers, ok := pubsub.Topics().Load(t)
+		if !ok {
+			continue
 		}
-		return true
-	})
+		notifyAndRemoveObservers(observers, ke)
+		pubsub.RemoveTopicIfEmpty(t)
+	}
 }
 
 func notifyAndRemoveObservers(value interface{}, ke *pubsub.KVChangeEvent) {
@@ -86,12 +150,11 @@ func notifyAndRemoveObservers(value interface{}, ke *pubsub.KVChangeEvent) {
 		return true
 	})
 }
+
+func coalesceID(ke *pubsub.KVChangeEvent) string {
+	return strings.Join([]string{ke.DomainID, ke.Project, ke.Key, ke.Action, stringutil.FormatMap(ke.Labels)}, ";;")
+}
+
 func init() {
-	h := &KVHandler{
-		BatchInterval: pubsub.DefaultEventBatchInterval,
-		BatchSize:     pubsub.DefaultEventBatchSize,
-		Immediate:     true,
-	}
-	pubsub.RegisterHandler(pubsub.EventKVChange, h)
-	go h.RunFlushTask()
+	pubsub.RegisterHandler(pubsub.EventKVChange, NewKVHandler())
 }
@@ -0,0 +1,121 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package notifier
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/apache/servicecomb-kie/server/pubsub"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKVHandler_Coalesce(t *testing.T) {
+	h := NewKVHandler()
+	h.Immediate = false
+	topic := &pubsub.Topic{
+		DomainID: "default",
+		Project:  "coalesce",
+		Labels:   map[string]string{"app": "mall"},
+	}
+	o := &pubsub.Observer{
+		UUID:  "coalesce-observer",
+		Event: make(chan *pubsub.KVChangeEvent, 1),
+	}
+	_, err := pubsub.AddObserver(o, topic)
+	assert.NoError(t, err)
+	defer pubsub.RemoveObserver(o.UUID, topic)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			service := "cart"
+			if i%2 == 0 {
+				service = "order"
+			}
+			h.mergeAndSave(&pubsub.KVChangeEvent{
+				Key:      "key" + strconv.Itoa(i%4),
+				Action:   pubsub.ActionPut,
+				DomainID: "default",
+				Project:  "coalesce",
+				Labels:   map[string]string{"app": "mall", "service": service},
+			})
+		}(i)
+	}
+	wg.Wait()
+	t.Run("events of the same key, action and labels should be coalesced", func(t *testing.T) {
+		h.mu.Lock()
+		assert.Equal(t, 4, len(h.pendingEvents))
+		h.mu.Unlock()
+	})
+	t.Run("events of different actions should not be coalesced", func(t *testing.T) {
+		h.mergeAndSave(&pubsub.KVChangeEvent{
+			Key:      "key0",
+			Action:   pubsub.ActionDelete,
+			DomainID: "default",
+			Project:  "coalesce",
+			Labels:   map[string]string{"app": "mall", "service": "order"},
+		})
+		h.mu.Lock()
+		assert.Equal(t, 5, len(h.pendingEvents))
+		h.mu.Unlock()
+	})
+	t.Run("fire events, watcher should be woken up once", func(t *testing.T) {
+		h.fireEvents()
+		assert.Equal(t, 1, len(o.Event))
+		h.mu.Lock()
+		assert.Equal(t, 0, len(h.pendingEvents))
+		h.mu.Unlock()
+	})
+}
+
+func TestKVHandler_FindTopicAndFire(t *testing.T) {
+	h := NewKVHandler()
+	topic := &pubsub.Topic{
+		DomainID: "default",
+		Project:  "fire",
+		Labels:   map[string]string{"app": "mall"},
+		Resolve:  true,
+	}
+	o := &pubsub.Observer{
+		UUID:  "fire-observer",
+		Event: make(chan *pubsub.KVChangeEvent, 1),
+	}
+	name, err := pubsub.AddObserver(o, topic)
+	assert.NoError(t, err)
+	defer pubsub.RemoveObserver(o.UUID, topic)
+
+	ke := &pubsub.KVChangeEvent{
+		Key:      "key",
+		Action:   pubsub.ActionPut,
+		DomainID: "default",
+		Project:  "fire",
+		Labels:   map[string]string{"app": "mall"},
+	}
+	h.FindTopicAndFire(ke)
+	assert.Equal(t, 1, len(o.Event))
+	t.Run("topic without observers should be removed with its index", func(t *testing.T) {
+		_, ok := pubsub.Topics().Load(name)
+		assert.False(t, ok)
+		assert.Empty(t, pubsub.MatchTopics(ke))
+		assert.Empty(t, pubsub.MatchTopics(&pubsub.KVChangeEvent{DomainID: "default", Project: "fire"}))
+	})
+}
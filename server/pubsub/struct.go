@@ -20,9 +20,11 @@ package pubsub
 import (
 	"encoding/json"
 	"errors"
+	"sort"
 	"strings"
 
 	"github.com/apache/servicecomb-kie/pkg/common"
+	"github.com/apache/servicecomb-kie/pkg/selector"
 	"github.com/apache/servicecomb-kie/pkg/stringutil"
 	"github.com/apache/servicecomb-kie/pkg/util"
 )
@@ -60,10 +62,23 @@ type Topic struct {
 	DomainID     string            `json:"domainID,omitempty"`
 	Project      string            `json:"project,omitempty"`
 	MatchType    string            `json:"match,omitempty"`
+	// Selector is the label requirements besides equality, the event labels must satisfy all of them
+	Selector selector.Selector `json:"selector,omitempty"`
+	// Combinations are used instead of labels, the topic matches if any of them matches
+	Combinations       []map[string]string `json:"-"`
+	CombinationsFormat []string            `json:"combinations,omitempty"`
+	// Resolve topics query kvs with references resolved,
+	// so they also match changes of kvs which may be referenced by kvs of the topic
+	Resolve bool `json:"resolve,omitempty"`
 }
 
 func (t *Topic) Encode() (string, error) {
 	t.LabelsFormat = stringutil.FormatMap(t.Labels)
+	t.CombinationsFormat = nil
+	for _, c := range t.Combinations {
+		t.CombinationsFormat = append(t.CombinationsFormat, stringutil.FormatMap(c))
+	}
+	sort.Strings(t.CombinationsFormat)
 	b, err := json.Marshal(t)
 	if err != nil {
 		return "", err
@@ -80,20 +95,33 @@ func ParseTopic(s string) (*Topic, error) {
 	if err != nil {
 		return nil, err
 	}
-	if t.LabelsFormat == stringutil.LabelNone {
-		return t, nil
-	}
-	ls := strings.Split(t.LabelsFormat, "::")
-	if len(ls) != 0 {
-		for _, l := range ls {
-			s := strings.Split(l, "=")
-			if len(s) != 2 {
-				return nil, errors.New("invalid label:" + l)
-			}
-			t.Labels[s[0]] = s[1]
+	t.Labels, err = parseLabels(t.LabelsFormat)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range t.CombinationsFormat {
+		labels, err := parseLabels(f)
+		if err != nil {
+			return nil, err
 		}
+		t.Combinations = append(t.Combinations, labels)
 	}
-	return t, err
+	return t, nil
+}
+
+func parseLabels(format string) (map[string]string, error) {
+	labels := make(map[string]string)
+	if format == stringutil.LabelNone {
+		return labels, nil
+	}
+	for _, l := range strings.Split(format, "::") {
+		s := strings.Split(l, "=")
+		if len(s) != 2 {
+			return nil, errors.New("invalid label:" + l)
+		}
+		labels[s[0]] = s[1]
+	}
+	return labels, nil
 }
 
 // Match compare event with topic
@@ -102,17 +130,42 @@ func ParseTopic(s string) (*Topic, error) {
 //
 // If the match type is not set, it will be matched when pulling request labels is equal to
 // update request labels or a subset of it.
+//
+// The event must come from the same domain and project as the topic,
+// and its labels must satisfy the selector of the topic.
+// A topic of label combinations matches if any of the combinations matches.
 func (t *Topic) Match(event *KVChangeEvent) bool {
+	if t.DomainID != event.DomainID || t.Project != event.Project {
+		return false
+	}
+	if len(t.Combinations) == 0 {
+		return t.matchLabels(t.Labels, event)
+	}
+	for _, labels := range t.Combinations {
+		if t.matchLabels(labels, event) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Topic) matchLabels(labels map[string]string, event *KVChangeEvent) bool {
+	if t.Resolve && t.mayReference(labels, event) {
+		return true
+	}
+	if !t.Selector.Matches(event.Labels) {
+		return false
+	}
 	match := false
 	if t.MatchType == common.PatternExact {
-		if !util.IsEquivalentLabel(t.Labels, event.Labels) {
+		if !util.IsEquivalentLabel(labels, event.Labels) {
 			return false
 		}
 	}
-	if len(t.Labels) == 0 {
+	if len(labels) == 0 {
 		return true
 	}
-	for k, v := range t.Labels {
+	for k, v := range labels {
 		if event.Labels[k] != v {
 			return false
 		}
@@ -121,6 +174,12 @@ func (t *Topic) Match(event *KVChangeEvent) bool {
 	return match
 }
 
+// mayReference return true if kvs of the topic may reference the kv of the event,
+// a kv can reference kvs whose labels are a subset of its labels, so the event labels must be a subset of the topic labels
+func (t *Topic) mayReference(labels map[string]string, event *KVChangeEvent) bool {
+	return util.IsContainLabel(labels, event.Labels)
+}
+
 // Observer represents a client polling request
 type Observer struct {
 	UUID  string
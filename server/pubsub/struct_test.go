@@ -21,7 +21,9 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/apache/servicecomb-kie/pkg/selector"
 	"github.com/apache/servicecomb-kie/server/pubsub"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestTopic_String(t *testing.T) {
@@ -52,3 +54,88 @@ func TestTopic_String(t *testing.T) {
 	topic, _ = pubsub.ParseTopic(string(mock))
 	t.Log(topic)
 }
+
+func TestTopic_Match(t *testing.T) {
+	topic := &pubsub.Topic{
+		DomainID: "default",
+		Project:  "1",
+		Labels:   map[string]string{"app": "mall"},
+	}
+	assert.True(t, topic.Match(&pubsub.KVChangeEvent{DomainID: "default", Project: "1",
+		Labels: map[string]string{"app": "mall", "service": "cart"}}))
+	assert.False(t, topic.Match(&pubsub.KVChangeEvent{DomainID: "default", Project: "2",
+		Labels: map[string]string{"app": "mall"}}))
+	assert.False(t, topic.Match(&pubsub.KVChangeEvent{DomainID: "other", Project: "1",
+		Labels: map[string]string{"app": "mall"}}))
+	assert.False(t, topic.Match(&pubsub.KVChangeEvent{DomainID: "default", Project: "1",
+		Labels: map[string]string{"app": "shop"}}))
+
+	t.Run("resolve topic should match events of kvs which may be referenced", func(t *testing.T) {
+		topic := &pubsub.Topic{
+			DomainID: "default",
+			Project:  "1",
+			Labels:   map[string]string{"app": "mall", "service": "cart"},
+			Resolve:  true,
+		}
+		assert.True(t, topic.Match(&pubsub.KVChangeEvent{DomainID: "default", Project: "1",
+			Labels: map[string]string{"app": "mall"}}))
+		assert.True(t, topic.Match(&pubsub.KVChangeEvent{DomainID: "default", Project: "1",
+			Labels: map[string]string{"app": "mall", "service": "cart", "version": "1.0"}}))
+		assert.False(t, topic.Match(&pubsub.KVChangeEvent{DomainID: "default", Project: "1",
+			Labels: map[string]string{"app": "mall", "version": "1.0"}}))
+		assert.False(t, topic.Match(&pubsub.KVChangeEvent{DomainID: "default", Project: "1",
+			Labels: map[string]string{"app": "shop"}}))
+
+		topic.MatchType = "exact"
+		assert.True(t, topic.Match(&pubsub.KVChangeEvent{DomainID: "default", Project: "1",
+			Labels: map[string]string{"app": "mall"}}))
+		assert.False(t, topic.Match(&pubsub.KVChangeEvent{DomainID: "default", Project: "1",
+			Labels: map[string]string{"app": "mall", "version": "1.0"}}))
+	})
+
+	t.Run("topic with selector should match events which satisfy it", func(t *testing.T) {
+		topic := &pubsub.Topic{
+			DomainID: "default",
+			Project:  "1",
+			Labels:   map[string]string{"app": "mall"},
+			Selector: selector.Selector{
+				{Key: "env", Operator: selector.OpNotEquals, Values: []string{"prod"}},
+				{Key: "canary", Operator: selector.OpNotExists},
+			},
+		}
+		assert.True(t, topic.Match(&pubsub.KVChangeEvent{DomainID: "default", Project: "1",
+			Labels: map[string]string{"app": "mall", "env": "test"}}))
+		assert.True(t, topic.Match(&pubsub.KVChangeEvent{DomainID: "default", Project: "1",
+			Labels: map[string]string{"app": "mall"}}))
+		assert.False(t, topic.Match(&pubsub.KVChangeEvent{DomainID: "default", Project: "1",
+			Labels: map[string]string{"app": "mall", "env": "prod"}}))
+		assert.False(t, topic.Match(&pubsub.KVChangeEvent{DomainID: "default", Project: "1",
+			Labels: map[string]string{"app": "mall", "canary": "true"}}))
+
+		name, err := topic.Encode()
+		assert.NoError(t, err)
+		parsed, err := pubsub.ParseTopic(name)
+		assert.NoError(t, err)
+		assert.Equal(t, topic.Selector.Strings(), parsed.Selector.Strings())
+	})
+	t.Run("combination topic should match if any combination matches", func(t *testing.T) {
+		topic := &pubsub.Topic{
+			DomainID:     "default",
+			Project:      "1",
+			MatchType:    "exact",
+			Combinations: []map[string]string{{"app": "mall"}, {"app": "mall", "service": "cart"}},
+		}
+		assert.True(t, topic.Match(&pubsub.KVChangeEvent{DomainID: "default", Project: "1",
+			Labels: map[string]string{"app": "mall"}}))
+		assert.True(t, topic.Match(&pubsub.KVChangeEvent{DomainID: "default", Project: "1",
+			Labels: map[string]string{"app": "mall", "service": "cart"}}))
+		assert.False(t, topic.Match(&pubsub.KVChangeEvent{DomainID: "default", Project: "1",
+			Labels: map[string]string{"app": "mall", "service": "order"}}))
+
+		name, err := topic.Encode()
+		assert.NoError(t, err)
+		parsed, err := pubsub.ParseTopic(name)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, topic.Combinations, parsed.Combinations)
+	})
+}
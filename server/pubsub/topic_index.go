@@ -0,0 +1,209 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pubsub
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/apache/servicecomb-kie/pkg/stringutil"
+)
+
+const (
+	indexSplit = "/"
+	// maxIndexedLabels is the max label number of an event to look up the index,
+	// a kv has at most 6 labels, an event with more labels falls back to scan all topics
+	maxIndexedLabels = 8
+)
+
+// topicIndex is an inverted index from (domain, project, labels) to parsed topics.
+// a topic can only match an event when its labels are a subset of the event labels,
+// so an event looks up every subset of its labels instead of ranging all topics,
+// the cost depends on the label number of the event, not the topic number.
+// resolve topics also match events whose labels are a subset of the topic labels,
+// they are indexed by each of their labels as well, an event looks up the topics having all of its labels
+type topicIndex struct {
+	mu     sync.RWMutex
+	topics map[string]map[string]*Topic
+	// referencing indexes resolve topics by each label, and by none for events without labels
+	referencing map[string]map[string]*Topic
+}
+
+func newTopicIndex() *topicIndex {
+	return &topicIndex{
+		topics:      make(map[string]map[string]*Topic),
+		referencing: make(map[string]map[string]*Topic),
+	}
+}
+
+func (i *topicIndex) add(name string, t *Topic) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	addTo(i.topics, indexKeys(t), name, t)
+	if t.Resolve {
+		addTo(i.referencing, referenceKeys(t), name, t)
+	}
+}
+
+func (i *topicIndex) remove(name string, t *Topic) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	removeFrom(i.topics, indexKeys(t), name)
+	if t.Resolve {
+		removeFrom(i.referencing, referenceKeys(t), name)
+	}
+}
+
+func addTo(index map[string]map[string]*Topic, keys []string, name string, t *Topic) {
+	for _, k := range keys {
+		ts, ok := index[k]
+		if !ok {
+			ts = make(map[string]*Topic)
+			index[k] = ts
+		}
+		ts[name] = t
+	}
+}
+
+func removeFrom(index map[string]map[string]*Topic, keys []string, name string) {
+	for _, k := range keys {
+		ts, ok := index[k]
+		if !ok {
+			continue
+		}
+		delete(ts, name)
+		if len(ts) == 0 {
+			delete(index, k)
+		}
+	}
+}
+
+// match return names of the topics which match the event
+func (i *topicIndex) match(e *KVChangeEvent) []string {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	names := matchTopics(nil, i.referencingTopics(e), e)
+	if len(e.Labels) > maxIndexedLabels {
+		for _, ts := range i.topics {
+			names = matchTopics(names, ts, e)
+		}
+		return dedup(names)
+	}
+	for _, labels := range subsets(e.Labels) {
+		names = matchTopics(names, i.topics[indexKey(e.DomainID, e.Project, labels)], e)
+	}
+	return dedup(names)
+}
+
+// referencingTopics return resolve topics having all labels of the event, it is the smallest set of them by one label
+func (i *topicIndex) referencingTopics(e *KVChangeEvent) map[string]*Topic {
+	if len(e.Labels) == 0 {
+		return i.referencing[indexKey(e.DomainID, e.Project, stringutil.LabelNone)]
+	}
+	var smallest map[string]*Topic
+	for k, v := range e.Labels {
+		ts, ok := i.referencing[indexKey(e.DomainID, e.Project, k+"="+v)]
+		if !ok {
+			return nil
+		}
+		if smallest == nil || len(ts) < len(smallest) {
+			smallest = ts
+		}
+	}
+	return smallest
+}
+
+func matchTopics(names []string, ts map[string]*Topic, e *KVChangeEvent) []string {
+	for name, t := range ts {
+		if t.Match(e) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// subsets return the formatted string of every subset of labels, including the empty one
+func subsets(labels map[string]string) []string {
+	ls := make([]string, 0, len(labels))
+	for k, v := range labels {
+		ls = append(ls, k+"="+v)
+	}
+	sort.Strings(ls)
+	result := make([]string, 0, 1<<len(ls))
+	result = append(result, stringutil.LabelNone)
+	picked := make([]string, 0, len(ls))
+	for mask := 1; mask < 1<<len(ls); mask++ {
+		picked = picked[:0]
+		for j, l := range ls {
+			if mask&(1<<j) != 0 {
+				picked = append(picked, l)
+			}
+		}
+		result = append(result, strings.Join(picked, "::"))
+	}
+	return result
+}
+
+// indexKeys return the keys of a topic, a topic of label combinations is indexed by each of them
+func indexKeys(t *Topic) []string {
+	if len(t.Combinations) == 0 {
+		return []string{indexKey(t.DomainID, t.Project, stringutil.FormatMap(t.Labels))}
+	}
+	keys := make([]string, 0, len(t.Combinations))
+	for _, labels := range t.Combinations {
+		keys = append(keys, indexKey(t.DomainID, t.Project, stringutil.FormatMap(labels)))
+	}
+	return keys
+}
+
+// referenceKeys return the keys of a resolve topic, it is indexed by each of its labels, and by none
+func referenceKeys(t *Topic) []string {
+	keys := []string{indexKey(t.DomainID, t.Project, stringutil.LabelNone)}
+	all := t.Combinations
+	if len(all) == 0 {
+		all = []map[string]string{t.Labels}
+	}
+	for _, labels := range all {
+		for k, v := range labels {
+			keys = append(keys, indexKey(t.DomainID, t.Project, k+"="+v))
+		}
+	}
+	return keys
+}
+
+// dedup removes duplicated names, a topic of label combinations can be found by several keys
+func dedup(names []string) []string {
+	if len(names) < 2 {
+		return names
+	}
+	seen := make(map[string]struct{}, len(names))
+	result := names[:0]
+	for _, name := range names {
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		result = append(result, name)
+	}
+	return result
+}
+
+func indexKey(domain, project, labels string) string {
+	return strings.Join([]string{domain, project, labels}, indexSplit)
+}
@@ -18,6 +18,7 @@
 package v1
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
@@ -30,7 +31,10 @@ import (
 
 	"github.com/apache/servicecomb-kie/pkg/common"
 	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/cache"
 	"github.com/apache/servicecomb-kie/server/datasource"
+	"github.com/apache/servicecomb-kie/server/datasource/auth"
+	"github.com/apache/servicecomb-kie/server/pubsub"
 )
 
 type AdminResource struct {
@@ -44,7 +48,7 @@ func (r *AdminResource) URLPatterns() []restful.Route {
 			Path:         "/v1/health",
 			ResourceFunc: r.HealthCheck,
 			FuncDesc:     "health check return version and revision",
-			Parameters:   []*restful.Parameters{},
+			Parameters:   []*restful.Parameters{DocQueryProject},
 			Returns: []*restful.Returns{
 				{
 					Code:  http.StatusOK,
@@ -53,6 +57,87 @@ func (r *AdminResource) URLPatterns() []restful.Route {
 			},
 			Consumes: []string{goRestful.MIME_JSON},
 			Produces: []string{goRestful.MIME_JSON},
+		}, {
+			Method:       http.MethodGet,
+			Path:         "/v1/admin/cluster/members",
+			ResourceFunc: r.ListMembers,
+			FuncDesc:     "list nodes in the cluster with status and event stats",
+			Parameters:   []*restful.Parameters{},
+			Returns: []*restful.Returns{
+				{
+					Code:  http.StatusOK,
+					Model: model.DocClusterMembers{},
+				},
+			},
+			Produces: []string{goRestful.MIME_JSON},
+		}, {
+			Method:       http.MethodPost,
+			Path:         "/v1/admin/cluster/members",
+			ResourceFunc: r.Join,
+			FuncDesc:     "join the cluster by addresses of existing nodes",
+			Parameters: []*restful.Parameters{
+				DocHeaderContentTypeJSON,
+			},
+			Read: ClusterJoinBody{},
+			Returns: []*restful.Returns{
+				{
+					Code:  http.StatusOK,
+					Model: model.DocClusterJoin{},
+				},
+			},
+			Consumes: []string{goRestful.MIME_JSON},
+			Produces: []string{goRestful.MIME_JSON},
+		}, {
+			Method:       http.MethodGet,
+			Path:         "/v1/admin/cache",
+			ResourceFunc: r.CacheStats,
+			FuncDesc:     "show size, hits and misses of long polling cache",
+			Parameters: []*restful.Parameters{
+				DocQueryTopics,
+			},
+			Returns: []*restful.Returns{
+				{
+					Code:  http.StatusOK,
+					Model: cache.Stats{},
+				},
+			},
+			Produces: []string{goRestful.MIME_JSON},
+		}, {
+			Method:       http.MethodDelete,
+			Path:         "/v1/admin/cache",
+			ResourceFunc: r.FlushCache,
+			FuncDesc:     "flush long polling cache",
+			Returns: []*restful.Returns{
+				{
+					Code:    http.StatusNoContent,
+					Message: "flushed",
+				},
+			},
+		}, {
+			Method:       http.MethodPost,
+			Path:         "/v1/admin/cluster:leave",
+			ResourceFunc: r.Leave,
+			FuncDesc:     "leave the cluster gracefully before the local node is shut down",
+			Returns: []*restful.Returns{
+				{
+					Code:    http.StatusNoContent,
+					Message: "left",
+				},
+			},
+		}, {
+			Method:       http.MethodDelete,
+			Path:         "/v1/admin/cluster/members/{node}",
+			ResourceFunc: r.ForceLeave,
+			FuncDesc:     "remove a failed node from the cluster",
+			Parameters: []*restful.Parameters{
+				DocPathNode, DocQueryPrune,
+			},
+			Returns: []*restful.Returns{
+				{
+					Code:    http.StatusNoContent,
+					Message: "removed",
+				},
+			},
 		},
 	}
 }
@@ -71,6 +156,14 @@ func (r *AdminResource) HealthCheck(context *restful.Context) {
 		return
 	}
 	resp.Revision = strconv.FormatInt(latest, 10)
+	if project := context.ReadQueryParameter(common.QueryParamProject); project != "" {
+		latest, err = datasource.GetBroker().GetRevisionDao().GetProjectRevision(context.Ctx, project, domain)
+		if err != nil {
+			WriteErrResponse(context, config.ErrInternal, err.Error())
+			return
+		}
+		resp.ProjectRevision = strconv.FormatInt(latest, 10)
+	}
 	resp.Version = runtime.Version
 	resp.Timestamp = time.Now().Unix()
 	total, err := datasource.GetBroker().GetKVDao().Total(context.Ctx, "", domain)
@@ -84,3 +177,116 @@ func (r *AdminResource) HealthCheck(context *restful.Context) {
 		openlog.Error(err.Error())
 	}
 }
+
+// ListMembers list nodes in the cluster
+func (r *AdminResource) ListMembers(rctx *restful.Context) {
+	if err := auth.CheckAdmin(rctx.Ctx); err != nil {
+		WriteError(rctx, err)
+		return
+	}
+	members := pubsub.Members()
+	err := writeResponse(rctx, &model.DocClusterMembers{
+		Total: len(members),
+		Data:  members,
+	})
+	if err != nil {
+		openlog.Error(err.Error())
+	}
+}
+
+// Join join the cluster at runtime
+func (r *AdminResource) Join(rctx *restful.Context) {
+	if err := auth.CheckAdmin(rctx.Ctx); err != nil {
+		WriteError(rctx, err)
+		return
+	}
+	b := new(ClusterJoinBody)
+	if err := readRequest(rctx, b); err != nil {
+		WriteErrResponse(rctx, config.ErrInvalidParams, fmt.Sprintf(FmtReadRequestError, err))
+		return
+	}
+	if len(b.Addresses) == 0 {
+		WriteErrResponse(rctx, config.ErrInvalidParams, "addresses can not be empty")
+		return
+	}
+	joined, err := pubsub.Join(b.Addresses)
+	if err != nil {
+		openlog.Error(fmt.Sprintf("join %v failed: %s", b.Addresses, err))
+		WriteErrResponse(rctx, config.ErrInternal, err.Error())
+		return
+	}
+	openlog.Info(fmt.Sprintf("join kie nodes: %v", b.Addresses))
+	err = writeResponse(rctx, &model.DocClusterJoin{Joined: joined})
+	if err != nil {
+		openlog.Error(err.Error())
+	}
+}
+
+// Leave the local node leaves the cluster gracefully
+func (r *AdminResource) Leave(rctx *restful.Context) {
+	if err := auth.CheckAdmin(rctx.Ctx); err != nil {
+		WriteError(rctx, err)
+		return
+	}
+	if err := pubsub.Leave(); err != nil {
+		if err == pubsub.ErrNotAlive {
+			WriteErrResponse(rctx, config.ErrInvalidParams, err.Error())
+			return
+		}
+		openlog.Error("leave the cluster failed: " + err.Error())
+		WriteErrResponse(rctx, config.ErrInternal, err.Error())
+		return
+	}
+	openlog.Info("the local kie node left the cluster")
+	rctx.WriteHeader(http.StatusNoContent)
+}
+
+// ForceLeave remove a failed node from the cluster
+func (r *AdminResource) ForceLeave(rctx *restful.Context) {
+	if err := auth.CheckAdmin(rctx.Ctx); err != nil {
+		WriteError(rctx, err)
+		return
+	}
+	node := rctx.ReadPathParameter(common.PathParamNode)
+	prune := rctx.ReadQueryParameter(common.QueryParamPrune) == "true"
+	err := pubsub.ForceLeave(node, prune)
+	if err != nil {
+		if err == pubsub.ErrRemoveLocalNode {
+			WriteErrResponse(rctx, config.ErrInvalidParams, err.Error())
+			return
+		}
+		if err == pubsub.ErrNodeNotExists {
+			WriteErrResponse(rctx, config.ErrRecordNotExists, err.Error())
+			return
+		}
+		openlog.Error(fmt.Sprintf("remove node [%s] failed: %s", node, err))
+		WriteErrResponse(rctx, config.ErrInternal, err.Error())
+		return
+	}
+	openlog.Info(fmt.Sprintf("remove kie node: %s", node))
+	rctx.WriteHeader(http.StatusNoContent)
+}
+
+// CacheStats show the long polling cache
+func (r *AdminResource) CacheStats(rctx *restful.Context) {
+	if err := auth.CheckAdmin(rctx.Ctx); err != nil {
+		WriteError(rctx, err)
+		return
+	}
+	withTopics := rctx.ReadQueryParameter(common.QueryParamTopics) == "true"
+	err := writeResponse(rctx, cache.CachedKV().Stats(withTopics))
+	if err != nil {
+		openlog.Error(err.Error())
+	}
+}
+
+// FlushCache remove all results in long polling cache
+func (r *AdminResource) FlushCache(rctx *restful.Context) {
+	if err := auth.CheckAdmin(rctx.Ctx); err != nil {
+		WriteError(rctx, err)
+		return
+	}
+	cache.CachedKV().Flush()
+	openlog.Info("long polling cache is flushed")
+	rctx.WriteHeader(http.StatusNoContent)
+}
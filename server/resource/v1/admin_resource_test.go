@@ -23,6 +23,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	_ "github.com/apache/servicecomb-kie/test"
@@ -31,6 +32,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/cache"
 	v1 "github.com/apache/servicecomb-kie/server/resource/v1"
 )
 
@@ -62,3 +64,65 @@ func Test_HeakthCheckLiveMode(t *testing.T) {
 	respcode := resp.Code
 	assert.NotEmpty(t, respcode)
 }
+
+func TestAdminResource_ListMembers(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/v1/admin/cluster/members", nil)
+	c, err := restfultest.New(&v1.AdminResource{}, nil)
+	assert.NoError(t, err)
+	resp := httptest.NewRecorder()
+	c.ServeHTTP(resp, r)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	data := &model.DocClusterMembers{}
+	err = json.Unmarshal(resp.Body.Bytes(), data)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, data.Total)
+	assert.True(t, data.Data[0].Local)
+	assert.Equal(t, "alive", data.Data[0].Status)
+
+	t.Run("remove the local node, should be rejected", func(t *testing.T) {
+		r, _ := http.NewRequest("DELETE", "/v1/admin/cluster/members/"+data.Data[0].Name, nil)
+		resp := httptest.NewRecorder()
+		c.ServeHTTP(resp, r)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+	t.Run("remove a node not exist, should return 404", func(t *testing.T) {
+		r, _ := http.NewRequest("DELETE", "/v1/admin/cluster/members/not-exist", nil)
+		resp := httptest.NewRecorder()
+		c.ServeHTTP(resp, r)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
+
+func TestAdminResource_Join(t *testing.T) {
+	c, err := restfultest.New(&v1.AdminResource{}, nil)
+	assert.NoError(t, err)
+	t.Run("join without addresses, should be rejected", func(t *testing.T) {
+		r, _ := http.NewRequest("POST", "/v1/admin/cluster/members", strings.NewReader(`{"addresses":[]}`))
+		r.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		c.ServeHTTP(resp, r)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
+
+func TestAdminResource_Cache(t *testing.T) {
+	c, err := restfultest.New(&v1.AdminResource{}, nil)
+	assert.NoError(t, err)
+	t.Run("flush cache", func(t *testing.T) {
+		r, _ := http.NewRequest("DELETE", "/v1/admin/cache", nil)
+		resp := httptest.NewRecorder()
+		c.ServeHTTP(resp, r)
+		assert.Equal(t, http.StatusNoContent, resp.Code)
+	})
+	t.Run("get stats, cache should be empty", func(t *testing.T) {
+		r, _ := http.NewRequest("GET", "/v1/admin/cache?topics=true", nil)
+		resp := httptest.NewRecorder()
+		c.ServeHTTP(resp, r)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		s := &cache.Stats{}
+		err := json.Unmarshal(resp.Body.Bytes(), s)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, s.Size)
+		assert.Equal(t, cache.DefaultSize, s.MaxSize)
+	})
+}
@@ -32,11 +32,13 @@ import (
 	"github.com/gofrs/uuid"
 
 	"github.com/apache/servicecomb-kie/server/datasource"
+	"github.com/apache/servicecomb-kie/server/datasource/auth"
 	kvsvc "github.com/apache/servicecomb-kie/server/service/kv"
 	"github.com/go-chassis/cari/rbac"
 
 	"github.com/apache/servicecomb-kie/pkg/common"
 	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/pkg/selector"
 	"github.com/apache/servicecomb-kie/server/pubsub"
 	goRestful "github.com/emicklei/go-restful"
 	"github.com/go-chassis/cari/config"
@@ -171,27 +173,41 @@ func writeResponse(ctx *restful.Context, v interface{}) error {
 	}
 	return ctx.WriteJSON(v, goRestful.MIME_JSON) // json is default
 }
-func getLabels(rctx *restful.Context) (map[string]string, error) {
-	labelSlice := rctx.Req.QueryParameters(common.QueryParamLabel)
+
+// getLabels parse label query params, equality requirements are returned as labels,
+// others like env!=prod are returned as selector
+func getLabels(rctx *restful.Context) (map[string]string, selector.Selector, error) {
+	return parseLabels(rctx.Req.QueryParameters(common.QueryParamLabel))
+}
+
+// parseLabels parse label requirements like app:mall or env!=prod
+func parseLabels(labelSlice []string) (map[string]string, selector.Selector, error) {
 	if len(labelSlice) == 0 {
-		return nil, nil
+		return nil, nil, nil
 	}
 	labels := make(map[string]string, len(labelSlice))
+	var s selector.Selector
 	for _, v := range labelSlice {
-		v := strings.Split(v, ":")
-		if len(v) != 2 {
-			return nil, errors.New(common.MsgIllegalLabels)
+		r, err := selector.ParseRequirement(v)
+		if err != nil {
+			return nil, nil, errors.New(common.MsgIllegalLabels)
 		}
-		labels[v[0]] = v[1]
+		if r.Operator == selector.OpEquals {
+			labels[r.Key] = r.Values[0]
+			continue
+		}
+		s = append(s, r)
 	}
-	return labels, nil
+	return labels, s, nil
 }
-func revNotMatch(ctx context.Context, revStr, domain string) (bool, error) {
+
+// revNotMatch compares with the project revision, so that writes to other projects do not wake clients up
+func revNotMatch(ctx context.Context, revStr, project, domain string) (bool, error) {
 	rev, err := strconv.ParseInt(revStr, 10, 64)
 	if err != nil {
 		return false, ErrInvalidRev
 	}
-	latest, err := datasource.GetBroker().GetRevisionDao().GetRevision(ctx, domain)
+	latest, err := datasource.GetBroker().GetRevisionDao().GetProjectRevision(ctx, project, domain)
 	if err != nil {
 		return false, err
 	}
@@ -210,7 +226,7 @@ func getMatchPattern(rctx *restful.Context) string {
 	}
 	return m
 }
-func eventHappened(waitStr string, topic *pubsub.Topic, ctx context.Context) (bool, string, error) {
+func eventHappened(waitStr string, topic *pubsub.Topic) (bool, string, error) {
 	d, err := time.ParseDuration(waitStr)
 	if err != nil || d > common.MaxWait {
 		return false, "", errors.New(common.MsgInvalidWait)
@@ -230,7 +246,6 @@ func eventHappened(waitStr string, topic *pubsub.Topic, ctx context.Context) (bo
 		happened = false
 		pubsub.RemoveObserver(o.UUID, topic)
 	case <-o.Event:
-		prepareCache(topicName, topic, ctx)
 	}
 	return happened, topicName, nil
 }
@@ -258,6 +273,19 @@ func checkPagination(offsetStr, limitStr string) (int64, int64, error) {
 	return offset, limit, err
 }
 
+// readUnixTime read a query param of unix seconds, 0 if it is absent
+func readUnixTime(rctx *restful.Context, name string) (int64, error) {
+	v := rctx.ReadQueryParameter(name)
+	if v == "" {
+		return 0, nil
+	}
+	t, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || t < 0 {
+		return 0, errors.New("invalid " + name + ", it must be unix seconds")
+	}
+	return t, nil
+}
+
 func validateGet(domain, project, kvID string) error {
 	if kvID == "" {
 		return ErrIDIsNil
@@ -282,15 +310,15 @@ func checkDomainAndProject(domain, project string) error {
 	}
 	return nil
 }
-func queryFromCache(rctx *restful.Context, topic string) {
-	rev, kv, queryErr := cache.CachedKV().Read(topic)
-	if queryErr != nil {
-		WriteErrResponse(rctx, queryErr.Code, queryErr.Message)
+func queryFromCache(rctx *restful.Context, topicName string, topic *pubsub.Topic) {
+	r := prepareCache(rctx.Ctx, topicName, topic)
+	if r.Err != nil {
+		WriteErrResponse(rctx, r.Err.Code, r.Err.Message)
 		return
 	}
-	rctx.ReadResponseWriter().Header().Set(common.HeaderRevision, strconv.FormatInt(rev, 10))
-	err := writeResponse(rctx, kv)
-	rctx.ReadRestfulRequest().SetAttribute(common.RespBodyContextKey, kv.Data)
+	rctx.ReadResponseWriter().Header().Set(common.HeaderRevision, strconv.FormatInt(r.Rev, 10))
+	err := writeResponse(rctx, r.KVs)
+	rctx.ReadRestfulRequest().SetAttribute(common.RespBodyContextKey, r.KVs.Data)
 	if err != nil {
 		openlog.Error(err.Error())
 	}
@@ -309,19 +337,46 @@ func queryAndResponse(rctx *restful.Context, request *model.ListKVRequest) {
 	}
 }
 
-func prepareCache(topicName string, topic *pubsub.Topic, ctx context.Context) {
-	rev, kvs, err := kvsvc.ListKV(ctx, &model.ListKVRequest{
-		Domain:  topic.DomainID,
-		Project: topic.Project,
-		Labels:  topic.Labels,
-		Match:   topic.MatchType,
-	})
-	if err != nil {
-		openlog.Error("can not query kvs:" + err.Error())
+// prepareCache return the cached result of the topic,
+// only query db if it is not cached or the revision has moved on
+func prepareCache(ctx context.Context, topicName string, topic *pubsub.Topic) *cache.DBResult {
+	key := cacheKey(ctx, topicName)
+	latest, err := datasource.GetBroker().GetRevisionDao().GetProjectRevision(ctx, topic.Project, topic.DomainID)
+	if err == nil {
+		if r, ok := cache.CachedKV().Read(key, latest); ok {
+			return r
+		}
 	}
-	cache.CachedKV().Write(topicName, &cache.DBResult{
+	rev, kvs, svcErr := kvsvc.ListKV(ctx, &model.ListKVRequest{
+		Domain:       topic.DomainID,
+		Project:      topic.Project,
+		Labels:       topic.Labels,
+		Selector:     topic.Selector,
+		Combinations: topic.Combinations,
+		Match:        topic.MatchType,
+		Resolve:      topic.Resolve,
+	})
+	r := &cache.DBResult{
 		KVs: kvs,
 		Rev: rev,
-		Err: err,
-	})
+		Err: svcErr,
+	}
+	if svcErr != nil {
+		openlog.Error("can not query kvs:" + svcErr.Error())
+		return r
+	}
+	cache.CachedKV().Write(key, r)
+	return r
+}
+
+// cacheKey separates results of different accounts, because results are filtered by permissions
+func cacheKey(ctx context.Context, topicName string) string {
+	if !auth.CheckEnable(ctx) {
+		return topicName
+	}
+	account, err := rbac.AccountFromContext(ctx)
+	if err != nil {
+		return topicName
+	}
+	return topicName + "::" + account.Name
 }
@@ -67,7 +67,8 @@ var (
 		ParamType: goRestful.QueryParameterKind,
 		Desc: "the combination format is {label_key}:{label_value}+{label_key}:{label_value} " +
 			"for example: /v1/test/kie/kv?q=app:mall&q=app:mall+service:cart, " +
-			"that will query key values from 2 kinds of labels",
+			"that will query key values from 2 kinds of labels, the result is the union, " +
+			"and \"matched\" tells which combinations each key value matched",
 	}
 	DocQueryWait = &restful.Parameters{
 		DataType:  "string",
@@ -108,13 +109,103 @@ var (
 		DataType:  "string",
 		Name:      "label",
 		ParamType: goRestful.QueryParameterKind,
-		Desc:      "label pairs,for example &label=service:order&label=version:1.0.0",
+		Desc: "label pairs,for example &label=service:order&label=version:1.0.0, " +
+			"and support selectors: env!=prod, version in (1.0,1.1), version notin (1.0), region (key exists), !canary (key does not exist)",
+	}
+	DocQuerySearchKey = &restful.Parameters{
+		DataType:  "string",
+		Name:      common.QueryParamKey,
+		ParamType: goRestful.QueryParameterKind,
+		Desc:      "regular expression of key, in RE2 syntax without nested repetitions, 128 bytes at most",
+	}
+	DocQuerySearchValue = &restful.Parameters{
+		DataType:  "string",
+		Name:      common.QueryParamValue,
+		ParamType: goRestful.QueryParameterKind,
+		Desc:      "regular expression of value, in RE2 syntax without nested repetitions, 128 bytes at most",
+	}
+	DocQueryIgnoreCase = &restful.Parameters{
+		DataType:  "boolean",
+		Name:      common.QueryParamIgnoreCase,
+		ParamType: goRestful.QueryParameterKind,
+		Desc:      "match key and value case insensitively",
+	}
+	DocQueryValueType = &restful.Parameters{
+		DataType:  "string",
+		Name:      common.QueryParamValueType,
+		ParamType: goRestful.QueryParameterKind,
+	}
+	DocQueryUpdatedAfter = &restful.Parameters{
+		DataType:  "integer",
+		Name:      common.QueryParamUpdatedAfter,
+		ParamType: goRestful.QueryParameterKind,
+		Desc:      "unix seconds, only return key values updated at or after it",
+	}
+	DocQueryUpdatedBefore = &restful.Parameters{
+		DataType:  "integer",
+		Name:      common.QueryParamUpdatedBefore,
+		ParamType: goRestful.QueryParameterKind,
+		Desc:      "unix seconds, only return key values updated at or before it",
 	}
 	DocQueryStatusParameters = &restful.Parameters{
 		DataType:  "string",
 		Name:      common.QueryParamStatus,
 		ParamType: goRestful.QueryParameterKind,
 	}
+	DocQueryPrefix = &restful.Parameters{
+		DataType:  "string",
+		Name:      common.QueryParamPrefix,
+		ParamType: goRestful.QueryParameterKind,
+		Desc:      "prefix of keys, e.g. servicecomb.rest.",
+	}
+	DocQueryDelimiter = &restful.Parameters{
+		DataType:  "string",
+		Name:      common.QueryParamDelimiter,
+		ParamType: goRestful.QueryParameterKind,
+		Desc:      "one of . _ : -, the default is .",
+	}
+	DocQueryOrphan = &restful.Parameters{
+		DataType:  "boolean",
+		Name:      common.QueryParamOrphan,
+		ParamType: goRestful.QueryParameterKind,
+		Desc:      "only return label sets no key value uses",
+	}
+	DocQueryOverride = &restful.Parameters{
+		DataType:  "string",
+		Name:      common.QueryParamOverride,
+		ParamType: goRestful.QueryParameterKind,
+		Desc:      "abort, skip or force, what to do if the key already exists in the target label set, default is abort",
+	}
+	DocQueryDryRun = &restful.Parameters{
+		DataType:  "boolean",
+		Name:      common.QueryParamDryRun,
+		ParamType: goRestful.QueryParameterKind,
+		Desc:      "preview the changes without persisting them",
+	}
+	DocQueryAtomic = &restful.Parameters{
+		DataType:  "boolean",
+		Name:      common.QueryParamAtomic,
+		ParamType: goRestful.QueryParameterKind,
+		Desc:      "write all key values in one transaction, or nothing",
+	}
+	DocQueryProject = &restful.Parameters{
+		DataType:  "string",
+		Name:      common.QueryParamProject,
+		ParamType: goRestful.QueryParameterKind,
+		Desc:      "also return the revision of the project",
+	}
+	DocQuerySort = &restful.Parameters{
+		DataType:  "string",
+		Name:      common.QueryParamSort,
+		ParamType: goRestful.QueryParameterKind,
+		Desc:      "sort by key, update_time, update_revision or priority, prefix it with - in descending order, backends other than mongodb scan the whole project for every page",
+	}
+	DocQueryCursor = &restful.Parameters{
+		DataType:  "string",
+		Name:      common.QueryParamCursor,
+		ParamType: goRestful.QueryParameterKind,
+		Desc:      "the next_cursor of the last page, can not be used with offset",
+	}
 	DocQueryLimitParameters = &restful.Parameters{
 		DataType:  "string",
 		Name:      common.QueryParamLimit,
@@ -152,6 +243,24 @@ var (
 		ParamType: goRestful.QueryParameterKind,
 		Desc:      "user agent of the call",
 	}
+	DocQueryPrune = &restful.Parameters{
+		DataType:  "bool",
+		Name:      common.QueryParamPrune,
+		ParamType: goRestful.QueryParameterKind,
+		Desc:      "erase the node from member list immediately",
+	}
+	DocQueryResolve = &restful.Parameters{
+		DataType:  "bool",
+		Name:      common.QueryParamResolve,
+		ParamType: goRestful.QueryParameterKind,
+		Desc:      "replace references like ${db.host} in values with values of the referenced keys",
+	}
+	DocQueryTopics = &restful.Parameters{
+		DataType:  "bool",
+		Name:      common.QueryParamTopics,
+		ParamType: goRestful.QueryParameterKind,
+		Desc:      "list cached topics",
+	}
 )
 
 // swagger doc path params
@@ -168,6 +277,24 @@ var (
 		ParamType: goRestful.PathParameterKind,
 		Required:  true,
 	}
+	DocPathNode = &restful.Parameters{
+		DataType:  "string",
+		Name:      common.PathParamNode,
+		ParamType: goRestful.PathParameterKind,
+		Required:  true,
+	}
+	DocPathSchemaID = &restful.Parameters{
+		DataType:  "string",
+		Name:      common.PathParamSchemaID,
+		ParamType: goRestful.PathParameterKind,
+		Required:  true,
+	}
+	DocPathLabelSetID = &restful.Parameters{
+		DataType:  "string",
+		Name:      common.PathParamLabelSetID,
+		ParamType: goRestful.PathParameterKind,
+		Required:  true,
+	}
 )
 
 // KVCreateBody is open api doc
@@ -200,12 +327,70 @@ type KVUpdateBody struct {
 	Value  string `json:"value"`
 }
 
+// KVRelabelBody is open api doc
+type KVRelabelBody struct {
+	Labels map[string]string `json:"labels"`
+}
+
+// KVCopyBody is open api doc, labels select the source kvs the same as the label query params
+type KVCopyBody struct {
+	Key           string            `json:"key"`
+	Labels        []string          `json:"labels"`
+	Status        string            `json:"status"`
+	TargetProject string            `json:"target_project"`
+	Rewrite       map[string]string `json:"rewrite"`
+}
+
+// TxnBody is open api doc
+type TxnBody struct {
+	Ops []*model.TxnOp `json:"ops"`
+}
+
+// BulkUpdateBody is open api doc, kvs are selected by ids, or by key and labels
+type BulkUpdateBody struct {
+	IDs    []string      `json:"ids,omitempty"`
+	Key    string        `json:"key,omitempty"`
+	Labels []string      `json:"labels,omitempty"`
+	Set    model.KVPatch `json:"set"`
+}
+
 // DeleteBody is the request body struct of delete multiple kvs interface
 type DeleteBody struct {
 	IDs []string `json:"ids"`
 }
 
+// ClusterJoinBody is open api doc
+type ClusterJoinBody struct {
+	Addresses []string `json:"addresses"`
+}
+
 // ErrorMsg is open api doc
 type ErrorMsg struct {
 	Msg string `json:"error_msg"`
 }
+
+// SchemaCreateBody is open api doc
+type SchemaCreateBody struct {
+	Key    string `json:"key"`
+	Schema string `json:"schema"`
+}
+
+// SchemaUpdateBody is open api doc
+type SchemaUpdateBody struct {
+	Schema string `json:"schema"`
+}
+
+// LabelAliasBody is open api doc
+type LabelAliasBody struct {
+	Labels map[string]string `json:"labels"`
+	Alias  string            `json:"alias"`
+}
+
+// CheckerTestBody is open api doc
+type CheckerTestBody struct {
+	Check     string            `json:"check"`
+	Key       string            `json:"key"`
+	ValueType string            `json:"value_type"`
+	Labels    map[string]string `json:"labels"`
+	Values    []string          `json:"values"`
+}
@@ -23,10 +23,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	goRestful "github.com/emicklei/go-restful"
 	"github.com/go-chassis/cari/config"
+	"github.com/go-chassis/cari/pkg/errsvc"
 	"github.com/go-chassis/foundation/validator"
 	"github.com/go-chassis/go-chassis/v2/server/restful"
 	"github.com/go-chassis/openlog"
@@ -35,6 +37,7 @@ import (
 	"github.com/apache/servicecomb-kie/pkg/model"
 	"github.com/apache/servicecomb-kie/server/datasource"
 	"github.com/apache/servicecomb-kie/server/pubsub"
+	"github.com/apache/servicecomb-kie/server/service/checker"
 	kvsvc "github.com/apache/servicecomb-kie/server/service/kv"
 )
 
@@ -59,6 +62,8 @@ func (r *KVResource) Upload(rctx *restful.Context) {
 		Project:  rctx.ReadPathParameter(common.PathParameterProject),
 		KVs:      inputUpload.Data,
 		Override: rctx.ReadQueryParameter(common.QueryParamOverride),
+		DryRun:   rctx.ReadQueryParameter(common.QueryParamDryRun) == "true",
+		Atomic:   rctx.ReadQueryParameter(common.QueryParamAtomic) == "true",
 	})
 	err = writeResponse(rctx, result)
 	if err != nil {
@@ -66,6 +71,235 @@ func (r *KVResource) Upload(rctx *restful.Context) {
 	}
 }
 
+// Validate checks kvs as uploading them without persisting them
+func (r *KVResource) Validate(rctx *restful.Context) {
+	inputUpload := new(KVUploadBody)
+	if err := readRequest(rctx, &inputUpload); err != nil {
+		WriteErrResponse(rctx, config.ErrInvalidParams, fmt.Sprintf(FmtReadRequestError, err))
+		return
+	}
+	result := kvsvc.Validate(rctx.Ctx, &model.UploadKVRequest{
+		Domain:  ReadDomain(rctx.Ctx),
+		Project: rctx.ReadPathParameter(common.PathParameterProject),
+		KVs:     inputUpload.Data,
+	})
+	err := writeResponse(rctx, result)
+	if err != nil {
+		openlog.Error(err.Error())
+	}
+}
+
+// Check tries the checker against sample values
+func (r *KVResource) Check(rctx *restful.Context) {
+	body := new(CheckerTestBody)
+	if err := readRequest(rctx, body); err != nil {
+		WriteErrResponse(rctx, config.ErrInvalidParams, fmt.Sprintf(FmtReadRequestError, err))
+		return
+	}
+	if body.Check == "" {
+		WriteErrResponse(rctx, config.ErrInvalidParams, "check is required")
+		return
+	}
+	if _, err := checker.Compile(body.Check); err != nil {
+		WriteErrResponse(rctx, config.ErrInvalidParams, err.Error())
+		return
+	}
+	result := &model.DocRespOfCheck{Results: make([]*model.CheckResult, 0, len(body.Values))}
+	for _, v := range body.Values {
+		r := &model.CheckResult{Value: v, Passed: true}
+		err := checker.Check(&model.KVDoc{
+			Key:       body.Key,
+			Value:     v,
+			ValueType: body.ValueType,
+			Labels:    body.Labels,
+			Checker:   body.Check,
+		})
+		if err != nil {
+			r.Passed = false
+			r.Reason = err.Error()
+		}
+		result.Results = append(result.Results, r)
+	}
+	err := writeResponse(rctx, result)
+	if err != nil {
+		openlog.Error(err.Error())
+	}
+}
+
+// Search finds kvs by regular expressions of key and value
+func (r *KVResource) Search(rctx *restful.Context) {
+	request := &model.SearchKVRequest{
+		Project:    rctx.ReadPathParameter(common.PathParameterProject),
+		Domain:     ReadDomain(rctx.Ctx),
+		Key:        rctx.ReadQueryParameter(common.QueryParamKey),
+		Value:      rctx.ReadQueryParameter(common.QueryParamValue),
+		IgnoreCase: rctx.ReadQueryParameter(common.QueryParamIgnoreCase) == "true",
+		ValueType:  rctx.ReadQueryParameter(common.QueryParamValueType),
+		Status:     rctx.ReadQueryParameter(common.QueryParamStatus),
+	}
+	labels, s, err := getLabels(rctx)
+	if err != nil {
+		WriteErrResponse(rctx, config.ErrInvalidParams, common.MsgIllegalLabels)
+		return
+	}
+	request.Labels = labels
+	request.Selector = s
+	request.UpdatedAfter, err = readUnixTime(rctx, common.QueryParamUpdatedAfter)
+	if err != nil {
+		WriteErrResponse(rctx, config.ErrInvalidParams, err.Error())
+		return
+	}
+	request.UpdatedBefore, err = readUnixTime(rctx, common.QueryParamUpdatedBefore)
+	if err != nil {
+		WriteErrResponse(rctx, config.ErrInvalidParams, err.Error())
+		return
+	}
+	request.Offset, request.Limit, err = checkPagination(rctx.ReadQueryParameter(common.QueryParamOffset),
+		rctx.ReadQueryParameter(common.QueryParamLimit))
+	if err != nil {
+		WriteErrResponse(rctx, config.ErrInvalidParams, err.Error())
+		return
+	}
+	err = validator.Validate(request)
+	if err != nil {
+		WriteErrResponse(rctx, config.ErrInvalidParams, err.Error())
+		return
+	}
+	result, svcErr := kvsvc.Search(rctx.Ctx, request)
+	if svcErr != nil {
+		WriteErrResponse(rctx, svcErr.Code, svcErr.Error())
+		return
+	}
+	err = writeResponse(rctx, result)
+	if err != nil {
+		openlog.Error(err.Error())
+	}
+}
+
+// Tree lists the child segments of keys under the prefix
+func (r *KVResource) Tree(rctx *restful.Context) {
+	request := &model.KeyTreeRequest{
+		Project:   rctx.ReadPathParameter(common.PathParameterProject),
+		Domain:    ReadDomain(rctx.Ctx),
+		Prefix:    rctx.ReadQueryParameter(common.QueryParamPrefix),
+		Delimiter: rctx.ReadQueryParameter(common.QueryParamDelimiter),
+		Status:    rctx.ReadQueryParameter(common.QueryParamStatus),
+	}
+	if request.Delimiter == "" {
+		request.Delimiter = kvsvc.DefaultDelimiter
+	}
+	labels, s, err := getLabels(rctx)
+	if err != nil {
+		WriteErrResponse(rctx, config.ErrInvalidParams, common.MsgIllegalLabels)
+		return
+	}
+	request.Labels = labels
+	request.Selector = s
+	err = validator.Validate(request)
+	if err != nil {
+		WriteErrResponse(rctx, config.ErrInvalidParams, err.Error())
+		return
+	}
+	result, svcErr := kvsvc.Tree(rctx.Ctx, request)
+	if svcErr != nil {
+		WriteErrResponse(rctx, svcErr.Code, svcErr.Error())
+		return
+	}
+	err = writeResponse(rctx, result)
+	if err != nil {
+		openlog.Error(err.Error())
+	}
+}
+
+// Copy copies kvs to another project or label set
+func (r *KVResource) Copy(rctx *restful.Context) {
+	body := new(KVCopyBody)
+	if err := readRequest(rctx, body); err != nil {
+		WriteErrResponse(rctx, config.ErrInvalidParams, fmt.Sprintf(FmtReadRequestError, err))
+		return
+	}
+	labels, s, err := parseLabels(body.Labels)
+	if err != nil {
+		WriteErrResponse(rctx, config.ErrInvalidParams, err.Error())
+		return
+	}
+	result, svcErr := kvsvc.Copy(rctx.Ctx, &model.CopyKVRequest{
+		Domain:        ReadDomain(rctx.Ctx),
+		Project:       rctx.ReadPathParameter(common.PathParameterProject),
+		Key:           body.Key,
+		Labels:        labels,
+		Selector:      s,
+		Status:        body.Status,
+		TargetProject: body.TargetProject,
+		Rewrite:       body.Rewrite,
+		Override:      rctx.ReadQueryParameter(common.QueryParamOverride),
+		DryRun:        rctx.ReadQueryParameter(common.QueryParamDryRun) == "true",
+	})
+	if svcErr != nil {
+		WriteErrResponse(rctx, svcErr.Code, svcErr.Error())
+		return
+	}
+	err = writeResponse(rctx, result)
+	if err != nil {
+		openlog.Error(err.Error())
+	}
+}
+
+// Txn applies kv operations atomically
+func (r *KVResource) Txn(rctx *restful.Context) {
+	body := new(TxnBody)
+	if err := readRequest(rctx, body); err != nil {
+		WriteErrResponse(rctx, config.ErrInvalidParams, fmt.Sprintf(FmtReadRequestError, err))
+		return
+	}
+	result, svcErr := kvsvc.Txn(rctx.Ctx, &model.TxnRequest{
+		Domain:  ReadDomain(rctx.Ctx),
+		Project: rctx.ReadPathParameter(common.PathParameterProject),
+		Ops:     body.Ops,
+	})
+	if svcErr != nil {
+		WriteErrResponse(rctx, svcErr.Code, svcErr.Error())
+		return
+	}
+	rctx.ReadResponseWriter().Header().Set(common.HeaderRevision, strconv.FormatInt(result.Revision, 10))
+	err := writeResponse(rctx, result)
+	if err != nil {
+		openlog.Error(err.Error())
+	}
+}
+
+// BulkUpdate patches the kvs selected by ids, or by key and labels
+func (r *KVResource) BulkUpdate(rctx *restful.Context) {
+	body := new(BulkUpdateBody)
+	if err := readRequest(rctx, body); err != nil {
+		WriteErrResponse(rctx, config.ErrInvalidParams, fmt.Sprintf(FmtReadRequestError, err))
+		return
+	}
+	labels, s, err := parseLabels(body.Labels)
+	if err != nil {
+		WriteErrResponse(rctx, config.ErrInvalidParams, err.Error())
+		return
+	}
+	result, svcErr := kvsvc.BulkUpdate(rctx.Ctx, &model.BulkUpdateKVRequest{
+		Domain:   ReadDomain(rctx.Ctx),
+		Project:  rctx.ReadPathParameter(common.PathParameterProject),
+		IDs:      body.IDs,
+		Key:      body.Key,
+		Labels:   labels,
+		Selector: s,
+		Set:      body.Set,
+		DryRun:   rctx.ReadQueryParameter(common.QueryParamDryRun) == "true",
+	})
+	if svcErr != nil {
+		WriteErrResponse(rctx, svcErr.Code, svcErr.Error())
+		return
+	}
+	err = writeResponse(rctx, result)
+	if err != nil {
+		openlog.Error(err.Error())
+	}
+}
+
 // Post create a kv
 func (r *KVResource) Post(rctx *restful.Context) {
 	var err error
@@ -82,7 +316,6 @@ func (r *KVResource) Post(rctx *restful.Context) {
 		WriteErrResponse(rctx, postErr.Code, postErr.Error())
 		return
 	}
-	kvsvc.Publish(kv)
 	err = writeResponse(rctx, kv)
 	if err != nil {
 		openlog.Error(err.Error())
@@ -116,19 +349,14 @@ func (r *KVResource) Put(rctx *restful.Context) {
 			WriteErrResponse(rctx, config.ErrRecordNotExists, err.Error())
 			return
 		}
+		if errsvc.IsErrEqualCode(err, config.ErrInvalidParams) {
+			// values rejected by value type, schema or checker, tell the reason
+			WriteErrResponse(rctx, config.ErrInvalidParams, err.Error())
+			return
+		}
 		WriteError(rctx, err)
 		return
 	}
-	err = pubsub.Publish(&pubsub.KVChangeEvent{
-		Key:      kv.Key,
-		Labels:   kv.Labels,
-		Project:  project,
-		DomainID: kv.Domain,
-		Action:   pubsub.ActionPut,
-	})
-	if err != nil {
-		openlog.Warn("lost kv change event when put:" + err.Error())
-	}
 	openlog.Info(
 		fmt.Sprintf("put [%s] success", kvID))
 	err = writeResponse(rctx, kv)
@@ -138,6 +366,30 @@ func (r *KVResource) Put(rctx *restful.Context) {
 
 }
 
+// Relabel moves a kv to another label set
+func (r *KVResource) Relabel(rctx *restful.Context) {
+	body := new(KVRelabelBody)
+	if err := readRequest(rctx, body); err != nil {
+		WriteErrResponse(rctx, config.ErrInvalidParams, fmt.Sprintf(FmtReadRequestError, err))
+		return
+	}
+	kv, svcErr := kvsvc.Relabel(rctx.Ctx, &model.RelabelKVRequest{
+		ID:       rctx.ReadPathParameter(common.PathParamKVID),
+		Domain:   ReadDomain(rctx.Ctx),
+		Project:  rctx.ReadPathParameter(common.PathParameterProject),
+		Labels:   body.Labels,
+		Override: rctx.ReadQueryParameter(common.QueryParamOverride),
+	})
+	if svcErr != nil {
+		WriteErrResponse(rctx, svcErr.Code, svcErr.Error())
+		return
+	}
+	err := writeResponse(rctx, kv)
+	if err != nil {
+		openlog.Error(err.Error())
+	}
+}
+
 // Get search key by kv id
 func (r *KVResource) Get(rctx *restful.Context) {
 	request := &model.GetKVRequest{
@@ -160,6 +412,14 @@ func (r *KVResource) Get(rctx *restful.Context) {
 		WriteError(rctx, err)
 		return
 	}
+	if rctx.ReadQueryParameter(common.QueryParamResolve) == "true" {
+		resolved, err := kvsvc.Resolve(rctx.Ctx, request.Domain, request.Project, []*model.KVDoc{kv})
+		if err != nil {
+			WriteErrResponse(rctx, config.ErrInternal, common.MsgDBError)
+			return
+		}
+		kv = resolved[0]
+	}
 	kv.Domain = ""
 	kv.Project = ""
 	err = writeResponse(rctx, kv)
@@ -178,13 +438,26 @@ func (r *KVResource) List(rctx *restful.Context) {
 		Value:   rctx.ReadQueryParameter(common.QueryParamValue),
 		Status:  rctx.ReadQueryParameter(common.QueryParamStatus),
 		Match:   getMatchPattern(rctx),
+		Resolve: rctx.ReadQueryParameter(common.QueryParamResolve) == "true",
 	}
-	labels, err := getLabels(rctx)
+	labels, s, err := getLabels(rctx)
 	if err != nil {
 		WriteErrResponse(rctx, config.ErrInvalidParams, common.MsgIllegalLabels)
 		return
 	}
 	request.Labels = labels
+	request.Selector = s
+	if len(rctx.Req.QueryParameters(common.QueryParamQ)) != 0 {
+		if len(labels) != 0 {
+			WriteErrResponse(rctx, config.ErrInvalidParams, "can not accept label pairs, when using q")
+			return
+		}
+		request.Combinations, err = ReadLabelCombinations(rctx.Req)
+		if err != nil {
+			WriteErrResponse(rctx, config.ErrInvalidParams, err.Error())
+			return
+		}
+	}
 	offsetStr := rctx.ReadQueryParameter(common.QueryParamOffset)
 	limitStr := rctx.ReadQueryParameter(common.QueryParamLimit)
 	offset, limit, err := checkPagination(offsetStr, limitStr)
@@ -194,6 +467,12 @@ func (r *KVResource) List(rctx *restful.Context) {
 	}
 	request.Offset = offset
 	request.Limit = limit
+	request.Sort = rctx.ReadQueryParameter(common.QueryParamSort)
+	request.Cursor = rctx.ReadQueryParameter(common.QueryParamCursor)
+	if (request.Sort != "" || request.Cursor != "") && rctx.ReadQueryParameter(common.QueryParamWait) != "" {
+		WriteErrResponse(rctx, config.ErrInvalidParams, "can not accept wait, when using sort or cursor")
+		return
+	}
 	err = validator.Validate(request)
 	if err != nil {
 		WriteErrResponse(rctx, config.ErrInvalidParams, err.Error())
@@ -230,7 +509,7 @@ func returnData(rctx *restful.Context, request *model.ListKVRequest) {
 		rctx.WriteHeader(http.StatusNotModified)
 		return
 	}
-	revised, err := revNotMatch(rctx.Ctx, revStr, request.Domain)
+	revised, err := revNotMatch(rctx.Ctx, revStr, request.Project, request.Domain)
 	if err != nil {
 		if err == ErrInvalidRev {
 			WriteErrResponse(rctx, config.ErrInvalidParams, err.Error())
@@ -263,18 +542,22 @@ func isLegalWaitRequest(rctx *restful.Context, request *model.ListKVRequest) boo
 	return true
 }
 func watch(rctx *restful.Context, request *model.ListKVRequest, wait string) bool {
-	changed, topic, err := eventHappened(wait, &pubsub.Topic{
-		Labels:    request.Labels,
-		Project:   request.Project,
-		MatchType: request.Match,
-		DomainID:  request.Domain,
-	}, rctx.Ctx)
+	topic := &pubsub.Topic{
+		Labels:       request.Labels,
+		Selector:     request.Selector,
+		Combinations: request.Combinations,
+		Project:      request.Project,
+		MatchType:    request.Match,
+		DomainID:     request.Domain,
+		Resolve:      request.Resolve,
+	}
+	changed, topicName, err := eventHappened(wait, topic)
 	if err != nil {
 		WriteErrResponse(rctx, config.ErrObserveEvent, err.Error())
 		return true
 	}
 	if changed {
-		queryFromCache(rctx, topic)
+		queryFromCache(rctx, topicName, topic)
 		return true
 	}
 	return false
@@ -290,7 +573,7 @@ func (r *KVResource) Delete(rctx *restful.Context) {
 		WriteErrResponse(rctx, config.ErrInvalidParams, err.Error())
 		return
 	}
-	kv, err := kvsvc.FindOneAndDelete(rctx.Ctx, kvID, project, domain)
+	_, err = kvsvc.FindOneAndDelete(rctx.Ctx, kvID, project, domain)
 	if err != nil {
 		openlog.Error("delete failed, ", openlog.WithTags(openlog.Tags{
 			"kvID":  kvID,
@@ -303,16 +586,6 @@ func (r *KVResource) Delete(rctx *restful.Context) {
 		WriteError(rctx, err)
 		return
 	}
-	err = pubsub.Publish(&pubsub.KVChangeEvent{
-		Key:      kv.Key,
-		Labels:   kv.Labels,
-		Project:  project,
-		DomainID: domain,
-		Action:   pubsub.ActionDelete,
-	})
-	if err != nil {
-		openlog.Warn("lost kv change event:" + err.Error())
-	}
 	rctx.WriteHeader(http.StatusNoContent)
 }
 
@@ -330,7 +603,7 @@ func (r *KVResource) DeleteList(rctx *restful.Context) {
 		WriteErrResponse(rctx, config.ErrInvalidParams, err.Error())
 		return
 	}
-	kvs, err := kvsvc.FindManyAndDelete(rctx.Ctx, b.IDs, project, domain)
+	_, err = kvsvc.FindManyAndDelete(rctx.Ctx, b.IDs, project, domain)
 	if err != nil {
 		if err == datasource.ErrKeyNotExists {
 			rctx.WriteHeader(http.StatusNoContent)
@@ -343,18 +616,6 @@ func (r *KVResource) DeleteList(rctx *restful.Context) {
 		WriteError(rctx, err)
 		return
 	}
-	for _, kv := range kvs {
-		err = pubsub.Publish(&pubsub.KVChangeEvent{
-			Key:      kv.Key,
-			Labels:   kv.Labels,
-			Project:  project,
-			DomainID: domain,
-			Action:   pubsub.ActionDelete,
-		})
-		if err != nil {
-			openlog.Warn("lost kv change event:" + err.Error())
-		}
-	}
 	rctx.WriteHeader(http.StatusNoContent)
 }
 
@@ -368,6 +629,9 @@ func (r *KVResource) URLPatterns() []restful.Route {
 			FuncDesc:     "upload key values",
 			Parameters: []*restful.Parameters{
 				DocPathProject,
+				DocQueryOverride,
+				DocQueryDryRun,
+				DocQueryAtomic,
 				DocHeaderContentTypeJSONAndYaml,
 			},
 			Read: KVUploadBody{},
@@ -379,6 +643,115 @@ func (r *KVResource) URLPatterns() []restful.Route {
 			},
 			Consumes: []string{goRestful.MIME_JSON, common.ContentTypeYaml},
 			Produces: []string{goRestful.MIME_JSON, common.ContentTypeYaml},
+		}, {
+			Method:       http.MethodPost,
+			Path:         "/v1/{project}/kie/kv:validate",
+			ResourceFunc: r.Validate,
+			FuncDesc:     "validate key values without persisting them",
+			Parameters: []*restful.Parameters{
+				DocPathProject,
+				DocHeaderContentTypeJSONAndYaml,
+			},
+			Read: KVUploadBody{},
+			Returns: []*restful.Returns{
+				{
+					Code:  http.StatusOK,
+					Model: model.DocRespOfUpload{},
+				},
+			},
+			Consumes: []string{goRestful.MIME_JSON, common.ContentTypeYaml},
+			Produces: []string{goRestful.MIME_JSON, common.ContentTypeYaml},
+		}, {
+			Method:       http.MethodPost,
+			Path:         "/v1/{project}/kie/kv:check",
+			ResourceFunc: r.Check,
+			FuncDesc:     "try a checker against sample values",
+			Parameters: []*restful.Parameters{
+				DocPathProject,
+				DocHeaderContentTypeJSONAndYaml,
+			},
+			Read: CheckerTestBody{},
+			Returns: []*restful.Returns{
+				{
+					Code:  http.StatusOK,
+					Model: model.DocRespOfCheck{},
+				},
+			},
+			Consumes: []string{goRestful.MIME_JSON, common.ContentTypeYaml},
+			Produces: []string{goRestful.MIME_JSON, common.ContentTypeYaml},
+		}, {
+			Method:       http.MethodGet,
+			Path:         "/v1/{project}/kie/kv:tree",
+			ResourceFunc: r.Tree,
+			FuncDesc:     "list the child segments of keys under the prefix, with the number of key values under them",
+			Parameters: []*restful.Parameters{
+				DocPathProject, DocQueryPrefix, DocQueryDelimiter, DocQueryStatusParameters, DocQueryLabelParameters,
+			},
+			Returns: []*restful.Returns{
+				{
+					Code:  http.StatusOK,
+					Model: model.KeyTreeResponse{},
+				},
+			},
+			Produces: []string{goRestful.MIME_JSON, common.ContentTypeYaml},
+		}, {
+			Method:       http.MethodGet,
+			Path:         "/v1/{project}/kie/kv:search",
+			ResourceFunc: r.Search,
+			FuncDesc:     "search key values by regular expressions of key and value",
+			Parameters: []*restful.Parameters{
+				DocPathProject, DocQuerySearchKey, DocQuerySearchValue, DocQueryIgnoreCase, DocQueryValueType,
+				DocQueryStatusParameters, DocQueryLabelParameters, DocQueryUpdatedAfter, DocQueryUpdatedBefore,
+				DocQueryLimitParameters, DocQueryOffsetParameters,
+			},
+			Returns: []*restful.Returns{
+				{
+					Code:  http.StatusOK,
+					Model: model.SearchResponse{},
+				},
+			},
+			Produces: []string{goRestful.MIME_JSON, common.ContentTypeYaml},
+		}, {
+			Method:       http.MethodPost,
+			Path:         "/v1/{project}/kie/kv:copy",
+			ResourceFunc: r.Copy,
+			FuncDesc:     "copy key values to another project or label set",
+			Parameters: []*restful.Parameters{
+				DocPathProject, DocQueryOverride, DocQueryDryRun, DocHeaderContentTypeJSONAndYaml,
+			},
+			Read: KVCopyBody{},
+			Returns: []*restful.Returns{
+				{
+					Code:  http.StatusOK,
+					Model: model.DocRespOfUpload{},
+				},
+			},
+			Consumes: []string{goRestful.MIME_JSON, common.ContentTypeYaml},
+			Produces: []string{goRestful.MIME_JSON, common.ContentTypeYaml},
+		}, {
+			Method:       http.MethodPost,
+			Path:         "/v1/{project}/kie/txn",
+			ResourceFunc: r.Txn,
+			FuncDesc:     "create, update and delete key values atomically",
+			Parameters: []*restful.Parameters{
+				DocPathProject, DocHeaderContentTypeJSONAndYaml,
+			},
+			Read: TxnBody{},
+			Returns: []*restful.Returns{
+				{
+					Code:  http.StatusOK,
+					Model: model.TxnResponse{},
+					Headers: map[string]goRestful.Header{
+						common.HeaderRevision: DocHeaderRevision,
+					},
+				},
+				{
+					Code:    http.StatusConflict,
+					Message: "the key already exists, or the revision precondition is not satisfied",
+				},
+			},
+			Consumes: []string{goRestful.MIME_JSON, common.ContentTypeYaml},
+			Produces: []string{goRestful.MIME_JSON, common.ContentTypeYaml},
 		}, {
 			Method:       http.MethodPost,
 			Path:         "/v1/{project}/kie/kv",
@@ -413,13 +786,34 @@ func (r *KVResource) URLPatterns() []restful.Route {
 			},
 			Consumes: []string{goRestful.MIME_JSON, common.ContentTypeYaml},
 			Produces: []string{goRestful.MIME_JSON, common.ContentTypeYaml},
+		}, {
+			Method:       http.MethodPut,
+			Path:         "/v1/{project}/kie/kv/{kv_id}/labels",
+			ResourceFunc: r.Relabel,
+			FuncDesc:     "move a key value to another label set",
+			Parameters: []*restful.Parameters{
+				DocPathProject, DocPathKeyID, DocQueryOverride, DocHeaderContentTypeJSONAndYaml,
+			},
+			Read: KVRelabelBody{},
+			Returns: []*restful.Returns{
+				{
+					Code:  http.StatusOK,
+					Model: model.DocResponseSingleKey{},
+				},
+				{
+					Code:    http.StatusConflict,
+					Message: "the key already exists in the label set",
+				},
+			},
+			Consumes: []string{goRestful.MIME_JSON, common.ContentTypeYaml},
+			Produces: []string{goRestful.MIME_JSON, common.ContentTypeYaml},
 		}, {
 			Method:       http.MethodGet,
 			Path:         "/v1/{project}/kie/kv/{kv_id}",
 			ResourceFunc: r.Get,
 			FuncDesc:     "get key values by kv_id",
 			Parameters: []*restful.Parameters{
-				DocPathProject, DocPathKeyID,
+				DocPathProject, DocPathKeyID, DocQueryResolve,
 			},
 			Returns: []*restful.Returns{
 				{
@@ -442,8 +836,9 @@ func (r *KVResource) URLPatterns() []restful.Route {
 			ResourceFunc: r.List,
 			FuncDesc:     "list key values by labels and key",
 			Parameters: []*restful.Parameters{
-				DocPathProject, DocQueryKeyParameters, DocQueryStatusParameters, DocQueryLabelParameters,
+				DocPathProject, DocQueryKeyParameters, DocQueryStatusParameters, DocQueryLabelParameters, DocQueryCombination,
 				DocQueryWait, DocQueryMatch, DocQueryRev, DocQueryLimitParameters, DocQueryOffsetParameters,
+				DocQueryResolve, DocQuerySort, DocQueryCursor,
 			},
 			Returns: []*restful.Returns{
 				{
@@ -481,6 +876,23 @@ func (r *KVResource) URLPatterns() []restful.Route {
 					Message: "server error",
 				},
 			},
+		}, {
+			Method:       http.MethodPut,
+			Path:         "/v1/{project}/kie/kv",
+			ResourceFunc: r.BulkUpdate,
+			FuncDesc:     "set value, status or priority of key values selected by ids or labels",
+			Parameters: []*restful.Parameters{
+				DocPathProject, DocQueryDryRun, DocHeaderContentTypeJSONAndYaml,
+			},
+			Read: BulkUpdateBody{},
+			Returns: []*restful.Returns{
+				{
+					Code:  http.StatusOK,
+					Model: model.BulkResponse{},
+				},
+			},
+			Consumes: []string{goRestful.MIME_JSON, common.ContentTypeYaml},
+			Produces: []string{goRestful.MIME_JSON, common.ContentTypeYaml},
 		}, {
 			Method:       http.MethodDelete,
 			Path:         "/v1/{project}/kie/kv",
@@ -24,6 +24,8 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -34,7 +36,9 @@ import (
 
 	common2 "github.com/apache/servicecomb-kie/pkg/common"
 	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/config"
 	"github.com/apache/servicecomb-kie/server/plugin/qms"
+	"github.com/apache/servicecomb-kie/server/pubsub"
 	v1 "github.com/apache/servicecomb-kie/server/resource/v1"
 	"github.com/go-chassis/go-archaius"
 	"github.com/go-chassis/go-chassis/v2/server/restful/restfultest"
@@ -48,6 +52,24 @@ var string8192 = string1024 + string1024 + string1024 + string1024 + string1024
 var string65536 = string8192 + string8192 + string8192 + string8192 + string8192 + string8192 + string8192 + string8192
 var string131072 = string65536 + string65536
 
+// drainEvents waits until change events of earlier writes are delivered, so that they do not wake up later watchers,
+// events are delivered in order, so once a sentinel event published after them arrives, they are all delivered
+func drainEvents(t *testing.T) {
+	topic := &pubsub.Topic{DomainID: "default", Project: "drain", Labels: map[string]string{"drain": "sentinel"}}
+	o := &pubsub.Observer{UUID: "drain", Event: make(chan *pubsub.KVChangeEvent, 1)}
+	_, err := pubsub.AddObserver(o, topic)
+	assert.NoError(t, err)
+	defer pubsub.RemoveObserver(o.UUID, topic)
+	err = pubsub.Publish(&pubsub.KVChangeEvent{Key: "drain", Action: pubsub.ActionPut, Labels: topic.Labels,
+		DomainID: topic.DomainID, Project: topic.Project})
+	assert.NoError(t, err)
+	select {
+	case <-o.Event:
+	case <-time.After(5 * time.Second):
+		t.Fatal("change events are not delivered in 5s")
+	}
+}
+
 func TestKVResource_Post(t *testing.T) {
 	t.Run("post kv, label is invalid, should return err", func(t *testing.T) {
 		kv := &model.KVDoc{
@@ -136,7 +158,7 @@ func TestKVResource_Post(t *testing.T) {
 	t.Run("post kv, value type is xml, should success", func(t *testing.T) {
 		kv := &model.KVDoc{
 			Key:       "xml",
-			Value:     "a",
+			Value:     "<a/>",
 			ValueType: "xml",
 			Labels:    map[string]string{"a": "a"},
 		}
@@ -302,6 +324,170 @@ func TestKVResource_List(t *testing.T) {
 		assert.Equal(t, 2, len(result.Data))
 		rev = resp.Header().Get(common2.HeaderRevision)
 	})
+	t.Run("list kv by label selector, should return kvs satisfy it", func(t *testing.T) {
+		for selector, want := range map[string]int{"version": 1, "!version": 2, "version in (1.0.0,2.0.0)": 1, "version!=1.0.0": 2} {
+			r, _ := http.NewRequest("GET", "/v1/kv_test/kie/kv?label=service:utService&label="+url.QueryEscape(selector), nil)
+			r.Header.Set("Content-Type", "application/json")
+			kvr := &v1.KVResource{}
+			c, err := restfultest.New(kvr, nil)
+			assert.NoError(t, err)
+			resp := httptest.NewRecorder()
+			c.ServeHTTP(resp, r)
+			body, err := ioutil.ReadAll(resp.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.Code, string(body))
+			result := &model.KVResponse{}
+			err = json.Unmarshal(body, result)
+			assert.NoError(t, err)
+			assert.Equal(t, want, len(result.Data), selector)
+		}
+
+		r, _ := http.NewRequest("GET", "/v1/kv_test/kie/kv?label="+url.QueryEscape("version in ()"), nil)
+		kvr := &v1.KVResource{}
+		c, err := restfultest.New(kvr, nil)
+		assert.NoError(t, err)
+		resp := httptest.NewRecorder()
+		c.ServeHTTP(resp, r)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+	t.Run("list kv by label combinations, should return the union", func(t *testing.T) {
+		r, _ := http.NewRequest("GET", "/v1/kv_test/kie/kv?q=service:utService&q=service:utService+version:1.0.0&match=exact", nil)
+		r.Header.Set("Content-Type", "application/json")
+		kvr := &v1.KVResource{}
+		c, err := restfultest.New(kvr, nil)
+		assert.NoError(t, err)
+		resp := httptest.NewRecorder()
+		c.ServeHTTP(resp, r)
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.Code, string(body))
+		result := &model.KVResponse{}
+		err = json.Unmarshal(body, result)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, len(result.Data))
+		for _, kv := range result.Data {
+			if kv.Labels["version"] != "" {
+				assert.Equal(t, []string{"service=utService::version=1.0.0"}, result.Matched[kv.ID])
+				continue
+			}
+			assert.Equal(t, []string{"service=utService"}, result.Matched[kv.ID])
+		}
+
+		r, _ = http.NewRequest("GET", "/v1/kv_test/kie/kv?q=service:utService&label=service:utService", nil)
+		resp = httptest.NewRecorder()
+		c.ServeHTTP(resp, r)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+	t.Run("search kv by key regex, should highlight the matches", func(t *testing.T) {
+		r, _ := http.NewRequest("GET", "/v1/kv_test/kie/kv:search?key="+url.QueryEscape("^TIME")+"&ignore_case=true&label=service:utService", nil)
+		kvr := &v1.KVResource{}
+		c, err := restfultest.New(kvr, nil)
+		assert.NoError(t, err)
+		resp := httptest.NewRecorder()
+		c.ServeHTTP(resp, r)
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.Code, string(body))
+		result := &model.SearchResponse{}
+		err = json.Unmarshal(body, result)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(result.Data))
+		for _, kv := range result.Data {
+			assert.Equal(t, []string{"<em>time</em>out"}, kv.Highlight["key"])
+		}
+
+		r, _ = http.NewRequest("GET", "/v1/kv_test/kie/kv:search?updated_after=yesterday", nil)
+		resp = httptest.NewRecorder()
+		c.ServeHTTP(resp, r)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+	t.Run("list key tree by service label, should count kvs of timeout", func(t *testing.T) {
+		r, _ := http.NewRequest("GET", "/v1/kv_test/kie/kv:tree?prefix=time&label=service:utService", nil)
+		kvr := &v1.KVResource{}
+		c, err := restfultest.New(kvr, nil)
+		assert.NoError(t, err)
+		resp := httptest.NewRecorder()
+		c.ServeHTTP(resp, r)
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.Code, string(body))
+		result := &model.KeyTreeResponse{}
+		err = json.Unmarshal(body, result)
+		assert.NoError(t, err)
+		assert.Equal(t, ".", result.Delimiter)
+		assert.Equal(t, []*model.KeyNode{{Name: "out", Path: "timeout", Count: 2, Key: true, Leaf: true}}, result.Data)
+
+		r, _ = http.NewRequest("GET", "/v1/kv_test/kie/kv:tree?delimiter=/", nil)
+		resp = httptest.NewRecorder()
+		c.ServeHTTP(resp, r)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+	t.Run("preview copying kvs of timeout to another project, should diff them", func(t *testing.T) {
+		j, _ := json.Marshal(&v1.KVCopyBody{
+			Key:           "timeout",
+			Labels:        []string{"service:utService"},
+			TargetProject: "kv_copy_test",
+		})
+		r, _ := http.NewRequest("POST", "/v1/kv_test/kie/kv:copy?dryRun=true", bytes.NewBuffer(j))
+		r.Header.Set("Content-Type", "application/json")
+		c, err := restfultest.New(&v1.KVResource{}, nil)
+		assert.NoError(t, err)
+		resp := httptest.NewRecorder()
+		c.ServeHTTP(resp, r)
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.Code, string(body))
+		result := &model.DocRespOfUpload{}
+		err = json.Unmarshal(body, result)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(result.Diff))
+		for _, d := range result.Diff {
+			assert.Equal(t, model.DiffCreate, d.Action)
+		}
+
+		r, _ = http.NewRequest("POST", "/v1/kv_test/kie/kv:copy?override=unknown", bytes.NewBuffer(j))
+		r.Header.Set("Content-Type", "application/json")
+		resp = httptest.NewRecorder()
+		c.ServeHTTP(resp, r)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+	t.Run("list kv sorted by key, should page by cursor", func(t *testing.T) {
+		kvr := &v1.KVResource{}
+		c, err := restfultest.New(kvr, nil)
+		assert.NoError(t, err)
+		var keys []string
+		cursor := ""
+		for i := 0; i < 3; i++ {
+			r, _ := http.NewRequest("GET", "/v1/kv_test/kie/kv?label=service:utService&sort=-key&limit=2&cursor="+cursor, nil)
+			resp := httptest.NewRecorder()
+			c.ServeHTTP(resp, r)
+			body, err := ioutil.ReadAll(resp.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.Code, string(body))
+			result := &model.KVResponse{}
+			err = json.Unmarshal(body, result)
+			assert.NoError(t, err)
+			for _, kv := range result.Data {
+				keys = append(keys, kv.Key)
+			}
+			if result.NextCursor == "" {
+				break
+			}
+			cursor = result.NextCursor
+		}
+		assert.Equal(t, 3, len(keys))
+		assert.True(t, sort.SliceIsSorted(keys, func(i, j int) bool { return keys[i] > keys[j] }), keys)
+
+		r, _ := http.NewRequest("GET", "/v1/kv_test/kie/kv?sort=-key&offset=1", nil)
+		resp := httptest.NewRecorder()
+		c.ServeHTTP(resp, r)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+		r, _ = http.NewRequest("GET", "/v1/kv_test/kie/kv?sort=value", nil)
+		resp = httptest.NewRecorder()
+		c.ServeHTTP(resp, r)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
 	t.Run("list kv by service label, with current rev param,should return 304 ", func(t *testing.T) {
 		r, _ := http.NewRequest("GET", "/v1/kv_test/kie/kv?label=service:utService&"+common2.QueryParamRev+"="+rev, nil)
 		r.Header.Set("Content-Type", "application/json")
@@ -338,6 +524,7 @@ func TestKVResource_List(t *testing.T) {
 		assert.Equal(t, http.StatusOK, resp.Result().StatusCode)
 	})
 	t.Run("list kv by service label, with wait and current rev param,should wait and return 304 ", func(t *testing.T) {
+		drainEvents(t)
 		r, _ := http.NewRequest("GET", "/v1/kv_test/kie/kv?label=service:utService&"+common2.QueryParamRev+"=1", nil)
 		r.Header.Set("Content-Type", "application/json")
 		kvr := &v1.KVResource{}
@@ -790,6 +977,70 @@ func TestKVResource_Upload(t *testing.T) {
 		assert.Equal(t, 1, len(data.Success))
 		assert.Equal(t, "uploadGreaterThanMaxValueOfKie", data.Failure[0].Key)
 	})
+	upload := func(t *testing.T, query string, kvs []*model.KVDoc) *model.DocRespOfUpload {
+		j, _ := json.Marshal(&v1.KVUploadBody{Data: kvs})
+		r, _ := http.NewRequest("POST", "/v1/upload_test/kie/file?"+query, bytes.NewBuffer(j))
+		r.Header.Set("Content-Type", "application/json")
+		c, _ := restfultest.New(&v1.KVResource{}, nil)
+		resp := httptest.NewRecorder()
+		c.ServeHTTP(resp, r)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		data := &model.DocRespOfUpload{}
+		err := json.Unmarshal(resp.Body.Bytes(), data)
+		assert.NoError(t, err)
+		return data
+	}
+	exist := func(t *testing.T, key string) bool {
+		r, _ := http.NewRequest("GET", "/v1/upload_test/kie/kv?key="+key, nil)
+		c, _ := restfultest.New(&v1.KVResource{}, nil)
+		resp := httptest.NewRecorder()
+		c.ServeHTTP(resp, r)
+		data := &model.KVResponse{}
+		err := json.Unmarshal(resp.Body.Bytes(), data)
+		assert.NoError(t, err)
+		return len(data.Data) != 0
+	}
+	t.Run("upload atomically with an invalid input, should upload nothing", func(t *testing.T) {
+		data := upload(t, "override=abort&atomic=true", []*model.KVDoc{
+			{Key: "atomic-1", Value: "1"},
+			{Key: "atomic-2", Value: "{", ValueType: "json"},
+		})
+		assert.Equal(t, 0, len(data.Success))
+		assert.Equal(t, 2, len(data.Failure))
+		assert.False(t, exist(t, "atomic-1"))
+	})
+	t.Run("upload atomically, should upload all", func(t *testing.T) {
+		data := upload(t, "override=abort&atomic=true", []*model.KVDoc{
+			{Key: "atomic-1", Value: "1"},
+			{Key: "atomic-2", Value: "{}", ValueType: "json"},
+		})
+		assert.Equal(t, 2, len(data.Success))
+		assert.Equal(t, 0, len(data.Failure))
+		assert.True(t, exist(t, "atomic-2"))
+	})
+	t.Run("preview uploading with force, should diff without persisting", func(t *testing.T) {
+		data := upload(t, "override=force&dryRun=true", []*model.KVDoc{
+			{Key: "atomic-1", Value: "2"},
+			{Key: "atomic-2", Value: "{}", ValueType: "json"},
+			{Key: "atomic-3", Value: "3"},
+		})
+		assert.Equal(t, 0, len(data.Failure))
+		assert.Equal(t, []*model.KVDiff{
+			{Key: "atomic-1", Labels: map[string]string{}, Action: model.DiffUpdate, OldValue: "1", NewValue: "2"},
+			{Key: "atomic-2", Labels: map[string]string{}, Action: model.DiffUnchanged, OldValue: "{}", NewValue: "{}"},
+			{Key: "atomic-3", Labels: map[string]string{}, Action: model.DiffCreate, NewValue: "3"},
+		}, data.Diff)
+		assert.False(t, exist(t, "atomic-3"))
+	})
+	t.Run("upload atomically with abort, should stop at the duplicate kv and upload nothing", func(t *testing.T) {
+		data := upload(t, "override=abort&atomic=true", []*model.KVDoc{
+			{Key: "atomic-3", Value: "3"},
+			{Key: "atomic-1", Value: "2"},
+		})
+		assert.Equal(t, 0, len(data.Success))
+		assert.Equal(t, 2, len(data.Failure))
+		assert.False(t, exist(t, "atomic-3"))
+	})
 }
 func TestKVResource_PutAndGet(t *testing.T) {
 	var id string
@@ -871,6 +1122,7 @@ func TestKVResource_PutAndGet(t *testing.T) {
 
 		err := archaius.Set(qms.QuotaConfigKey, 2)
 		assert.NoError(t, err)
+		defer archaius.Set(qms.QuotaConfigKey, qms.DefaultQuota)
 		j, _ := json.Marshal(&model.KVDoc{
 			Key:   "reached_quota",
 			Value: "1",
@@ -933,3 +1185,169 @@ func TestKVResource_DeleteList(t *testing.T) {
 		assert.Equal(t, 0, len(result.Data))
 	})
 }
+
+func TestKVResource_Validate(t *testing.T) {
+	validate := func(t *testing.T, project string, kvs []*model.KVDoc) *model.DocRespOfUpload {
+		input := &v1.KVUploadBody{Data: kvs}
+		j, _ := json.Marshal(input)
+		r, _ := http.NewRequest("POST", "/v1/"+project+"/kie/kv:validate", bytes.NewBuffer(j))
+		r.Header.Set("Content-Type", "application/json")
+		c, _ := restfultest.New(&v1.KVResource{}, nil)
+		resp := httptest.NewRecorder()
+		c.ServeHTTP(resp, r)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		data := &model.DocRespOfUpload{}
+		err := json.Unmarshal(resp.Body.Bytes(), data)
+		assert.NoError(t, err)
+		return data
+	}
+	t.Run("invalid values should be reported with position, and nothing is persisted", func(t *testing.T) {
+		data := validate(t, "validate_test", []*model.KVDoc{
+			{Key: "valid", Value: "a: b", ValueType: "yaml"},
+			{Key: "broken", Value: "{\"a\":\n  }", ValueType: "json"},
+			{Key: "text", Value: "{"},
+		})
+		assert.Equal(t, 2, len(data.Success))
+		if assert.Equal(t, 1, len(data.Failure)) {
+			assert.Equal(t, "broken", data.Failure[0].Key)
+			assert.Equal(t, 2, data.Failure[0].Line)
+			assert.Equal(t, 3, data.Failure[0].Column)
+		}
+
+		r, _ := http.NewRequest("GET", "/v1/validate_test/kie/kv", nil)
+		c, _ := restfultest.New(&v1.KVResource{}, nil)
+		resp := httptest.NewRecorder()
+		c.ServeHTTP(resp, r)
+		result := &model.KVResponse{}
+		err := json.Unmarshal(resp.Body.Bytes(), result)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(result.Data))
+	})
+	t.Run("create kv with invalid value should fail", func(t *testing.T) {
+		kv := &model.KVDoc{Key: "broken", Value: "<a>", ValueType: "xml"}
+		j, _ := json.Marshal(kv)
+		r, _ := http.NewRequest("POST", "/v1/validate_test/kie/kv", bytes.NewBuffer(j))
+		r.Header.Set("Content-Type", "application/json")
+		c, _ := restfultest.New(&v1.KVResource{}, nil)
+		resp := httptest.NewRecorder()
+		c.ServeHTTP(resp, r)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+	t.Run("value_type is required in strict mode", func(t *testing.T) {
+		config.Configurations.ValueType.StrictProjects = []string{"validate_strict"}
+		defer func() {
+			config.Configurations.ValueType.StrictProjects = nil
+		}()
+		data := validate(t, "validate_strict", []*model.KVDoc{{Key: "text", Value: "a"}})
+		assert.Equal(t, 1, len(data.Failure))
+		data = validate(t, "validate_test", []*model.KVDoc{{Key: "text", Value: "a"}})
+		assert.Equal(t, 0, len(data.Failure))
+	})
+}
+
+func TestKVResource_Check(t *testing.T) {
+	check := "int(value) > 0 && int(value) < 1000"
+	t.Run("try checker against sample values", func(t *testing.T) {
+		j, _ := json.Marshal(&v1.CheckerTestBody{Check: check, Values: []string{"1", "1000", "abc"}})
+		r, _ := http.NewRequest("POST", "/v1/check_test/kie/kv:check", bytes.NewBuffer(j))
+		r.Header.Set("Content-Type", "application/json")
+		c, _ := restfultest.New(&v1.KVResource{}, nil)
+		resp := httptest.NewRecorder()
+		c.ServeHTTP(resp, r)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		result := &model.DocRespOfCheck{}
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), result))
+		if assert.Equal(t, 3, len(result.Results)) {
+			assert.True(t, result.Results[0].Passed)
+			assert.False(t, result.Results[1].Passed)
+			assert.False(t, result.Results[2].Passed)
+			assert.NotEmpty(t, result.Results[2].Reason)
+		}
+	})
+	t.Run("invalid checker should be rejected", func(t *testing.T) {
+		j, _ := json.Marshal(&v1.CheckerTestBody{Check: "value +", Values: []string{"1"}})
+		r, _ := http.NewRequest("POST", "/v1/check_test/kie/kv:check", bytes.NewBuffer(j))
+		r.Header.Set("Content-Type", "application/json")
+		c, _ := restfultest.New(&v1.KVResource{}, nil)
+		resp := httptest.NewRecorder()
+		c.ServeHTTP(resp, r)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+	t.Run("update value violating checker should be rejected", func(t *testing.T) {
+		kv := &model.KVDoc{Key: "checked", Value: "10", Checker: check}
+		j, _ := json.Marshal(kv)
+		r, _ := http.NewRequest("POST", "/v1/check_test/kie/kv", bytes.NewBuffer(j))
+		r.Header.Set("Content-Type", "application/json")
+		c, _ := restfultest.New(&v1.KVResource{}, nil)
+		resp := httptest.NewRecorder()
+		c.ServeHTTP(resp, r)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), kv))
+
+		j, _ = json.Marshal(&v1.KVUpdateBody{Value: "-1"})
+		r, _ = http.NewRequest("PUT", "/v1/check_test/kie/kv/"+kv.ID, bytes.NewBuffer(j))
+		r.Header.Set("Content-Type", "application/json")
+		resp = httptest.NewRecorder()
+		c.ServeHTTP(resp, r)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Body.String(), "rejected by checker")
+	})
+}
+
+func TestKVResource_Txn(t *testing.T) {
+	t.Run("create kvs in a transaction, should return the revision", func(t *testing.T) {
+		j, _ := json.Marshal(&v1.TxnBody{Ops: []*model.TxnOp{
+			{Op: model.TxnCreate, Key: "flag", Value: "true"},
+			{Op: model.TxnCreate, Key: "endpoint", Value: "http://a"},
+		}})
+		r, _ := http.NewRequest("POST", "/v1/txn_test/kie/txn", bytes.NewBuffer(j))
+		r.Header.Set("Content-Type", "application/json")
+		c, err := restfultest.New(&v1.KVResource{}, nil)
+		assert.NoError(t, err)
+		resp := httptest.NewRecorder()
+		c.ServeHTTP(resp, r)
+		assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		result := &model.TxnResponse{}
+		err = json.Unmarshal(resp.Body.Bytes(), result)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(result.Data))
+		assert.Equal(t, strconv.FormatInt(result.Revision, 10), resp.Header().Get(common2.HeaderRevision))
+
+		r, _ = http.NewRequest("POST", "/v1/txn_test/kie/txn", bytes.NewBuffer(j))
+		r.Header.Set("Content-Type", "application/json")
+		resp = httptest.NewRecorder()
+		c.ServeHTTP(resp, r)
+		assert.Equal(t, http.StatusConflict, resp.Code)
+	})
+}
+
+func TestKVResource_BulkUpdate(t *testing.T) {
+	t.Run("disable kvs by labels, should report each kv", func(t *testing.T) {
+		c, err := restfultest.New(&v1.KVResource{}, nil)
+		assert.NoError(t, err)
+		for _, key := range []string{"bulk.a", "bulk.b"} {
+			j, _ := json.Marshal(&model.KVDoc{Key: key, Value: "1", Status: common2.StatusEnabled, Labels: map[string]string{"app": "bulk"}})
+			r, _ := http.NewRequest("POST", "/v1/bulk_test/kie/kv", bytes.NewBuffer(j))
+			r.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			c.ServeHTTP(resp, r)
+			assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		}
+		j, _ := json.Marshal(&v1.BulkUpdateBody{
+			Labels: []string{"app:bulk"},
+			Set:    model.KVPatch{Status: common2.StatusDisabled},
+		})
+		r, _ := http.NewRequest("PUT", "/v1/bulk_test/kie/kv", bytes.NewBuffer(j))
+		r.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		c.ServeHTTP(resp, r)
+		assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		result := &model.BulkResponse{}
+		err = json.Unmarshal(resp.Body.Bytes(), result)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, result.Total)
+		for _, kv := range result.Success {
+			assert.Equal(t, common2.StatusDisabled, kv.Status)
+		}
+	})
+}
@@ -0,0 +1,165 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1
+
+import (
+	"fmt"
+	"net/http"
+
+	goRestful "github.com/emicklei/go-restful"
+	"github.com/go-chassis/cari/config"
+	"github.com/go-chassis/go-chassis/v2/server/restful"
+	"github.com/go-chassis/openlog"
+
+	"github.com/apache/servicecomb-kie/pkg/common"
+	"github.com/apache/servicecomb-kie/pkg/model"
+	labelsvc "github.com/apache/servicecomb-kie/server/service/label"
+)
+
+// LabelResource has API about the label catalog of a project
+type LabelResource struct {
+}
+
+// List return the distinct label keys and values of the project
+func (r *LabelResource) List(rctx *restful.Context) {
+	result, svcErr := labelsvc.List(rctx.Ctx, rctx.ReadPathParameter(common.PathParameterProject), ReadDomain(rctx.Ctx))
+	if svcErr != nil {
+		WriteErrResponse(rctx, svcErr.Code, svcErr.Detail)
+		return
+	}
+	err := writeResponse(rctx, result)
+	if err != nil {
+		openlog.Error(err.Error())
+	}
+}
+
+// ListSets return the label sets of the project
+func (r *LabelResource) ListSets(rctx *restful.Context) {
+	result, svcErr := labelsvc.ListSets(rctx.Ctx, rctx.ReadPathParameter(common.PathParameterProject), ReadDomain(rctx.Ctx),
+		rctx.ReadQueryParameter(common.QueryParamOrphan) == "true")
+	if svcErr != nil {
+		WriteErrResponse(rctx, svcErr.Code, svcErr.Detail)
+		return
+	}
+	err := writeResponse(rctx, result)
+	if err != nil {
+		openlog.Error(err.Error())
+	}
+}
+
+// PutAlias names a label set
+func (r *LabelResource) PutAlias(rctx *restful.Context) {
+	body := new(LabelAliasBody)
+	if err := readRequest(rctx, body); err != nil {
+		WriteErrResponse(rctx, config.ErrInvalidParams, fmt.Sprintf(FmtReadRequestError, err))
+		return
+	}
+	doc, svcErr := labelsvc.UpdateAlias(rctx.Ctx, &model.LabelAliasRequest{
+		Project: rctx.ReadPathParameter(common.PathParameterProject),
+		Domain:  ReadDomain(rctx.Ctx),
+		Labels:  body.Labels,
+		Alias:   body.Alias,
+	})
+	if svcErr != nil {
+		WriteErrResponse(rctx, svcErr.Code, svcErr.Detail)
+		return
+	}
+	err := writeResponse(rctx, doc)
+	if err != nil {
+		openlog.Error(err.Error())
+	}
+}
+
+// DeleteSet deletes an orphan label set by id
+func (r *LabelResource) DeleteSet(rctx *restful.Context) {
+	svcErr := labelsvc.DeleteOrphan(rctx.Ctx, rctx.ReadPathParameter(common.PathParamLabelSetID),
+		rctx.ReadPathParameter(common.PathParameterProject), ReadDomain(rctx.Ctx))
+	if svcErr != nil {
+		WriteErrResponse(rctx, svcErr.Code, svcErr.Detail)
+		return
+	}
+	rctx.WriteHeader(http.StatusNoContent)
+}
+
+// URLPatterns defined label operations
+func (r *LabelResource) URLPatterns() []restful.Route {
+	return []restful.Route{
+		{
+			Method:       http.MethodGet,
+			Path:         "/v1/{project}/kie/labels",
+			ResourceFunc: r.List,
+			FuncDesc:     "list distinct label keys and values of the project, with the number of key values having them",
+			Parameters: []*restful.Parameters{
+				DocPathProject,
+			},
+			Returns: []*restful.Returns{
+				{
+					Code:  http.StatusOK,
+					Model: model.LabelResponse{},
+				},
+			},
+			Produces: []string{goRestful.MIME_JSON, common.ContentTypeYaml},
+		}, {
+			Method:       http.MethodGet,
+			Path:         "/v1/{project}/kie/labels/sets",
+			ResourceFunc: r.ListSets,
+			FuncDesc:     "list label sets of the project, with their aliases and the number of key values",
+			Parameters: []*restful.Parameters{
+				DocPathProject, DocQueryOrphan,
+			},
+			Returns: []*restful.Returns{
+				{
+					Code:  http.StatusOK,
+					Model: model.LabelSetResponse{},
+				},
+			},
+			Produces: []string{goRestful.MIME_JSON, common.ContentTypeYaml},
+		}, {
+			Method:       http.MethodPut,
+			Path:         "/v1/{project}/kie/labels/aliases",
+			ResourceFunc: r.PutAlias,
+			FuncDesc:     "give a label set a human friendly name, an empty alias removes the name",
+			Parameters: []*restful.Parameters{
+				DocPathProject, DocHeaderContentTypeJSONAndYaml,
+			},
+			Read: LabelAliasBody{},
+			Returns: []*restful.Returns{
+				{
+					Code:  http.StatusOK,
+					Model: model.LabelDoc{},
+				},
+			},
+			Consumes: []string{goRestful.MIME_JSON, common.ContentTypeYaml},
+			Produces: []string{goRestful.MIME_JSON, common.ContentTypeYaml},
+		}, {
+			Method:       http.MethodDelete,
+			Path:         "/v1/{project}/kie/labels/sets/{label_set_id}",
+			ResourceFunc: r.DeleteSet,
+			FuncDesc:     "delete a label set no key value uses",
+			Parameters: []*restful.Parameters{
+				DocPathProject, DocPathLabelSetID,
+			},
+			Returns: []*restful.Returns{
+				{
+					Code:    http.StatusNoContent,
+					Message: "delete success",
+				},
+			},
+		},
+	}
+}
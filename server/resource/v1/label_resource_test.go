@@ -0,0 +1,126 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	_ "github.com/apache/servicecomb-kie/test"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	v1 "github.com/apache/servicecomb-kie/server/resource/v1"
+	"github.com/go-chassis/go-chassis/v2/server/restful/restfultest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLabelResource(t *testing.T) {
+	serve := func(resource interface{}, method, url string, body interface{}) *httptest.ResponseRecorder {
+		var r *http.Request
+		if body != nil {
+			j, _ := json.Marshal(body)
+			r, _ = http.NewRequest(method, url, bytes.NewBuffer(j))
+			r.Header.Set("Content-Type", "application/json")
+		} else {
+			r, _ = http.NewRequest(method, url, nil)
+		}
+		c, _ := restfultest.New(resource, nil)
+		resp := httptest.NewRecorder()
+		c.ServeHTTP(resp, r)
+		return resp
+	}
+	labels := &v1.LabelResource{}
+	kvs := &v1.KVResource{}
+	mallProd := map[string]string{"app": "mall", "env": "prod"}
+	var cart *model.KVDoc
+	for _, kv := range []*model.KVDoc{
+		{Key: "timeout", Value: "1", Labels: mallProd},
+		{Key: "retry", Value: "1", Labels: mallProd},
+		{Key: "timeout", Value: "1", Labels: map[string]string{"app": "cart"}},
+	} {
+		resp := serve(kvs, http.MethodPost, "/v1/label_test/kie/kv", kv)
+		assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		cart = &model.KVDoc{}
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), cart))
+	}
+
+	t.Run("list labels, should count kvs of each key and value", func(t *testing.T) {
+		resp := serve(labels, http.MethodGet, "/v1/label_test/kie/labels", nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		result := &model.LabelResponse{}
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), result))
+		assert.Equal(t, []*model.LabelKey{
+			{Key: "app", Count: 3, Values: []*model.LabelValue{{Value: "cart", Count: 1}, {Value: "mall", Count: 2}}},
+			{Key: "env", Count: 2, Values: []*model.LabelValue{{Value: "prod", Count: 2}}},
+		}, result.Data)
+	})
+	t.Run("name label sets, aliases should be unique", func(t *testing.T) {
+		resp := serve(labels, http.MethodPut, "/v1/label_test/kie/labels/aliases", &v1.LabelAliasBody{
+			Labels: mallProd, Alias: "mall-prod",
+		})
+		assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		doc := &model.LabelDoc{}
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), doc))
+		assert.Equal(t, "mall-prod", doc.Alias)
+		assert.Equal(t, int64(2), doc.Count)
+
+		resp = serve(labels, http.MethodPut, "/v1/label_test/kie/labels/aliases", &v1.LabelAliasBody{
+			Labels: map[string]string{"app": "cart"}, Alias: "mall-prod",
+		})
+		assert.Equal(t, http.StatusConflict, resp.Code)
+
+		resp = serve(labels, http.MethodPut, "/v1/label_test/kie/labels/aliases", &v1.LabelAliasBody{
+			Labels: map[string]string{"app": "unknown"}, Alias: "unknown",
+		})
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+	t.Run("delete kvs of a label set, should find it as an orphan", func(t *testing.T) {
+		resp := serve(kvs, http.MethodDelete, "/v1/label_test/kie/kv/"+cart.ID, nil)
+		assert.Equal(t, http.StatusNoContent, resp.Code)
+
+		resp = serve(labels, http.MethodGet, "/v1/label_test/kie/labels/sets?orphan=true", nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		result := &model.LabelSetResponse{}
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), result))
+		assert.Equal(t, 1, result.Total)
+		orphan := result.Data[0]
+		assert.Equal(t, map[string]string{"app": "cart"}, orphan.Labels)
+		assert.Equal(t, int64(0), orphan.Count)
+
+		resp = serve(labels, http.MethodGet, "/v1/label_test/kie/labels/sets", nil)
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), result))
+		assert.Equal(t, 2, result.Total)
+		var inUse string
+		for _, doc := range result.Data {
+			if doc.Alias == "mall-prod" {
+				inUse = doc.ID
+			}
+		}
+		assert.NotEmpty(t, inUse)
+		resp = serve(labels, http.MethodDelete, "/v1/label_test/kie/labels/sets/"+inUse, nil)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+		resp = serve(labels, http.MethodDelete, "/v1/label_test/kie/labels/sets/"+orphan.ID, nil)
+		assert.Equal(t, http.StatusNoContent, resp.Code)
+		resp = serve(labels, http.MethodDelete, "/v1/label_test/kie/labels/sets/"+orphan.ID, nil)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
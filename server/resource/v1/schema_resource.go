@@ -0,0 +1,204 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package v1
+
+import (
+	"fmt"
+	"net/http"
+
+	goRestful "github.com/emicklei/go-restful"
+	"github.com/go-chassis/cari/config"
+	"github.com/go-chassis/go-chassis/v2/server/restful"
+	"github.com/go-chassis/openlog"
+
+	"github.com/apache/servicecomb-kie/pkg/common"
+	"github.com/apache/servicecomb-kie/pkg/model"
+	schemasvc "github.com/apache/servicecomb-kie/server/service/schema"
+)
+
+// SchemaResource has API about json schemas of keys
+type SchemaResource struct {
+}
+
+// Post create a schema of a key or key pattern
+func (r *SchemaResource) Post(rctx *restful.Context) {
+	body := new(SchemaCreateBody)
+	if err := readRequest(rctx, body); err != nil {
+		WriteErrResponse(rctx, config.ErrInvalidParams, fmt.Sprintf(FmtReadRequestError, err))
+		return
+	}
+	doc, svcErr := schemasvc.Create(rctx.Ctx, &model.SchemaDoc{
+		Key:     body.Key,
+		Schema:  body.Schema,
+		Domain:  ReadDomain(rctx.Ctx),
+		Project: rctx.ReadPathParameter(common.PathParameterProject),
+	})
+	if svcErr != nil {
+		WriteErrResponse(rctx, svcErr.Code, svcErr.Detail)
+		return
+	}
+	writeSchema(rctx, doc)
+}
+
+// Put replace the schema content
+func (r *SchemaResource) Put(rctx *restful.Context) {
+	body := new(SchemaUpdateBody)
+	if err := readRequest(rctx, body); err != nil {
+		WriteErrResponse(rctx, config.ErrInvalidParams, fmt.Sprintf(FmtReadRequestError, err))
+		return
+	}
+	doc, svcErr := schemasvc.Update(rctx.Ctx, rctx.ReadPathParameter(common.PathParamSchemaID),
+		rctx.ReadPathParameter(common.PathParameterProject), ReadDomain(rctx.Ctx), body.Schema)
+	if svcErr != nil {
+		WriteErrResponse(rctx, svcErr.Code, svcErr.Detail)
+		return
+	}
+	writeSchema(rctx, doc)
+}
+
+// Get return a schema by id
+func (r *SchemaResource) Get(rctx *restful.Context) {
+	doc, svcErr := schemasvc.Get(rctx.Ctx, rctx.ReadPathParameter(common.PathParamSchemaID),
+		rctx.ReadPathParameter(common.PathParameterProject), ReadDomain(rctx.Ctx))
+	if svcErr != nil {
+		WriteErrResponse(rctx, svcErr.Code, svcErr.Detail)
+		return
+	}
+	writeSchema(rctx, doc)
+}
+
+// List return all schemas of the project
+func (r *SchemaResource) List(rctx *restful.Context) {
+	docs, svcErr := schemasvc.List(rctx.Ctx, rctx.ReadPathParameter(common.PathParameterProject), ReadDomain(rctx.Ctx))
+	if svcErr != nil {
+		WriteErrResponse(rctx, svcErr.Code, svcErr.Detail)
+		return
+	}
+	for _, doc := range docs {
+		doc.Domain = ""
+		doc.Project = ""
+	}
+	err := writeResponse(rctx, &model.SchemaResponse{Total: len(docs), Data: docs})
+	if err != nil {
+		openlog.Error(err.Error())
+	}
+}
+
+// Delete deletes a schema by id
+func (r *SchemaResource) Delete(rctx *restful.Context) {
+	svcErr := schemasvc.Delete(rctx.Ctx, rctx.ReadPathParameter(common.PathParamSchemaID),
+		rctx.ReadPathParameter(common.PathParameterProject), ReadDomain(rctx.Ctx))
+	if svcErr != nil {
+		WriteErrResponse(rctx, svcErr.Code, svcErr.Detail)
+		return
+	}
+	rctx.WriteHeader(http.StatusNoContent)
+}
+
+func writeSchema(rctx *restful.Context, doc *model.SchemaDoc) {
+	doc.Domain = ""
+	doc.Project = ""
+	err := writeResponse(rctx, doc)
+	if err != nil {
+		openlog.Error(err.Error())
+	}
+}
+
+// URLPatterns defined schema operations
+func (r *SchemaResource) URLPatterns() []restful.Route {
+	return []restful.Route{
+		{
+			Method:       http.MethodPost,
+			Path:         "/v1/{project}/kie/schema",
+			ResourceFunc: r.Post,
+			FuncDesc:     "create the json schema of a key or key pattern",
+			Parameters: []*restful.Parameters{
+				DocPathProject, DocHeaderContentTypeJSONAndYaml,
+			},
+			Read: SchemaCreateBody{},
+			Returns: []*restful.Returns{
+				{
+					Code:  http.StatusOK,
+					Model: model.SchemaDoc{},
+				},
+			},
+			Consumes: []string{goRestful.MIME_JSON, common.ContentTypeYaml},
+			Produces: []string{goRestful.MIME_JSON, common.ContentTypeYaml},
+		}, {
+			Method:       http.MethodPut,
+			Path:         "/v1/{project}/kie/schema/{schema_id}",
+			ResourceFunc: r.Put,
+			FuncDesc:     "update a json schema",
+			Parameters: []*restful.Parameters{
+				DocPathProject, DocPathSchemaID, DocHeaderContentTypeJSONAndYaml,
+			},
+			Read: SchemaUpdateBody{},
+			Returns: []*restful.Returns{
+				{
+					Code:  http.StatusOK,
+					Model: model.SchemaDoc{},
+				},
+			},
+			Consumes: []string{goRestful.MIME_JSON, common.ContentTypeYaml},
+			Produces: []string{goRestful.MIME_JSON, common.ContentTypeYaml},
+		}, {
+			Method:       http.MethodGet,
+			Path:         "/v1/{project}/kie/schema/{schema_id}",
+			ResourceFunc: r.Get,
+			FuncDesc:     "get a json schema by schema_id",
+			Parameters: []*restful.Parameters{
+				DocPathProject, DocPathSchemaID,
+			},
+			Returns: []*restful.Returns{
+				{
+					Code:  http.StatusOK,
+					Model: model.SchemaDoc{},
+				},
+			},
+			Produces: []string{goRestful.MIME_JSON, common.ContentTypeYaml},
+		}, {
+			Method:       http.MethodGet,
+			Path:         "/v1/{project}/kie/schema",
+			ResourceFunc: r.List,
+			FuncDesc:     "list json schemas of the project",
+			Parameters: []*restful.Parameters{
+				DocPathProject,
+			},
+			Returns: []*restful.Returns{
+				{
+					Code:  http.StatusOK,
+					Model: model.SchemaResponse{},
+				},
+			},
+			Produces: []string{goRestful.MIME_JSON, common.ContentTypeYaml},
+		}, {
+			Method:       http.MethodDelete,
+			Path:         "/v1/{project}/kie/schema/{schema_id}",
+			ResourceFunc: r.Delete,
+			FuncDesc:     "delete a json schema by schema_id",
+			Parameters: []*restful.Parameters{
+				DocPathProject, DocPathSchemaID,
+			},
+			Returns: []*restful.Returns{
+				{
+					Code:    http.StatusNoContent,
+					Message: "delete success",
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,138 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package v1_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	_ "github.com/apache/servicecomb-kie/test"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	v1 "github.com/apache/servicecomb-kie/server/resource/v1"
+	"github.com/go-chassis/go-chassis/v2/server/restful/restfultest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaResource(t *testing.T) {
+	serve := func(resource interface{}, method, url string, body interface{}) *httptest.ResponseRecorder {
+		var r *http.Request
+		if body != nil {
+			j, _ := json.Marshal(body)
+			r, _ = http.NewRequest(method, url, bytes.NewBuffer(j))
+			r.Header.Set("Content-Type", "application/json")
+		} else {
+			r, _ = http.NewRequest(method, url, nil)
+		}
+		c, _ := restfultest.New(resource, nil)
+		resp := httptest.NewRecorder()
+		c.ServeHTTP(resp, r)
+		return resp
+	}
+	schemas := &v1.SchemaResource{}
+	kvs := &v1.KVResource{}
+	timeout := &model.SchemaDoc{}
+
+	t.Run("create schemas", func(t *testing.T) {
+		resp := serve(schemas, http.MethodPost, "/v1/schema_test/kie/schema", &v1.SchemaCreateBody{
+			Key:    "timeout",
+			Schema: `{"type": "integer", "minimum": 100, "maximum": 30000}`,
+		})
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), timeout))
+		assert.NotEmpty(t, timeout.ID)
+
+		resp = serve(schemas, http.MethodPost, "/v1/schema_test/kie/schema", &v1.SchemaCreateBody{
+			Key:    "beginWith(db.)",
+			Schema: `{"type": "object", "properties": {"port": {"type": "integer"}}, "required": ["port"]}`,
+		})
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		resp = serve(schemas, http.MethodPost, "/v1/schema_test/kie/schema", &v1.SchemaCreateBody{
+			Key:    "timeout",
+			Schema: `{"type": "string"}`,
+		})
+		assert.Equal(t, http.StatusConflict, resp.Code)
+
+		resp = serve(schemas, http.MethodPost, "/v1/schema_test/kie/schema", &v1.SchemaCreateBody{
+			Key:    "invalid",
+			Schema: `{"type": 1}`,
+		})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+		resp = serve(schemas, http.MethodGet, "/v1/schema_test/kie/schema", nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		list := &model.SchemaResponse{}
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), list))
+		assert.Equal(t, 2, list.Total)
+	})
+	t.Run("kvs violating schemas should be rejected with the failing path", func(t *testing.T) {
+		resp := serve(kvs, http.MethodPost, "/v1/schema_test/kie/kv", &model.KVDoc{Key: "timeout", Value: "50"})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Body.String(), "at /")
+
+		resp = serve(kvs, http.MethodPost, "/v1/schema_test/kie/kv", &model.KVDoc{
+			Key:       "db.mysql",
+			Value:     "port: abc\n",
+			ValueType: "yaml",
+		})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Body.String(), "/port")
+
+		resp = serve(kvs, http.MethodPost, "/v1/schema_test/kie/file?override=force", &v1.KVUploadBody{Data: []*model.KVDoc{
+			{Key: "db.redis", Value: `{"host": "127.0.0.1"}`, ValueType: "json"},
+			{Key: "db.redis", Value: `{"port": 6379}`, ValueType: "json", Labels: map[string]string{"env": "test"}},
+		}})
+		assert.Equal(t, http.StatusOK, resp.Code)
+		result := &model.DocRespOfUpload{}
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), result))
+		assert.Equal(t, 1, len(result.Success))
+		assert.Equal(t, 1, len(result.Failure))
+	})
+	t.Run("kvs conform to schemas should be created and updated", func(t *testing.T) {
+		resp := serve(kvs, http.MethodPost, "/v1/schema_test/kie/kv", &model.KVDoc{Key: "timeout", Value: "200"})
+		assert.Equal(t, http.StatusOK, resp.Code)
+		kv := &model.KVDoc{}
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), kv))
+
+		resp = serve(kvs, http.MethodPut, "/v1/schema_test/kie/kv/"+kv.ID, &v1.KVUpdateBody{Value: "40000"})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		resp = serve(kvs, http.MethodPut, "/v1/schema_test/kie/kv/"+kv.ID, &v1.KVUpdateBody{Value: "300"})
+		assert.Equal(t, http.StatusOK, resp.Code)
+	})
+	t.Run("update and delete schema", func(t *testing.T) {
+		resp := serve(schemas, http.MethodPut, "/v1/schema_test/kie/schema/"+timeout.ID, &v1.SchemaUpdateBody{
+			Schema: `{"type": "integer", "minimum": 1}`,
+		})
+		assert.Equal(t, http.StatusOK, resp.Code)
+		resp = serve(kvs, http.MethodPost, "/v1/schema_test/kie/kv:validate", &v1.KVUploadBody{Data: []*model.KVDoc{
+			{Key: "timeout", Value: "50"},
+		}})
+		assert.Equal(t, http.StatusOK, resp.Code)
+		result := &model.DocRespOfUpload{}
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), result))
+		assert.Equal(t, 0, len(result.Failure))
+
+		resp = serve(schemas, http.MethodDelete, "/v1/schema_test/kie/schema/"+timeout.ID, nil)
+		assert.Equal(t, http.StatusNoContent, resp.Code)
+		resp = serve(schemas, http.MethodGet, "/v1/schema_test/kie/schema/"+timeout.ID, nil)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
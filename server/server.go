@@ -18,11 +18,15 @@
 package server
 
 import (
+	"fmt"
+	"time"
+
 	chassis "github.com/go-chassis/go-chassis/v2"
 	"github.com/go-chassis/go-chassis/v2/core/common"
 	"github.com/go-chassis/openlog"
 
 	"github.com/apache/servicecomb-kie/pkg/validator"
+	"github.com/apache/servicecomb-kie/server/cache"
 	"github.com/apache/servicecomb-kie/server/config"
 	"github.com/apache/servicecomb-kie/server/datasource"
 	"github.com/apache/servicecomb-kie/server/db"
@@ -30,22 +34,29 @@ import (
 	"github.com/apache/servicecomb-kie/server/pubsub"
 	"github.com/apache/servicecomb-kie/server/rbac"
 	v1 "github.com/apache/servicecomb-kie/server/resource/v1"
+	"github.com/apache/servicecomb-kie/server/service/outbox"
 )
 
 func Run() {
 	chassis.RegisterSchema(common.ProtocolRest, &v1.KVResource{})
 	chassis.RegisterSchema(common.ProtocolRest, &v1.HistoryResource{})
 	chassis.RegisterSchema(common.ProtocolRest, &v1.AdminResource{})
+	chassis.RegisterSchema(common.ProtocolRest, &v1.SchemaResource{})
+	chassis.RegisterSchema(common.ProtocolRest, &v1.LabelResource{})
 	if err := chassis.Init(); err != nil {
 		openlog.Fatal(err.Error())
 	}
 	if err := config.Init(); err != nil {
 		openlog.Fatal(err.Error())
 	}
+	if config.GetDB().RevisionBlock > 1 && config.Configurations.PeerAddr != "" {
+		openlog.Fatal("revisionBlock larger than 1 only works with a single kie node")
+	}
 	if err := db.Init(config.GetDB()); err != nil {
 		openlog.Fatal(err.Error())
 	}
-	if err := datasource.Init(config.GetDB().Kind); err != nil {
+	if err := datasource.Init(config.GetDB().Kind,
+		datasource.WithRevisionBlock(config.GetDB().RevisionBlock)); err != nil {
 		openlog.Fatal(err.Error())
 	}
 	if err := metrics.InitMetric(); err != nil {
@@ -54,10 +65,25 @@ func Run() {
 	if err := validator.Init(); err != nil {
 		openlog.Fatal("validate init failed: " + err.Error())
 	}
+	initCache()
 	rbac.Init()
 	pubsub.Init()
 	pubsub.Start()
+	outbox.Run()
 	if err := chassis.Run(); err != nil {
 		openlog.Fatal("service exit: " + err.Error())
 	}
 }
+
+func initCache() {
+	c := config.GetLongPolling()
+	ttl := cache.DefaultTTL
+	if c.CacheTTL != "" {
+		d, err := time.ParseDuration(c.CacheTTL)
+		if err != nil {
+			openlog.Fatal(fmt.Sprintf("invalid long polling cache ttl [%s]: %s", c.CacheTTL, err))
+		}
+		ttl = d
+	}
+	cache.Init(c.CacheSize, ttl)
+}
@@ -0,0 +1,160 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+// Package checker evaluates the check expression of a kv, every new value of the kv must satisfy it.
+// expressions are side effect free, they can only read the kv, and they run within a time, size and memory budget
+package checker
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+	"github.com/go-chassis/openlog"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/config"
+)
+
+// defaults of checker budget
+const (
+	DefaultTimeout      = 100 * time.Millisecond
+	DefaultMaxNodes     = 1000
+	DefaultMemoryBudget = 100000
+
+	maxCached = 1000
+)
+
+// errors of checker
+var (
+	ErrRejected = errors.New("value is rejected by checker")
+	ErrTimeout  = errors.New("checker exceeds the time budget")
+)
+
+// Env is what a checker can read, e.g. int(value) > 0 && labels.env != "production"
+type Env struct {
+	Key       string            `expr:"key"`
+	Value     string            `expr:"value"`
+	ValueType string            `expr:"value_type"`
+	Labels    map[string]string `expr:"labels"`
+}
+
+var (
+	mu       sync.RWMutex
+	programs = map[string]*vm.Program{}
+)
+
+// Compile compiles the checker, it must be an expression returning bool
+func Compile(checker string) (*vm.Program, error) {
+	mu.RLock()
+	p, ok := programs[checker]
+	mu.RUnlock()
+	if ok {
+		return p, nil
+	}
+	p, err := expr.Compile(checker,
+		expr.Env(Env{}),
+		expr.AsBool(),
+		expr.MaxNodes(maxNodes()),
+		expr.DisableBuiltin("now"),
+		expr.DisableBuiltin("date"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid checker: %w", err)
+	}
+	mu.Lock()
+	if len(programs) >= maxCached {
+		programs = map[string]*vm.Program{}
+	}
+	programs[checker] = p
+	mu.Unlock()
+	return p, nil
+}
+
+// Check return nil if the value of kv satisfies its checker, or the kv has no checker.
+// a stored checker which is not an expression, e.g. a python script of old versions, is skipped
+func Check(kv *model.KVDoc) error {
+	if kv.Checker == "" {
+		return nil
+	}
+	p, err := Compile(kv.Checker)
+	if err != nil {
+		openlog.Warn(fmt.Sprintf("checker of key [%s] is skipped: %s", kv.Key, err))
+		return nil
+	}
+	env := Env{
+		Key:       kv.Key,
+		Value:     kv.Value,
+		ValueType: kv.ValueType,
+		Labels:    kv.Labels,
+	}
+	if env.Labels == nil {
+		env.Labels = map[string]string{}
+	}
+	type result struct {
+		out interface{}
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		// the memory budget bounds every loop of the expression, so the evaluation always ends even after timeout
+		machine := &vm.VM{MemoryBudget: memoryBudget()}
+		out, err := machine.Run(p, env)
+		done <- result{out: out, err: err}
+	}()
+	select {
+	case r := <-done:
+		if r.err != nil {
+			return fmt.Errorf("%w: %s", ErrRejected, r.err)
+		}
+		if passed, _ := r.out.(bool); !passed {
+			return fmt.Errorf("%w: %s", ErrRejected, kv.Checker)
+		}
+		return nil
+	case <-time.After(timeout()):
+		openlog.Warn(fmt.Sprintf("checker of key [%s] exceeds the time budget", kv.Key))
+		return ErrTimeout
+	}
+}
+
+func timeout() time.Duration {
+	t := config.GetChecker().Timeout
+	if t == "" {
+		return DefaultTimeout
+	}
+	d, err := time.ParseDuration(t)
+	if err != nil || d <= 0 {
+		return DefaultTimeout
+	}
+	return d
+}
+
+func maxNodes() uint {
+	if n := config.GetChecker().MaxNodes; n > 0 {
+		return n
+	}
+	return DefaultMaxNodes
+}
+
+func memoryBudget() uint {
+	if n := config.GetChecker().MemoryBudget; n > 0 {
+		return n
+	}
+	return DefaultMemoryBudget
+}
@@ -0,0 +1,68 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package checker_test
+
+import (
+	"errors"
+	"testing"
+
+	_ "github.com/apache/servicecomb-kie/test"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/service/checker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	kv := func(value, check string) *model.KVDoc {
+		return &model.KVDoc{
+			Key:     "timeout",
+			Value:   value,
+			Labels:  map[string]string{"env": "test"},
+			Checker: check,
+		}
+	}
+	t.Run("no checker should pass", func(t *testing.T) {
+		assert.NoError(t, checker.Check(kv("abc", "")))
+	})
+	t.Run("value satisfies checker should pass", func(t *testing.T) {
+		assert.NoError(t, checker.Check(kv("500", "int(value) > 0 && int(value) < 1000")))
+		assert.NoError(t, checker.Check(kv("a", `labels.env == "test" && key startsWith "time"`)))
+	})
+	t.Run("value violates checker should be rejected", func(t *testing.T) {
+		err := checker.Check(kv("5000", "int(value) > 0 && int(value) < 1000"))
+		assert.True(t, errors.Is(err, checker.ErrRejected))
+		err = checker.Check(kv("abc", "int(value) > 0"))
+		assert.True(t, errors.Is(err, checker.ErrRejected))
+	})
+	t.Run("legacy python checker should be skipped", func(t *testing.T) {
+		assert.NoError(t, checker.Check(kv("abc", "def check(value):\n    return True")))
+	})
+	t.Run("checker exceeding memory budget should be rejected", func(t *testing.T) {
+		err := checker.Check(kv("a", "all(1..100000, {all(1..100000, {# > 0})})"))
+		assert.True(t, errors.Is(err, checker.ErrRejected))
+		assert.Contains(t, err.Error(), "memory budget exceeded")
+	})
+	t.Run("invalid checker should not compile", func(t *testing.T) {
+		_, err := checker.Compile("int(value) +")
+		assert.Error(t, err)
+		_, err = checker.Compile("int(value)")
+		assert.Error(t, err)
+		_, err = checker.Compile("now() > now()")
+		assert.Error(t, err)
+	})
+}
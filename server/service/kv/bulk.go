@@ -0,0 +1,223 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-chassis/cari/config"
+	"github.com/go-chassis/cari/pkg/errsvc"
+	"github.com/go-chassis/foundation/validator"
+	"github.com/go-chassis/openlog"
+
+	"github.com/apache/servicecomb-kie/pkg/common"
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/datasource"
+)
+
+// BulkUpdate patches the selected kvs under one revision, they are written in transactions of at most MaxTxnOps kvs,
+// a kv changed concurrently fails alone. in dry run, kvs are selected and checked without being written
+func BulkUpdate(ctx context.Context, request *model.BulkUpdateKVRequest) (*model.BulkResponse, *errsvc.Error) {
+	if err := validator.Validate(request); err != nil {
+		return nil, config.NewError(config.ErrInvalidParams, err.Error())
+	}
+	set := request.Set
+	if set.Value == nil && set.Status == "" && set.Priority == nil {
+		return nil, config.NewError(config.ErrInvalidParams, "nothing to set")
+	}
+	if len(request.IDs) == 0 && request.Key == "" && len(request.Labels) == 0 && len(request.Selector) == 0 {
+		return nil, config.NewError(config.ErrInvalidParams, "ids, key or labels are required to select kvs")
+	}
+	result := &model.BulkResponse{
+		Success: []*model.KVDoc{},
+		Failure: []*model.DocFailedOfUpload{},
+	}
+	kvs, svcErr := selectKVs(ctx, request, result)
+	if svcErr != nil {
+		return nil, svcErr
+	}
+	result.Total = len(kvs) + len(result.Failure)
+	var changes []*Change
+	for _, old := range kvs {
+		c, patchErr := patch(ctx, old, set)
+		if patchErr != nil {
+			appendBulkFailure(patchErr, old, result)
+			continue
+		}
+		if c == nil {
+			// the kv is already as expected
+			datasource.ClearPart(old)
+			result.Success = append(result.Success, old)
+			continue
+		}
+		changes = append(changes, c)
+	}
+	if request.DryRun || len(changes) == 0 {
+		for _, c := range changes {
+			datasource.ClearPart(c.New)
+			result.Success = append(result.Success, c.New)
+		}
+		return result, nil
+	}
+	var after int64
+	for _, c := range changes {
+		if c.Old.UpdateRevision > after {
+			after = c.Old.UpdateRevision
+		}
+	}
+	revision, err := applyRevision(ctx, request.Project, request.Domain, after)
+	if err != nil {
+		openlog.Error(err.Error())
+		return nil, config.NewError(config.ErrInternal, "bulk update failed")
+	}
+	result.Revision = revision
+	now := time.Now().Unix()
+	for _, c := range changes {
+		c.New.UpdateRevision = revision
+		c.New.UpdateTime = now
+	}
+	var written []*Change
+	for start := 0; start < len(changes); start += MaxTxnOps {
+		end := start + MaxTxnOps
+		if end > len(changes) {
+			end = len(changes)
+		}
+		written = append(written, writeChanges(ctx, changes[start:end], result)...)
+	}
+	if len(written) != 0 {
+		runAfter(ctx, &Change{Op: OpBulk, Changes: written})
+	}
+	for _, c := range written {
+		datasource.ClearPart(c.New)
+		result.Success = append(result.Success, c.New)
+	}
+	openlog.Info(fmt.Sprintf("bulk update %d of %d kvs in %s at revision %d", len(written), result.Total, request.Project, revision))
+	return result, nil
+}
+
+// selectKVs get kvs by ids, or list them by key and labels, ids not found are reported as failures
+func selectKVs(ctx context.Context, request *model.BulkUpdateKVRequest, result *model.BulkResponse) ([]*model.KVDoc, *errsvc.Error) {
+	var kvs []*model.KVDoc
+	if len(request.IDs) == 0 {
+		resp, err := List(ctx, request.Project, request.Domain,
+			datasource.WithKey(request.Key),
+			datasource.WithLabels(request.Labels),
+			datasource.WithSelector(request.Selector))
+		if err != nil {
+			openlog.Error("list kvs to update failed: " + err.Error())
+			return nil, config.NewError(config.ErrInternal, common.MsgDBError)
+		}
+		kvs = resp.Data
+	}
+	for _, id := range request.IDs {
+		kv, err := datasource.GetBroker().GetKVDao().Get(ctx, &model.GetKVRequest{
+			Domain:  request.Domain,
+			Project: request.Project,
+			ID:      id,
+		})
+		if err != nil {
+			if !errors.Is(err, datasource.ErrKeyNotExists) {
+				openlog.Error(fmt.Sprintf("get kv [%s] to update failed: %s", id, err))
+				return nil, config.NewError(config.ErrInternal, common.MsgDBError)
+			}
+			appendBulkFailure(config.NewError(config.ErrRecordNotExists, err.Error()), &model.KVDoc{ID: id}, result)
+			continue
+		}
+		kvs = append(kvs, kv)
+	}
+	for _, kv := range kvs {
+		kv.Domain = request.Domain
+		kv.Project = request.Project
+	}
+	return kvs, nil
+}
+
+// patch return the update change of the kv, it is nil if nothing is changed
+func patch(ctx context.Context, old *model.KVDoc, set model.KVPatch) (*Change, *errsvc.Error) {
+	updated := *old
+	if set.Value != nil {
+		updated.Value = *set.Value
+	}
+	if set.Status != "" {
+		updated.Status = set.Status
+	}
+	if set.Priority != nil {
+		updated.Priority = *set.Priority
+	}
+	if updated.Value == old.Value && updated.Status == old.Status && updated.Priority == old.Priority {
+		return nil, nil
+	}
+	c := &Change{Op: OpUpdate, Old: old, New: &updated}
+	if hookErr := runBefore(ctx, c); hookErr != nil {
+		return nil, hookErr
+	}
+	if c.New.Value != old.Value {
+		if valueErr, _ := checkValue(old.Project, c.New.ValueType, c.New.Value); valueErr != nil {
+			return nil, valueErr
+		}
+		if ruleErr := checkRules(ctx, c.New); ruleErr != nil {
+			return nil, ruleErr
+		}
+	}
+	return c, nil
+}
+
+// writeChanges writes the changes in one transaction, or one by one if any of them is changed concurrently
+func writeChanges(ctx context.Context, changes []*Change, result *model.BulkResponse) []*Change {
+	txn := &datasource.KVTxn{Revisions: make(map[string]int64, len(changes))}
+	for _, c := range changes {
+		txn.Updates = append(txn.Updates, c.New)
+		txn.Revisions[c.Old.ID] = c.Old.UpdateRevision
+	}
+	err := datasource.GetBroker().GetKVDao().Batch(ctx, txn, writeOptions(ctx)...)
+	if err == nil {
+		return changes
+	}
+	if !errors.Is(err, datasource.ErrKVConflict) || len(changes) == 1 {
+		openlog.Error("bulk update kvs failed: " + err.Error())
+		for _, c := range changes {
+			appendBulkFailure(writeError(err), c.Old, result)
+		}
+		return nil
+	}
+	var written []*Change
+	for _, c := range changes {
+		written = append(written, writeChanges(ctx, []*Change{c}, result)...)
+	}
+	return written
+}
+
+func writeError(err error) *errsvc.Error {
+	if errors.Is(err, datasource.ErrKVConflict) {
+		return config.NewError(config.ErrRecordAlreadyExists, err.Error())
+	}
+	return config.NewError(config.ErrInternal, common.MsgDBError)
+}
+
+func appendBulkFailure(err *errsvc.Error, kv *model.KVDoc, result *model.BulkResponse) {
+	result.Failure = append(result.Failure, &model.DocFailedOfUpload{
+		ID:      kv.ID,
+		Key:     kv.Key,
+		Labels:  kv.Labels,
+		ErrCode: err.Code,
+		ErrMsg:  err.Detail,
+	})
+}
@@ -0,0 +1,114 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-chassis/cari/config"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apache/servicecomb-kie/pkg/common"
+	"github.com/apache/servicecomb-kie/pkg/model"
+	kvsvc "github.com/apache/servicecomb-kie/server/service/kv"
+)
+
+func TestBulkUpdate(t *testing.T) {
+	ctx := context.TODO()
+	h := &txnHook{}
+	kvsvc.RegisterHook("bulk-test", h)
+	defer kvsvc.UnregisterHook("bulk-test")
+	labels := map[string]string{"app": "bulk-test"}
+	var ids []string
+	for _, key := range []string{"bulk.a", "bulk.b", "bulk.c"} {
+		kv, err := kvsvc.Create(ctx, &model.KVDoc{
+			Key:     key,
+			Value:   "1",
+			Labels:  labels,
+			Status:  common.StatusEnabled,
+			Domain:  domain,
+			Project: "bulk-test",
+		})
+		assert.Nil(t, err)
+		ids = append(ids, kv.ID)
+	}
+	h.changes = nil
+
+	t.Run("dry run, should report kvs without writing them", func(t *testing.T) {
+		resp, err := kvsvc.BulkUpdate(ctx, &model.BulkUpdateKVRequest{
+			Domain:  domain,
+			Project: "bulk-test",
+			Labels:  labels,
+			Set:     model.KVPatch{Status: common.StatusDisabled},
+			DryRun:  true,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 3, resp.Total)
+		assert.Equal(t, 3, len(resp.Success))
+		assert.Equal(t, int64(0), resp.Revision)
+		kv, getErr := kvsvc.Get(ctx, &model.GetKVRequest{ID: ids[0], Domain: domain, Project: "bulk-test"})
+		assert.NoError(t, getErr)
+		assert.Equal(t, common.StatusEnabled, kv.Status)
+		assert.Equal(t, 0, len(h.changes))
+	})
+	t.Run("disable kvs by labels, should share one revision", func(t *testing.T) {
+		resp, err := kvsvc.BulkUpdate(ctx, &model.BulkUpdateKVRequest{
+			Domain:  domain,
+			Project: "bulk-test",
+			Labels:  labels,
+			Set:     model.KVPatch{Status: common.StatusDisabled},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(resp.Success))
+		for _, kv := range resp.Success {
+			assert.Equal(t, common.StatusDisabled, kv.Status)
+			assert.Equal(t, resp.Revision, kv.UpdateRevision)
+		}
+		if assert.Equal(t, 1, len(h.changes)) {
+			assert.Equal(t, kvsvc.OpBulk, h.changes[0].Op)
+			assert.Equal(t, 3, len(h.changes[0].Changes))
+		}
+	})
+	t.Run("set priority by ids, should report ids not found", func(t *testing.T) {
+		priority := 5
+		resp, err := kvsvc.BulkUpdate(ctx, &model.BulkUpdateKVRequest{
+			Domain:  domain,
+			Project: "bulk-test",
+			IDs:     []string{ids[1], "not-exist"},
+			Set:     model.KVPatch{Priority: &priority},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, resp.Total)
+		if assert.Equal(t, 1, len(resp.Success)) {
+			assert.Equal(t, 5, resp.Success[0].Priority)
+		}
+		if assert.Equal(t, 1, len(resp.Failure)) {
+			assert.Equal(t, "not-exist", resp.Failure[0].ID)
+			assert.Equal(t, config.ErrRecordNotExists, resp.Failure[0].ErrCode)
+		}
+	})
+	t.Run("nothing to set, should be rejected", func(t *testing.T) {
+		_, err := kvsvc.BulkUpdate(ctx, &model.BulkUpdateKVRequest{
+			Domain:  domain,
+			Project: "bulk-test",
+			Labels:  labels,
+		})
+		assert.Equal(t, config.ErrInvalidParams, err.Code)
+	})
+}
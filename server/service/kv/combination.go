@@ -0,0 +1,87 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kv
+
+import (
+	"context"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/pkg/stringutil"
+	"github.com/apache/servicecomb-kie/server/datasource"
+)
+
+// listByCombinations return the union of kvs matching any of the label combinations,
+// each kv is tagged with the formatted combinations it matched, the union is paged after merging
+func listByCombinations(ctx context.Context, request *model.ListKVRequest, opts []datasource.FindOption) (*model.KVResponse, error) {
+	result := &model.KVResponse{
+		Data:    []*model.KVDoc{},
+		Matched: make(map[string][]string),
+	}
+	for _, labels := range request.Combinations {
+		combinationOpts := append(opts[:len(opts):len(opts)],
+			datasource.WithLabels(labels), datasource.WithOffset(0), datasource.WithLimit(0))
+		kvs, err := List(ctx, request.Project, request.Domain, combinationOpts...)
+		if err != nil {
+			return nil, err
+		}
+		combination := stringutil.FormatMap(labels)
+		for _, kv := range kvs.Data {
+			if _, ok := result.Matched[kv.ID]; !ok {
+				result.Data = append(result.Data, kv)
+			}
+			result.Matched[kv.ID] = append(result.Matched[kv.ID], combination)
+		}
+	}
+	result.Total = len(result.Data)
+	if request.Sort != "" || request.Cursor != "" {
+		// every combination returns all kvs after the cursor, page the union by cursor again
+		o := datasource.NewDefaultFindOpts()
+		for _, opt := range opts {
+			opt(&o)
+		}
+		page := datasource.NewCursorPage(o)
+		for _, kv := range result.Data {
+			page.Add(kv)
+		}
+		result.Data, result.NextCursor = page.Result()
+	} else {
+		datasource.ReverseByPriorityAndUpdateRev(result.Data)
+		result.Data = pageByOffset(result.Data, request.Offset, request.Limit)
+	}
+	matched := make(map[string][]string, len(result.Data))
+	for _, kv := range result.Data {
+		matched[kv.ID] = result.Matched[kv.ID]
+	}
+	result.Matched = matched
+	return result, nil
+}
+
+func pageByOffset(kvs []*model.KVDoc, offset, limit int64) []*model.KVDoc {
+	if limit == 0 {
+		return kvs
+	}
+	total := int64(len(kvs))
+	if offset >= total {
+		return []*model.KVDoc{}
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	return kvs[offset:end]
+}
@@ -0,0 +1,87 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/apache/servicecomb-kie/pkg/common"
+	"github.com/apache/servicecomb-kie/pkg/model"
+	kvsvc "github.com/apache/servicecomb-kie/server/service/kv"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListKV_Combinations(t *testing.T) {
+	ctx := context.TODO()
+	create := func(key string, labels map[string]string) *model.KVDoc {
+		kv, err := kvsvc.Create(ctx, &model.KVDoc{
+			Key:     key,
+			Value:   "1",
+			Status:  common.StatusEnabled,
+			Labels:  labels,
+			Domain:  domain,
+			Project: "combination-test",
+		})
+		assert.Nil(t, err)
+		return kv
+	}
+	app := map[string]string{"app": "mall"}
+	svc := map[string]string{"app": "mall", "service": "cart"}
+	appKV := create("timeout", app)
+	svcKV := create("timeout", svc)
+	otherKV := create("timeout", map[string]string{"app": "shop"})
+
+	t.Run("exact match should tag each kv with its combination", func(t *testing.T) {
+		_, resp, err := kvsvc.ListKV(ctx, &model.ListKVRequest{
+			Domain:       domain,
+			Project:      "combination-test",
+			Combinations: []map[string]string{app, svc},
+			Match:        common.PatternExact,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, resp.Total)
+		assert.Equal(t, []string{"app=mall"}, resp.Matched[appKV.ID])
+		assert.Equal(t, []string{"app=mall::service=cart"}, resp.Matched[svcKV.ID])
+		assert.NotContains(t, resp.Matched, otherKV.ID)
+	})
+	t.Run("kv matched by several combinations should be returned once", func(t *testing.T) {
+		_, resp, err := kvsvc.ListKV(ctx, &model.ListKVRequest{
+			Domain:       domain,
+			Project:      "combination-test",
+			Combinations: []map[string]string{app, svc},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, resp.Total)
+		assert.ElementsMatch(t, []string{"app=mall", "app=mall::service=cart"}, resp.Matched[svcKV.ID])
+	})
+	t.Run("union should be paged", func(t *testing.T) {
+		_, resp, err := kvsvc.ListKV(ctx, &model.ListKVRequest{
+			Domain:       domain,
+			Project:      "combination-test",
+			Combinations: []map[string]string{app, {"app": "shop"}},
+			Match:        common.PatternExact,
+			Offset:       1,
+			Limit:        1,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, resp.Total)
+		assert.Equal(t, 1, len(resp.Data))
+		assert.Equal(t, 1, len(resp.Matched))
+	})
+}
@@ -0,0 +1,109 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kv
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-chassis/cari/config"
+	"github.com/go-chassis/cari/pkg/errsvc"
+	"github.com/go-chassis/foundation/validator"
+	"github.com/go-chassis/openlog"
+
+	"github.com/apache/servicecomb-kie/pkg/common"
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/datasource"
+)
+
+// Copy copies the selected kvs to the target project with labels rewritten, for example
+// promotes kvs of env=staging to env=production, the copies are uploaded with the override strategy,
+// in dry run, the changes are previewed as diff without persisting them
+func Copy(ctx context.Context, request *model.CopyKVRequest) (*model.DocRespOfUpload, *errsvc.Error) {
+	if request.Override == "" {
+		request.Override = DefaultOverride
+	}
+	if SelectStrategy(request.Override) == nil {
+		return nil, config.NewError(config.ErrInvalidParams, "invalid override: "+request.Override)
+	}
+	if request.TargetProject == "" {
+		request.TargetProject = request.Project
+	}
+	if err := validator.Validate(request); err != nil {
+		return nil, config.NewError(config.ErrInvalidParams, err.Error())
+	}
+	if request.TargetProject == request.Project && len(request.Rewrite) == 0 {
+		return nil, config.NewError(config.ErrInvalidParams, "kvs can not be copied to themselves, rewrite labels or change the project")
+	}
+	opts := []datasource.FindOption{
+		datasource.WithKey(request.Key),
+		datasource.WithLabels(request.Labels),
+		datasource.WithSelector(request.Selector),
+	}
+	if request.Status != "" {
+		opts = append(opts, datasource.WithStatus(request.Status))
+	}
+	source, err := List(ctx, request.Project, request.Domain, opts...)
+	if err != nil {
+		openlog.Error("list kvs to copy failed: " + err.Error())
+		return nil, config.NewError(config.ErrInternal, common.MsgDBError)
+	}
+	kvs := make([]*model.KVDoc, 0, len(source.Data))
+	for _, kv := range source.Data {
+		copied := &model.KVDoc{
+			Key:       kv.Key,
+			Value:     kv.Value,
+			ValueType: kv.ValueType,
+			Status:    kv.Status,
+			Checker:   kv.Checker,
+			Priority:  kv.Priority,
+			Labels:    rewriteLabels(kv.Labels, request.Rewrite),
+		}
+		if checkChecker(copied) != nil {
+			// legacy checkers are skipped in the source, the copies leave them out
+			copied.Checker = ""
+		}
+		kvs = append(kvs, copied)
+	}
+	upload := &model.UploadKVRequest{
+		Domain:   request.Domain,
+		Project:  request.TargetProject,
+		KVs:      kvs,
+		Override: request.Override,
+	}
+	if request.DryRun {
+		return preview(ctx, upload), nil
+	}
+	openlog.Info(fmt.Sprintf("copy %d kvs from [%s] to [%s]", len(kvs), request.Project, request.TargetProject))
+	return Upload(ctx, upload), nil
+}
+
+func rewriteLabels(labels, rewrite map[string]string) map[string]string {
+	result := make(map[string]string, len(labels)+len(rewrite))
+	for k, v := range labels {
+		result[k] = v
+	}
+	for k, v := range rewrite {
+		if v == "" {
+			delete(result, k)
+			continue
+		}
+		result[k] = v
+	}
+	return result
+}
@@ -0,0 +1,103 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-chassis/cari/config"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apache/servicecomb-kie/pkg/common"
+	"github.com/apache/servicecomb-kie/pkg/model"
+	kvsvc "github.com/apache/servicecomb-kie/server/service/kv"
+)
+
+func TestCopy(t *testing.T) {
+	ctx := context.TODO()
+	create := func(project, key, value string, labels map[string]string) {
+		_, err := kvsvc.Create(ctx, &model.KVDoc{
+			Key:      key,
+			Value:    value,
+			Status:   common.StatusEnabled,
+			Priority: 2,
+			Labels:   labels,
+			Domain:   domain,
+			Project:  project,
+		})
+		assert.Nil(t, err)
+	}
+	staging := map[string]string{"app": "mall", "env": "staging"}
+	production := map[string]string{"app": "mall", "env": "production"}
+	create("copy-a", "timeout", "1s", staging)
+	create("copy-a", "retries", "3", staging)
+	create("copy-a", "retries", "5", production)
+	copyKV := func(request *model.CopyKVRequest) *model.DocRespOfUpload {
+		request.Domain = domain
+		request.Project = "copy-a"
+		request.Labels = map[string]string{"env": "staging"}
+		result, err := kvsvc.Copy(ctx, request)
+		assert.Nil(t, err)
+		return result
+	}
+	promote := map[string]string{"env": "production"}
+
+	t.Run("preview promoting kvs with force, should diff without persisting", func(t *testing.T) {
+		result := copyKV(&model.CopyKVRequest{Rewrite: promote, Override: "force", DryRun: true})
+		assert.Equal(t, 0, len(result.Failure))
+		assert.Equal(t, 2, len(result.Diff))
+		actions := map[string]*model.KVDiff{}
+		for _, d := range result.Diff {
+			assert.Equal(t, production, d.Labels)
+			actions[d.Key] = d
+		}
+		assert.Equal(t, model.DiffCreate, actions["timeout"].Action)
+		assert.Equal(t, model.DiffUpdate, actions["retries"].Action)
+		assert.Equal(t, "5", actions["retries"].OldValue)
+		assert.Equal(t, "3", actions["retries"].NewValue)
+
+		exist, err := kvsvc.Exist(ctx, "timeout", "copy-a", domain, production)
+		assert.NoError(t, err)
+		assert.False(t, exist)
+	})
+	t.Run("promote kvs with skip, should keep the existing kv", func(t *testing.T) {
+		result := copyKV(&model.CopyKVRequest{Rewrite: promote, Override: "skip"})
+		assert.Equal(t, 1, len(result.Success))
+		assert.Equal(t, 1, len(result.Failure))
+		assert.Equal(t, "retries", result.Failure[0].Key)
+		assert.Equal(t, config.ErrSkipDuplicateKV, result.Failure[0].ErrCode)
+
+		kvs, err := kvsvc.GetByKey(ctx, "retries", "copy-a", domain, production)
+		assert.NoError(t, err)
+		assert.Equal(t, "5", kvs[0].Value)
+	})
+	t.Run("copy kvs to another project, should create them with labels rewritten", func(t *testing.T) {
+		result := copyKV(&model.CopyKVRequest{TargetProject: "copy-b", Rewrite: map[string]string{"env": ""}})
+		assert.Equal(t, 2, len(result.Success))
+		assert.Equal(t, 0, len(result.Failure))
+		kvs, err := kvsvc.GetByKey(ctx, "timeout", "copy-b", domain, map[string]string{"app": "mall"})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(kvs))
+		assert.Equal(t, 2, kvs[0].Priority)
+	})
+	t.Run("copy kvs to themselves, should be rejected", func(t *testing.T) {
+		_, err := kvsvc.Copy(ctx, &model.CopyKVRequest{Domain: domain, Project: "copy-a"})
+		assert.Equal(t, config.ErrInvalidParams, err.Code)
+	})
+}
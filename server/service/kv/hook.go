@@ -0,0 +1,123 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kv
+
+import (
+	"context"
+	"sync"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/go-chassis/cari/pkg/errsvc"
+)
+
+// Op is the kind of kv mutation
+type Op string
+
+// kv mutations which hooks are called for
+const (
+	OpCreate Op = "create"
+	OpUpdate Op = "update"
+	OpDelete Op = "delete"
+	// OpRelabel moves Old to another label set as New
+	OpRelabel Op = "relabel"
+	// OpTxn applies Changes atomically, hooks are called before each of them and after all of them
+	OpTxn Op = "txn"
+	// OpBulk applies Changes under one revision, but not atomically
+	OpBulk Op = "bulk"
+)
+
+// Change describes a kv mutation.
+// Old is nil when creating, New is nil when deleting,
+// uploaded kvs are created or updated in any upload mode, a dry run upload only calls before hooks
+type Change struct {
+	Op  Op
+	Old *model.KVDoc
+	New *model.KVDoc
+	// Changes are the creations, updates and deletions of a transaction or bulk operation
+	Changes []*Change
+}
+
+// Hook is called before and after kv mutations.
+// Before can veto the change by returning an error, or mutate Change.New before it is saved,
+// After is called once the change is saved, it can not fail the change
+type Hook interface {
+	Before(ctx context.Context, c *Change) *errsvc.Error
+	After(ctx context.Context, c *Change)
+}
+
+type namedHook struct {
+	name string
+	hook Hook
+}
+
+var (
+	hooksMux sync.RWMutex
+	hooks    []namedHook
+)
+
+// RegisterHook add a hook, hooks are called in registration order,
+// register a hook with the same name replaces the old one
+func RegisterHook(name string, hook Hook) {
+	hooksMux.Lock()
+	defer hooksMux.Unlock()
+	// never modify the slice in place, hooks in use may be ranging it
+	for i, h := range hooks {
+		if h.name == name {
+			hs := make([]namedHook, len(hooks))
+			copy(hs, hooks)
+			hs[i].hook = hook
+			hooks = hs
+			return
+		}
+	}
+	hooks = append(hooks[:len(hooks):len(hooks)], namedHook{name: name, hook: hook})
+}
+
+// UnregisterHook remove a hook by name
+func UnregisterHook(name string) {
+	hooksMux.Lock()
+	defer hooksMux.Unlock()
+	for i, h := range hooks {
+		if h.name == name {
+			hooks = append(hooks[:i:i], hooks[i+1:]...)
+			return
+		}
+	}
+}
+
+func registeredHooks() []namedHook {
+	hooksMux.RLock()
+	defer hooksMux.RUnlock()
+	return hooks
+}
+
+// runBefore stops at the first hook which vetoes the change
+func runBefore(ctx context.Context, c *Change) *errsvc.Error {
+	for _, h := range registeredHooks() {
+		if err := h.hook.Before(ctx, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runAfter(ctx context.Context, c *Change) {
+	for _, h := range registeredHooks() {
+		h.hook.After(ctx, c)
+	}
+}
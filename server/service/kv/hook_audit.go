@@ -0,0 +1,61 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package kv
+
+import (
+	"context"
+
+	"github.com/go-chassis/cari/pkg/errsvc"
+	rbacmodel "github.com/go-chassis/cari/rbac"
+	"github.com/go-chassis/openlog"
+)
+
+func init() {
+	RegisterHook("audit", &Audit{})
+}
+
+// Audit logs who changed which kv
+type Audit struct {
+}
+
+func (a *Audit) Before(ctx context.Context, c *Change) *errsvc.Error {
+	return nil
+}
+
+func (a *Audit) After(ctx context.Context, c *Change) {
+	if c.Op == OpTxn || c.Op == OpBulk {
+		for _, sub := range c.Changes {
+			a.After(ctx, sub)
+		}
+		return
+	}
+	kv := c.New
+	if kv == nil {
+		kv = c.Old
+	}
+	user := ""
+	if account, err := rbacmodel.AccountFromContext(ctx); err == nil {
+		user = account.Name
+	}
+	openlog.Info("audit: kv "+string(c.Op), openlog.WithTags(openlog.Tags{
+		"user":    user,
+		"domain":  kv.Domain,
+		"project": kv.Project,
+		"kvID":    kv.ID,
+		"key":     kv.Key,
+	}))
+}
@@ -0,0 +1,74 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package kv
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/datasource"
+	"github.com/go-chassis/cari/pkg/errsvc"
+	"github.com/go-chassis/openlog"
+)
+
+func init() {
+	RegisterHook("history", &History{})
+}
+
+// History records revisions of kvs
+type History struct {
+}
+
+func (h *History) Before(ctx context.Context, c *Change) *errsvc.Error {
+	return nil
+}
+
+func (h *History) After(ctx context.Context, c *Change) {
+	switch c.Op {
+	case OpCreate, OpUpdate:
+		h.add(ctx, c.New)
+	case OpDelete:
+		h.delete(ctx, c.Old)
+	case OpRelabel:
+		// revisions of the old kv are already carried over to the new one
+		h.delete(ctx, c.Old)
+		h.add(ctx, c.New)
+	case OpTxn, OpBulk:
+		for _, sub := range c.Changes {
+			h.After(ctx, sub)
+		}
+	}
+}
+
+func (h *History) add(ctx context.Context, kv *model.KVDoc) {
+	err := datasource.GetBroker().GetHistoryDao().AddHistory(ctx, kv)
+	if err != nil {
+		openlog.Error(fmt.Sprintf("can not add revision for [%s] [%s] in [%s],err: %s",
+			kv.Key, kv.Labels, kv.Domain, err))
+		return
+	}
+	openlog.Debug(fmt.Sprintf("add history %s with labels %s length [%d]",
+		kv.Key, kv.Labels, len(kv.Value)))
+}
+
+func (h *History) delete(ctx context.Context, kv *model.KVDoc) {
+	err := datasource.GetBroker().GetHistoryDao().DelayDeletionTime(ctx, []string{kv.ID}, kv.Project, kv.Domain)
+	if err != nil {
+		openlog.Error(fmt.Sprintf("add delete time to [%s] failed : [%s]", kv.ID, err))
+	}
+}
@@ -0,0 +1,100 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package kv
+
+import (
+	"context"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/pkg/stringutil"
+	"github.com/apache/servicecomb-kie/server/pubsub"
+	"github.com/apache/servicecomb-kie/server/service/outbox"
+	"github.com/go-chassis/cari/pkg/errsvc"
+	"github.com/go-chassis/openlog"
+)
+
+func init() {
+	RegisterHook("publish", &Publisher{})
+}
+
+// Publisher publishes kv change events to the cluster
+type Publisher struct {
+}
+
+func (p *Publisher) Before(ctx context.Context, c *Change) *errsvc.Error {
+	return nil
+}
+
+func (p *Publisher) After(ctx context.Context, c *Change) {
+	if outbox.Enabled() {
+		// events are already in outbox, let the relay deliver them
+		outbox.Notify()
+		return
+	}
+	switch c.Op {
+	case OpCreate, OpUpdate:
+		publish(c.New, pubsub.ActionPut)
+	case OpDelete:
+		publish(c.Old, pubsub.ActionDelete)
+	case OpRelabel:
+		publish(c.Old, pubsub.ActionDelete)
+		publish(c.New, pubsub.ActionPut)
+	case OpTxn, OpBulk:
+		publishChanges(c.Changes)
+	}
+}
+
+// publishChanges coalesces events of the changes by label set, because watchers only care about labels,
+// so that they are woken up once by a transaction or bulk operation
+func publishChanges(changes []*Change) {
+	events := make(map[string]*model.KVDoc)
+	actions := make(map[string]string)
+	var ids []string
+	for _, c := range changes {
+		kv, action := c.New, pubsub.ActionPut
+		if kv == nil {
+			kv, action = c.Old, pubsub.ActionDelete
+		}
+		id := stringutil.FormatMap(kv.Labels)
+		if _, ok := events[id]; !ok {
+			ids = append(ids, id)
+			events[id] = kv
+			actions[id] = action
+			continue
+		}
+		if action == pubsub.ActionPut {
+			events[id] = kv
+			actions[id] = action
+		}
+	}
+	for _, id := range ids {
+		publish(events[id], actions[id])
+	}
+}
+
+func publish(kv *model.KVDoc, action string) {
+	err := pubsub.Publish(&pubsub.KVChangeEvent{
+		Key:      kv.Key,
+		Labels:   kv.Labels,
+		Project:  kv.Project,
+		DomainID: kv.Domain,
+		Action:   action,
+	})
+	if err != nil {
+		openlog.Warn("lost kv change event when " + action + ":" + err.Error())
+	}
+}
@@ -0,0 +1,177 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/apache/servicecomb-kie/pkg/common"
+	"github.com/apache/servicecomb-kie/pkg/model"
+	kvsvc "github.com/apache/servicecomb-kie/server/service/kv"
+	"github.com/go-chassis/cari/config"
+	"github.com/go-chassis/cari/pkg/errsvc"
+	"github.com/stretchr/testify/assert"
+)
+
+type policyHook struct {
+	changes []kvsvc.Change
+	befores []kvsvc.Op
+}
+
+func (p *policyHook) Before(ctx context.Context, c *kvsvc.Change) *errsvc.Error {
+	p.befores = append(p.befores, c.Op)
+	if c.New != nil && c.New.Key == "forbidden" {
+		return config.NewError(config.ErrInvalidParams, "key is forbidden")
+	}
+	if c.Old != nil && c.Old.Key == "undeletable" && c.Op == kvsvc.OpDelete {
+		return config.NewError(config.ErrInvalidParams, "key is undeletable")
+	}
+	if c.New != nil && c.New.Labels != nil {
+		c.New.Labels["owner"] = "policy"
+	}
+	return nil
+}
+
+func (p *policyHook) After(ctx context.Context, c *kvsvc.Change) {
+	p.changes = append(p.changes, *c)
+}
+
+func TestHook(t *testing.T) {
+	h := &policyHook{}
+	kvsvc.RegisterHook("policy", h)
+	defer kvsvc.UnregisterHook("policy")
+	labels := map[string]string{"app": "hook-test"}
+
+	t.Run("before hook vetoes create", func(t *testing.T) {
+		_, err := kvsvc.Create(context.TODO(), &model.KVDoc{
+			Key:     "forbidden",
+			Value:   "1",
+			Labels:  map[string]string{"app": "hook-test"},
+			Domain:  domain,
+			Project: project,
+		})
+		assert.Error(t, err)
+		assert.Equal(t, config.ErrInvalidParams, err.Code)
+		assert.Empty(t, h.changes)
+	})
+	t.Run("before hook mutates create, after hook sees old and new kv of update", func(t *testing.T) {
+		kv, err := kvsvc.Create(context.TODO(), &model.KVDoc{
+			Key:     "undeletable",
+			Value:   "1",
+			Status:  common.StatusEnabled,
+			Labels:  labels,
+			Domain:  domain,
+			Project: project,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "policy", kv.Labels["owner"])
+		_, updateErr := kvsvc.Update(context.TODO(), &model.UpdateKVRequest{
+			ID:      kv.ID,
+			Value:   "2",
+			Domain:  domain,
+			Project: project,
+		})
+		assert.NoError(t, updateErr)
+		assert.Equal(t, 2, len(h.changes))
+		assert.Equal(t, kvsvc.OpCreate, h.changes[0].Op)
+		assert.Equal(t, kvsvc.OpUpdate, h.changes[1].Op)
+		assert.Equal(t, "1", h.changes[1].Old.Value)
+		assert.Equal(t, "2", h.changes[1].New.Value)
+
+		_, deleteErr := kvsvc.FindOneAndDelete(context.TODO(), kv.ID, project, domain)
+		assert.Error(t, deleteErr)
+		_, getErr := kvsvc.Get(context.TODO(), &model.GetKVRequest{
+			Domain:  domain,
+			Project: project,
+			ID:      kv.ID,
+		})
+		assert.NoError(t, getErr)
+
+		_, deleteErr = kvsvc.FindManyAndDelete(context.TODO(), []string{kv.ID, "not-exist"}, project, domain)
+		assert.Error(t, deleteErr)
+		_, getErr = kvsvc.Get(context.TODO(), &model.GetKVRequest{
+			Domain:  domain,
+			Project: project,
+			ID:      kv.ID,
+		})
+		assert.NoError(t, getErr)
+	})
+	t.Run("upload calls before hooks once", func(t *testing.T) {
+		h.befores = nil
+		result := kvsvc.Upload(context.TODO(), &model.UploadKVRequest{
+			Domain:   domain,
+			Project:  project,
+			Override: "force",
+			KVs: []*model.KVDoc{
+				{Key: "upload-once", Value: "1", Labels: map[string]string{"app": "hook-test"}},
+			},
+		})
+		assert.Equal(t, 1, len(result.Success))
+		assert.Equal(t, []kvsvc.Op{kvsvc.OpCreate}, h.befores)
+
+		// the policy labels the created kv, upload it again with them to override it
+		h.befores = nil
+		result = kvsvc.Upload(context.TODO(), &model.UploadKVRequest{
+			Domain:   domain,
+			Project:  project,
+			Override: "force",
+			KVs: []*model.KVDoc{
+				{Key: "upload-once", Value: "2", Labels: map[string]string{"app": "hook-test", "owner": "policy"}},
+			},
+		})
+		if assert.Equal(t, 1, len(result.Success)) {
+			assert.Equal(t, "2", result.Success[0].Value)
+		}
+		assert.Equal(t, []kvsvc.Op{kvsvc.OpUpdate}, h.befores)
+	})
+	t.Run("atomic and dry run upload call the same before hooks as upload", func(t *testing.T) {
+		kvs := func() []*model.KVDoc {
+			return []*model.KVDoc{
+				{Key: "undeletable", Value: "3", Labels: map[string]string{"app": "hook-test", "owner": "policy"}},
+				{Key: "upload-atomic", Value: "1", Labels: map[string]string{"app": "hook-test"}},
+			}
+		}
+		for _, request := range []*model.UploadKVRequest{
+			{Domain: domain, Project: project, Override: "force", DryRun: true, KVs: kvs()},
+			{Domain: domain, Project: project, Override: "force", Atomic: true, KVs: kvs()},
+		} {
+			h.befores = nil
+			h.changes = nil
+			result := kvsvc.Upload(context.TODO(), request)
+			assert.Equal(t, 0, len(result.Failure))
+			assert.Equal(t, []kvsvc.Op{kvsvc.OpUpdate, kvsvc.OpCreate}, h.befores)
+		}
+		if assert.Equal(t, 2, len(h.changes)) {
+			assert.Equal(t, kvsvc.OpUpdate, h.changes[0].Op)
+			assert.Equal(t, kvsvc.OpCreate, h.changes[1].Op)
+			assert.Equal(t, "policy", h.changes[1].New.Labels["owner"])
+		}
+	})
+	t.Run("before hook vetoes upload", func(t *testing.T) {
+		result := kvsvc.Upload(context.TODO(), &model.UploadKVRequest{
+			Domain:   domain,
+			Project:  project,
+			Override: "force",
+			KVs: []*model.KVDoc{
+				{Key: "forbidden", Value: "1", Labels: map[string]string{"app": "hook-test"}},
+			},
+		})
+		assert.Equal(t, 0, len(result.Success))
+		assert.Equal(t, 1, len(result.Failure))
+	})
+}
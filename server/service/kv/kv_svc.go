@@ -33,6 +33,7 @@ import (
 	"github.com/apache/servicecomb-kie/pkg/stringutil"
 	"github.com/apache/servicecomb-kie/server/datasource"
 	"github.com/apache/servicecomb-kie/server/pubsub"
+	"github.com/apache/servicecomb-kie/server/service/outbox"
 	"github.com/apache/servicecomb-kie/server/service/sync"
 	"github.com/go-chassis/cari/config"
 	"github.com/go-chassis/cari/pkg/errsvc"
@@ -48,6 +49,7 @@ func ListKV(ctx context.Context, request *model.ListKVRequest) (int64, *model.KV
 		datasource.WithKey(request.Key),
 		datasource.WithValue(request.Value),
 		datasource.WithLabels(request.Labels),
+		datasource.WithSelector(request.Selector),
 		datasource.WithOffset(request.Offset),
 		datasource.WithLimit(request.Limit),
 	}
@@ -58,15 +60,31 @@ func ListKV(ctx context.Context, request *model.ListKVRequest) (int64, *model.KV
 	if request.Status != "" {
 		opts = append(opts, datasource.WithStatus(request.Status))
 	}
-	rev, err := datasource.GetBroker().GetRevisionDao().GetRevision(ctx, request.Domain)
+	sortOpts, sortErr := sortOptions(request)
+	if sortErr != nil {
+		return 0, nil, sortErr
+	}
+	opts = append(opts, sortOpts...)
+	rev, err := datasource.GetBroker().GetRevisionDao().GetProjectRevision(ctx, request.Project, request.Domain)
 	if err != nil {
 		return rev, nil, config.NewError(config.ErrInternal, err.Error())
 	}
-	kv, err := List(ctx, request.Project, request.Domain, opts...)
+	var kv *model.KVResponse
+	if len(request.Combinations) != 0 {
+		kv, err = listByCombinations(ctx, request, opts)
+	} else {
+		kv, err = List(ctx, request.Project, request.Domain, opts...)
+	}
 	if err != nil {
 		openlog.Error("common: " + err.Error())
 		return rev, nil, config.NewError(config.ErrInternal, common.MsgDBError)
 	}
+	if request.Resolve {
+		kv.Data, err = Resolve(ctx, request.Domain, request.Project, kv.Data)
+		if err != nil {
+			return rev, nil, config.NewError(config.ErrInternal, common.MsgDBError)
+		}
+	}
 	return rev, kv, nil
 }
 
@@ -77,10 +95,24 @@ func Create(ctx context.Context, kv *model.KVDoc) (*model.KVDoc, *errsvc.Error)
 	if kv.Status == "" {
 		kv.Status = common.StatusDisabled
 	}
+	change := &Change{Op: OpCreate, New: kv}
+	if hookErr := runBefore(ctx, change); hookErr != nil {
+		return nil, hookErr
+	}
+	kv = change.New
 	err := validator.Validate(kv)
 	if err != nil {
 		return nil, config.NewError(config.ErrInvalidParams, err.Error())
 	}
+	if valueErr, _ := checkValue(kv.Project, kv.ValueType, kv.Value); valueErr != nil {
+		return nil, valueErr
+	}
+	if checkerErr := checkChecker(kv); checkerErr != nil {
+		return nil, checkerErr
+	}
+	if ruleErr := checkRules(ctx, kv); ruleErr != nil {
+		return nil, ruleErr
+	}
 	err = quota.PreCreate(kv.Domain, kv.Project, "", 1)
 	if err != nil {
 		if err == quota.ErrReached {
@@ -114,7 +146,7 @@ func Create(ctx context.Context, kv *model.KVDoc) (*model.KVDoc, *errsvc.Error)
 	if exist {
 		return kv, config.NewError(config.ErrRecordAlreadyExists, datasource.ErrKVAlreadyExists.Error())
 	}
-	revision, err := datasource.GetBroker().GetRevisionDao().ApplyRevision(ctx, kv.Domain)
+	revision, err := datasource.GetBroker().GetRevisionDao().ApplyRevision(ctx, kv.Project, kv.Domain)
 	if err != nil {
 		openlog.Error(err.Error())
 		return nil, config.NewError(config.ErrInternal, "create kv failed")
@@ -125,7 +157,7 @@ func Create(ctx context.Context, kv *model.KVDoc) (*model.KVDoc, *errsvc.Error)
 		return nil, config.NewError(config.ErrInternal, "create kv failed")
 	}
 
-	kv, err = datasource.GetBroker().GetKVDao().Create(ctx, kv, datasource.WithSync(sync.FromContext(ctx)))
+	kv, err = datasource.GetBroker().GetKVDao().Create(ctx, kv, writeOptions(ctx)...)
 	if err != nil {
 		openlog.Error(fmt.Sprintf("post err:%s", err.Error()))
 		if errors.Is(err, datasource.ErrKVAlreadyExists) {
@@ -133,12 +165,7 @@ func Create(ctx context.Context, kv *model.KVDoc) (*model.KVDoc, *errsvc.Error)
 		}
 		return nil, util.SvcErr(err)
 	}
-	err = datasource.GetBroker().GetHistoryDao().AddHistory(ctx, kv)
-	if err != nil {
-		openlog.Warn(
-			fmt.Sprintf("can not updateKeyValue version for [%s] [%s] in [%s]",
-				kv.Key, kv.Labels, kv.Domain))
-	}
+	runAfter(ctx, &Change{Op: OpCreate, New: kv})
 	openlog.Debug(fmt.Sprintf("create %s with labels %s length [%d]", kv.Key, kv.Labels, len(kv.Value)))
 	datasource.ClearPart(kv)
 	return kv, nil
@@ -159,7 +186,20 @@ func completeKV(kv *model.KVDoc, revision int64) error {
 	return nil
 }
 
+// Upload uploads kvs with the override strategy one by one,
+// in dry run, the changes are previewed without persisting them, in atomic mode, all kvs are written or nothing
 func Upload(ctx context.Context, request *model.UploadKVRequest) *model.DocRespOfUpload {
+	if SelectStrategy(request.Override) == nil {
+		result := &model.DocRespOfUpload{Success: []*model.KVDoc{}, Failure: []*model.DocFailedOfUpload{}}
+		appendStoppedKVResult(request.KVs, "invalid override: "+request.Override, result)
+		return result
+	}
+	if request.DryRun {
+		return preview(ctx, request)
+	}
+	if request.Atomic {
+		return uploadAtomically(ctx, request)
+	}
 	override := request.Override
 	kvs := request.KVs
 	result := &model.DocRespOfUpload{
@@ -173,6 +213,15 @@ func Upload(ctx context.Context, request *model.UploadKVRequest) *model.DocRespO
 		}
 		kv.Domain = request.Domain
 		kv.Project = request.Project
+		if err := validator.Validate(kv); err != nil {
+			appendFailedKVResult(config.NewError(config.ErrInvalidParams, err.Error()), kv, result)
+			continue
+		}
+		if valueErr, se := checkValue(kv.Project, kv.ValueType, kv.Value); valueErr != nil {
+			appendSyntaxFailedKVResult(valueErr, se, kv, result)
+			continue
+		}
+		// the strategy creates or updates the kv, hooks are called there
 		kv, err := strategy.Execute(ctx, kv)
 		if err != nil {
 			if err.Code == config.ErrStopUpload {
@@ -182,8 +231,6 @@ func Upload(ctx context.Context, request *model.UploadKVRequest) *model.DocRespO
 			appendFailedKVResult(err, kv, result)
 			continue
 		}
-
-		Publish(kv)
 		result.Success = append(result.Success, kv)
 	}
 	return result
@@ -211,20 +258,6 @@ func appendAbortFailedKVResult(kvs []*model.KVDoc, result *model.DocRespOfUpload
 	}
 }
 
-func Publish(kv *model.KVDoc) {
-	err := pubsub.Publish(&pubsub.KVChangeEvent{
-		Key:      kv.Key,
-		Labels:   kv.Labels,
-		Project:  kv.Project,
-		DomainID: kv.Domain,
-		Action:   pubsub.ActionPut,
-	})
-	if err != nil {
-		openlog.Warn("lost kv change event when post:" + err.Error())
-	}
-	openlog.Info(fmt.Sprintf("post [%s] success", kv.ID))
-}
-
 // Update update key value and add new revision
 func Update(ctx context.Context, kv *model.UpdateKVRequest) (*model.KVDoc, error) {
 	oldKV, err := datasource.GetBroker().GetKVDao().Get(ctx, &model.GetKVRequest{
@@ -235,60 +268,97 @@ func Update(ctx context.Context, kv *model.UpdateKVRequest) (*model.KVDoc, error
 	if err != nil {
 		return nil, err
 	}
+	newKV := *oldKV
 	if kv.Status != "" {
-		oldKV.Status = kv.Status
+		newKV.Status = kv.Status
 	}
 	if kv.Value != "" {
-		oldKV.Value = kv.Value
+		newKV.Value = kv.Value
+	}
+	change := &Change{Op: OpUpdate, Old: oldKV, New: &newKV}
+	if hookErr := runBefore(ctx, change); hookErr != nil {
+		return nil, hookErr
+	}
+	updated := change.New
+	if updated.Value != oldKV.Value {
+		// values stored before are not checked, so that their status can still be changed
+		if valueErr, _ := checkValue(kv.Project, updated.ValueType, updated.Value); valueErr != nil {
+			return nil, valueErr
+		}
+		if ruleErr := checkRules(ctx, updated); ruleErr != nil {
+			return nil, ruleErr
+		}
 	}
-	oldKV.UpdateTime = time.Now().Unix()
-	oldKV.UpdateRevision, err = datasource.GetBroker().GetRevisionDao().ApplyRevision(ctx, kv.Domain)
+	updated.UpdateTime = time.Now().Unix()
+	updated.UpdateRevision, err = applyRevision(ctx, kv.Project, kv.Domain, oldKV.UpdateRevision)
 	if err != nil {
 		return nil, err
 	}
-	err = datasource.GetBroker().GetKVDao().Update(ctx, oldKV, datasource.WithSync(sync.FromContext(ctx)))
+	err = datasource.GetBroker().GetKVDao().Update(ctx, updated, writeOptions(ctx)...)
 	if err != nil {
 		return nil, err
 	}
 	openlog.Info(
 		fmt.Sprintf("update %s with labels %s length [%d]",
-			oldKV.Key, oldKV.Labels, len(kv.Value)))
-	err = datasource.GetBroker().GetHistoryDao().AddHistory(ctx, oldKV)
-	if err != nil {
-		openlog.Error(
-			fmt.Sprintf("can not add revision for [%s] [%s] in [%s],err: %s",
-				oldKV.Key, oldKV.Labels, kv.Domain, err))
-	}
-	openlog.Debug(
-		fmt.Sprintf("add history %s with labels %s length [%d]",
-			oldKV.Key, oldKV.Labels, len(oldKV.Value)))
-	datasource.ClearPart(oldKV)
-	return oldKV, nil
+			updated.Key, updated.Labels, len(kv.Value)))
+	runAfter(ctx, change)
+	datasource.ClearPart(updated)
+	return updated, nil
 
 }
 
 func FindOneAndDelete(ctx context.Context, kvID string, project, domain string) (*model.KVDoc, error) {
-	kv, err := datasource.GetBroker().GetKVDao().FindOneAndDelete(ctx, kvID, project, domain, datasource.WithSync(sync.FromContext(ctx)))
+	old, err := Get(ctx, &model.GetKVRequest{
+		Domain:  domain,
+		Project: project,
+		ID:      kvID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if hookErr := runBefore(ctx, &Change{Op: OpDelete, Old: old}); hookErr != nil {
+		return nil, hookErr
+	}
+	kv, err := datasource.GetBroker().GetKVDao().FindOneAndDelete(ctx, kvID, project, domain, writeOptions(ctx)...)
 	if err != nil {
 		return nil, err
 	}
 	openlog.Info(fmt.Sprintf("delete success,kvID=%s", kvID))
-	if _, err := datasource.GetBroker().GetRevisionDao().ApplyRevision(ctx, domain); err != nil {
+	if _, err := datasource.GetBroker().GetRevisionDao().ApplyRevision(ctx, project, domain); err != nil {
 		openlog.Error(fmt.Sprintf("the kv [%s] is deleted, but increase revision failed: [%s]", kvID, err))
 		return nil, err
 	}
-	err = datasource.GetBroker().GetHistoryDao().DelayDeletionTime(ctx, []string{kvID}, project, domain)
-	if err != nil {
-		openlog.Error(fmt.Sprintf("add delete time to [%s] failed : [%s]", kvID, err))
-	}
+	kv.Domain = domain
+	kv.Project = project
+	runAfter(ctx, &Change{Op: OpDelete, Old: kv})
 	return kv, nil
 }
 
+// applyRevision returns a revision larger than after, which is the revision of the kv to change
+func applyRevision(ctx context.Context, project, domain string, after int64) (int64, error) {
+	dao := datasource.GetBroker().GetRevisionDao()
+	rev, err := dao.ApplyRevision(ctx, project, domain)
+	if err != nil || rev > after {
+		return rev, err
+	}
+	datasource.Revisions().Drop(domain)
+	return dao.ApplyRevision(ctx, project, domain)
+}
+
 func FindManyAndDelete(ctx context.Context, kvIDs []string, project, domain string) ([]*model.KVDoc, error) {
-	var kvs []*model.KVDoc
-	var deleted int64
-	var err error
-	kvs, deleted, err = datasource.GetBroker().GetKVDao().FindManyAndDelete(ctx, kvIDs, project, domain, datasource.WithSync(sync.FromContext(ctx)))
+	// kvs not found are not deleted, so that no hook vetoes them
+	olds, err := datasource.GetBroker().GetKVDao().List(ctx, project, domain, datasource.WithIDs(kvIDs))
+	if err != nil {
+		return nil, err
+	}
+	for _, old := range olds.Data {
+		old.Domain = domain
+		old.Project = project
+		if hookErr := runBefore(ctx, &Change{Op: OpDelete, Old: old}); hookErr != nil {
+			return nil, hookErr
+		}
+	}
+	kvs, deleted, err := datasource.GetBroker().GetKVDao().FindManyAndDelete(ctx, kvIDs, project, domain, writeOptions(ctx)...)
 	if err != nil {
 		return nil, err
 	}
@@ -297,17 +367,28 @@ func FindManyAndDelete(ctx context.Context, kvIDs []string, project, domain stri
 	} else {
 		openlog.Info(fmt.Sprintf("deleted %d kvs, their ids are %v", deleted, kvIDs))
 	}
-	if _, err := datasource.GetBroker().GetRevisionDao().ApplyRevision(ctx, domain); err != nil {
+	if _, err := datasource.GetBroker().GetRevisionDao().ApplyRevision(ctx, project, domain); err != nil {
 		openlog.Error(fmt.Sprintf("kvs [%v] are deleted, but increase revision failed: [%v]", kvIDs, err))
 		return nil, err
 	}
-	err = datasource.GetBroker().GetHistoryDao().DelayDeletionTime(ctx, kvIDs, project, domain)
-	if err != nil {
-		openlog.Error(fmt.Sprintf("add delete time to kvs [%s] failed : [%s]", kvIDs, err))
+	for _, kv := range kvs {
+		kv.Domain = domain
+		kv.Project = project
+		runAfter(ctx, &Change{Op: OpDelete, Old: kv})
 	}
 	return kvs, nil
 }
 
+// writeOptions return options of kv mutations,
+// if outbox is enabled, the change event is written in the same transaction and delivered by the local node
+func writeOptions(ctx context.Context) []datasource.WriteOption {
+	opts := []datasource.WriteOption{datasource.WithSync(sync.FromContext(ctx))}
+	if outbox.Enabled() {
+		opts = append(opts, datasource.WithOutbox(pubsub.LocalNode()))
+	}
+	return opts
+}
+
 func Get(ctx context.Context, req *model.GetKVRequest) (*model.KVDoc, error) {
 	return datasource.GetBroker().GetKVDao().Get(ctx, req)
 }
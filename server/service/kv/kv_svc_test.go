@@ -25,6 +25,7 @@ import (
 
 	"github.com/apache/servicecomb-kie/pkg/common"
 	"github.com/apache/servicecomb-kie/pkg/model"
+	kieconfig "github.com/apache/servicecomb-kie/server/config"
 	"github.com/apache/servicecomb-kie/server/datasource"
 	kvsvc "github.com/apache/servicecomb-kie/server/service/kv"
 	"github.com/go-chassis/cari/config"
@@ -187,6 +188,29 @@ func TestService_Update(t *testing.T) {
 		})
 		assert.NoError(t, err)
 	})
+	t.Run("update kv without value_type in strict mode should be rejected", func(t *testing.T) {
+		kv, err := datasource.GetBroker().GetKVDao().Create(context.TODO(), &model.KVDoc{
+			ID:          "strict-update",
+			Key:         "strict-update",
+			Value:       "1",
+			Labels:      map[string]string{"app": "strict"},
+			LabelFormat: "app=strict",
+			Domain:      domain,
+			Project:     "kv-strict",
+		})
+		assert.NoError(t, err)
+		kieconfig.Configurations.ValueType.StrictProjects = []string{"kv-strict"}
+		defer func() {
+			kieconfig.Configurations.ValueType.StrictProjects = nil
+		}()
+		_, err = kvsvc.Update(context.TODO(), &model.UpdateKVRequest{
+			ID:      kv.ID,
+			Value:   "2",
+			Domain:  domain,
+			Project: "kv-strict",
+		})
+		assert.Error(t, err)
+	})
 }
 
 func TestService_Delete(t *testing.T) {
@@ -19,9 +19,15 @@ package kv
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
+	"github.com/apache/servicecomb-kie/pkg/common"
 	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/datasource"
+	"github.com/go-chassis/cari/config"
 	"github.com/go-chassis/cari/pkg/errsvc"
+	"github.com/go-chassis/openlog"
 )
 
 var strategyMap = make(map[string]OverrideStrategy)
@@ -37,3 +43,20 @@ func RegisterStrategy(override string, strategy OverrideStrategy) {
 func SelectStrategy(override string) OverrideStrategy {
 	return strategyMap[override]
 }
+
+// findExisting return the kv of the same key and labels, or nil if there is none,
+// strategies look it up before creating, so that hooks are called once, either for creating or for updating
+func findExisting(ctx context.Context, kv *model.KVDoc) (*model.KVDoc, *errsvc.Error) {
+	kvs, err := GetByKey(ctx, kv.Key, kv.Project, kv.Domain, kv.Labels)
+	if err != nil {
+		if errors.Is(err, datasource.ErrKeyNotExists) {
+			return nil, nil
+		}
+		openlog.Error(fmt.Sprintf("get record [key: %s, labels: %s] failed", kv.Key, kv.Labels))
+		return nil, config.NewError(config.ErrInternal, common.MsgDBError)
+	}
+	if len(kvs) == 0 {
+		return nil, nil
+	}
+	return kvs[0], nil
+}
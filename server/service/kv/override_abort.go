@@ -37,13 +37,19 @@ type Abort struct {
 
 func (a *Abort) Execute(ctx context.Context, kv *model.KVDoc) (*model.KVDoc, *errsvc.Error) {
 	inputKV := kv
-	kv, err := Create(ctx, kv)
-	if err == nil {
-		return kv, nil
+	old, err := findExisting(ctx, inputKV)
+	if err != nil {
+		return inputKV, err
 	}
-	if err.Code == config.ErrRecordAlreadyExists {
-		openlog.Info(fmt.Sprintf("stop overriding duplicate [key: %s, labels: %s]", inputKV.Key, inputKV.Labels))
-		return inputKV, config.NewError(config.ErrStopUpload, "stop overriding duplicate kv")
+	if old == nil {
+		kv, err = Create(ctx, inputKV)
+		if err == nil {
+			return kv, nil
+		}
+		if err.Code != config.ErrRecordAlreadyExists {
+			return inputKV, err
+		}
 	}
-	return inputKV, err
+	openlog.Info(fmt.Sprintf("stop overriding duplicate [key: %s, labels: %s]", inputKV.Key, inputKV.Labels))
+	return inputKV, config.NewError(config.ErrStopUpload, "stop overriding duplicate kv")
 }
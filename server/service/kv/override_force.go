@@ -23,7 +23,6 @@ import (
 
 	"github.com/apache/servicecomb-kie/pkg/util"
 
-	"github.com/go-chassis/cari/config"
 	"github.com/go-chassis/cari/pkg/errsvc"
 	"github.com/go-chassis/openlog"
 
@@ -37,23 +36,23 @@ func init() {
 type Force struct {
 }
 
+// Execute creates the kv, or updates the existing one,
+// it is decided before calling hooks, so that a kv is either checked as created or as updated
 func (f *Force) Execute(ctx context.Context, kv *model.KVDoc) (*model.KVDoc, *errsvc.Error) {
 	input := kv
-	kv, err := Create(ctx, kv)
-	if err == nil {
-		return kv, nil
-	}
-	if err.Code != config.ErrRecordAlreadyExists {
+	old, err := findExisting(ctx, input)
+	if err != nil {
 		return input, err
 	}
-
-	getKvsByOpts, getKvErr := GetByKey(ctx, input.Key, input.Project, input.Domain, input.Labels)
-	if getKvErr != nil {
-		openlog.Info(fmt.Sprintf("get record [key: %s, labels: %s] failed", input.Key, input.Labels))
-		return input, util.SvcErr(getKvErr)
+	if old == nil {
+		kv, err = Create(ctx, input)
+		if err != nil {
+			return input, err
+		}
+		return kv, nil
 	}
 	kvReq := &model.UpdateKVRequest{
-		ID:      getKvsByOpts[0].ID,
+		ID:      old.ID,
 		Value:   input.Value,
 		Status:  input.Status,
 		Project: input.Project,
@@ -37,13 +37,19 @@ type Skip struct {
 
 func (s *Skip) Execute(ctx context.Context, kv *model.KVDoc) (*model.KVDoc, *errsvc.Error) {
 	inputKV := kv
-	kv, err := Create(ctx, kv)
-	if err == nil {
-		return kv, nil
+	old, err := findExisting(ctx, inputKV)
+	if err != nil {
+		return inputKV, err
 	}
-	if err.Code == config.ErrRecordAlreadyExists {
-		openlog.Info(fmt.Sprintf("skip overriding duplicate [key: %s, labels: %s]", inputKV.Key, inputKV.Labels))
-		return inputKV, config.NewError(config.ErrSkipDuplicateKV, "skip overriding duplicate kvs")
+	if old == nil {
+		kv, err = Create(ctx, inputKV)
+		if err == nil {
+			return kv, nil
+		}
+		if err.Code != config.ErrRecordAlreadyExists {
+			return inputKV, err
+		}
 	}
-	return inputKV, err
+	openlog.Info(fmt.Sprintf("skip overriding duplicate [key: %s, labels: %s]", inputKV.Key, inputKV.Labels))
+	return inputKV, config.NewError(config.ErrSkipDuplicateKV, "skip overriding duplicate kvs")
 }
@@ -0,0 +1,115 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-chassis/cari/config"
+	"github.com/go-chassis/cari/pkg/errsvc"
+	"github.com/go-chassis/foundation/validator"
+	"github.com/go-chassis/openlog"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/pkg/stringutil"
+	"github.com/apache/servicecomb-kie/pkg/util"
+	"github.com/apache/servicecomb-kie/server/datasource"
+)
+
+// DefaultOverride is the override strategy when the key already exists in the target label set
+const DefaultOverride = "abort"
+
+// Relabel moves the kv to another label set, the kv is created under the new label set
+// and the old one is removed in a transaction, the history of the kv is carried over.
+// if the key already exists in the label set, abort fails the move, skip leaves both kvs untouched,
+// force overwrites the existing kv
+func Relabel(ctx context.Context, request *model.RelabelKVRequest) (*model.KVDoc, *errsvc.Error) {
+	if request.Override == "" {
+		request.Override = DefaultOverride
+	}
+	if SelectStrategy(request.Override) == nil {
+		return nil, config.NewError(config.ErrInvalidParams, "invalid override: "+request.Override)
+	}
+	if err := validator.Validate(request); err != nil {
+		return nil, config.NewError(config.ErrInvalidParams, err.Error())
+	}
+	old, err := Get(ctx, &model.GetKVRequest{Domain: request.Domain, Project: request.Project, ID: request.ID})
+	if err != nil {
+		if errors.Is(err, datasource.ErrKeyNotExists) {
+			return nil, config.NewError(config.ErrRecordNotExists, err.Error())
+		}
+		return nil, util.SvcErr(err)
+	}
+	old.Domain = request.Domain
+	old.Project = request.Project
+	kv := *old
+	kv.Labels = request.Labels
+	if kv.Labels == nil {
+		kv.Labels = map[string]string{}
+	}
+	kv.LabelFormat = stringutil.FormatMap(kv.Labels)
+	if kv.LabelFormat == old.LabelFormat {
+		return nil, config.NewError(config.ErrInvalidParams, "the kv already has the labels")
+	}
+	change := &Change{Op: OpRelabel, Old: old, New: &kv}
+	if hookErr := runBefore(ctx, change); hookErr != nil {
+		return nil, hookErr
+	}
+	moved := change.New
+	if ruleErr := checkRules(ctx, moved); ruleErr != nil {
+		return nil, ruleErr
+	}
+	revision, err := applyRevision(ctx, request.Project, request.Domain, old.UpdateRevision)
+	if err != nil {
+		openlog.Error(err.Error())
+		return nil, config.NewError(config.ErrInternal, "relabel kv failed")
+	}
+	// a moved kv keeps its creation, but it is identified by the new labels
+	createRevision, createTime := moved.CreateRevision, moved.CreateTime
+	if err := completeKV(moved, revision); err != nil {
+		return nil, config.NewError(config.ErrInternal, "relabel kv failed")
+	}
+	moved.CreateRevision, moved.CreateTime = createRevision, createTime
+	moved.UpdateTime = time.Now().Unix()
+
+	// the history is carried over in the move, it fails if the kv is changed since it was read
+	err = datasource.GetBroker().GetKVDao().Move(ctx, old, moved, request.Override == "force", writeOptions(ctx)...)
+	if err != nil {
+		return nil, relabelError(err, request.Override)
+	}
+	openlog.Info(fmt.Sprintf("relabel %s from %s to %s", moved.Key, old.LabelFormat, moved.LabelFormat))
+	runAfter(ctx, change)
+	datasource.ClearPart(moved)
+	return moved, nil
+}
+
+func relabelError(err error, override string) *errsvc.Error {
+	switch {
+	case errors.Is(err, datasource.ErrKeyNotExists):
+		return config.NewError(config.ErrRecordNotExists, err.Error())
+	case errors.Is(err, datasource.ErrKVAlreadyExists) && override == "skip":
+		return config.NewError(config.ErrSkipDuplicateKV, "skip overriding duplicate kv")
+	case errors.Is(err, datasource.ErrKVAlreadyExists), errors.Is(err, datasource.ErrKVConflict):
+		return config.NewError(config.ErrRecordAlreadyExists, err.Error())
+	}
+	openlog.Error("relabel kv failed: " + err.Error())
+	return util.SvcErr(err)
+}
@@ -0,0 +1,133 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-chassis/cari/config"
+	"github.com/go-chassis/cari/pkg/errsvc"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apache/servicecomb-kie/pkg/common"
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/datasource"
+	kvsvc "github.com/apache/servicecomb-kie/server/service/kv"
+)
+
+// concurrentUpdate updates the kv to relabel after it is read, before it is moved
+type concurrentUpdate struct {
+}
+
+func (u *concurrentUpdate) Before(ctx context.Context, c *kvsvc.Change) *errsvc.Error {
+	if c.Op != kvsvc.OpRelabel {
+		return nil
+	}
+	_, err := kvsvc.Update(ctx, &model.UpdateKVRequest{ID: c.Old.ID, Value: "4s", Domain: c.Old.Domain, Project: c.Old.Project})
+	if err != nil {
+		return config.NewError(config.ErrInternal, err.Error())
+	}
+	return nil
+}
+
+func (u *concurrentUpdate) After(ctx context.Context, c *kvsvc.Change) {
+}
+
+func TestRelabel(t *testing.T) {
+	ctx := context.TODO()
+	project := "relabel-test"
+	create := func(labels map[string]string, value string) *model.KVDoc {
+		kv, err := kvsvc.Create(ctx, &model.KVDoc{
+			Key:     "timeout",
+			Value:   value,
+			Status:  common.StatusEnabled,
+			Labels:  labels,
+			Domain:  domain,
+			Project: project,
+		})
+		assert.Nil(t, err)
+		return kv
+	}
+	relabel := func(id string, labels map[string]string, override string) (*model.KVDoc, *errsvc.Error) {
+		return kvsvc.Relabel(ctx, &model.RelabelKVRequest{
+			ID:       id,
+			Domain:   domain,
+			Project:  project,
+			Labels:   labels,
+			Override: override,
+		})
+	}
+	gray := map[string]string{"env": "gray"}
+	prod := map[string]string{"env": "prod"}
+	old := create(gray, "1s")
+	_, updateErr := kvsvc.Update(ctx, &model.UpdateKVRequest{ID: old.ID, Value: "2s", Domain: domain, Project: project})
+	assert.NoError(t, updateErr)
+
+	t.Run("move kv to another label set, should carry history over", func(t *testing.T) {
+		kv, svcErr := relabel(old.ID, prod, "")
+		assert.Nil(t, svcErr)
+		assert.NotEqual(t, old.ID, kv.ID)
+		assert.Equal(t, prod, kv.Labels)
+		assert.Equal(t, "2s", kv.Value)
+		assert.Equal(t, old.CreateRevision, kv.CreateRevision)
+
+		exist, err := datasource.GetBroker().GetKVDao().Exist(ctx, "timeout", project, domain, datasource.WithLabelFormat("env=gray"))
+		assert.NoError(t, err)
+		assert.False(t, exist)
+
+		histories, err := datasource.GetBroker().GetHistoryDao().GetHistory(ctx, kv.ID, project, domain)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, len(histories.Data))
+	})
+	t.Run("move kv to a label set holding the key, should follow override", func(t *testing.T) {
+		kv := create(gray, "3s")
+		_, err := relabel(kv.ID, prod, "abort")
+		assert.Equal(t, config.ErrRecordAlreadyExists, err.Code)
+		_, err = relabel(kv.ID, prod, "skip")
+		assert.Equal(t, config.ErrSkipDuplicateKV, err.Code)
+		_, err = relabel(kv.ID, prod, "unknown")
+		assert.Equal(t, config.ErrInvalidParams, err.Code)
+
+		moved, err := relabel(kv.ID, prod, "force")
+		assert.Nil(t, err)
+		assert.Equal(t, "3s", moved.Value)
+		got, getErr := kvsvc.Get(ctx, &model.GetKVRequest{ID: moved.ID, Domain: domain, Project: project})
+		assert.NoError(t, getErr)
+		assert.Equal(t, "3s", got.Value)
+	})
+	t.Run("move a kv updated after it is read, should conflict and keep the update", func(t *testing.T) {
+		kv := create(map[string]string{"env": "dev"}, "1s")
+		kvsvc.RegisterHook("concurrent-update", &concurrentUpdate{})
+		_, err := relabel(kv.ID, map[string]string{"env": "test"}, "")
+		kvsvc.UnregisterHook("concurrent-update")
+		if assert.NotNil(t, err) {
+			assert.Equal(t, config.ErrRecordAlreadyExists, err.Code)
+		}
+		got, getErr := kvsvc.Get(ctx, &model.GetKVRequest{ID: kv.ID, Domain: domain, Project: project})
+		assert.NoError(t, getErr)
+		assert.Equal(t, "4s", got.Value)
+		exist, existErr := datasource.GetBroker().GetKVDao().Exist(ctx, "timeout", project, domain, datasource.WithLabelFormat("env=test"))
+		assert.NoError(t, existErr)
+		assert.False(t, exist)
+	})
+	t.Run("move a kv not exists, should return not found", func(t *testing.T) {
+		_, err := relabel("not-exist", prod, "")
+		assert.Equal(t, config.ErrRecordNotExists, err.Code)
+	})
+}
@@ -0,0 +1,132 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package kv
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-chassis/openlog"
+
+	"github.com/apache/servicecomb-kie/pkg/common"
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/pkg/util"
+	"github.com/apache/servicecomb-kie/server/datasource"
+)
+
+// MaxResolveDepth is the max depth of nested references
+const MaxResolveDepth = 10
+
+// refRegex matches ${key} and the escaped $${key}
+var refRegex = regexp.MustCompile(`\$?\$\{([a-zA-Z0-9._:-]+)\}`)
+
+// resolver resolves references of values in a domain and project,
+// kvs of a key are queried once per resolver
+type resolver struct {
+	ctx     context.Context
+	domain  string
+	project string
+	byKey   map[string][]*model.KVDoc
+	err     error
+}
+
+// Resolve return copies of kvs whose references like ${db.host} in values are replaced by values of the referenced kvs,
+// a kv can reference enabled kvs whose labels are a subset of its labels, if there are several ones,
+// the first one in the order of datasource.ReverseByPriorityAndUpdateRev wins.
+// references which are missing, cyclic or deeper than MaxResolveDepth are left as they are,
+// and $${key} is unescaped to ${key}. the raw values of kvs are never changed
+func Resolve(ctx context.Context, domain, project string, kvs []*model.KVDoc) ([]*model.KVDoc, error) {
+	r := &resolver{
+		ctx:     ctx,
+		domain:  domain,
+		project: project,
+		byKey:   make(map[string][]*model.KVDoc),
+	}
+	resolved := make([]*model.KVDoc, 0, len(kvs))
+	for _, kv := range kvs {
+		if !strings.Contains(kv.Value, "${") {
+			resolved = append(resolved, kv)
+			continue
+		}
+		c := *kv
+		c.Value = r.resolve(kv.Value, kv.Labels, 0, map[string]bool{kv.Key: true})
+		if r.err != nil {
+			return nil, r.err
+		}
+		resolved = append(resolved, &c)
+	}
+	return resolved, nil
+}
+
+func (r *resolver) resolve(value string, labels map[string]string, depth int, visiting map[string]bool) string {
+	return refRegex.ReplaceAllStringFunc(value, func(ref string) string {
+		if r.err != nil {
+			return ref
+		}
+		if strings.HasPrefix(ref, "$$") {
+			return ref[1:]
+		}
+		name := ref[2 : len(ref)-1]
+		if visiting[name] {
+			openlog.Warn(fmt.Sprintf("reference [%s] is cyclic", name))
+			return ref
+		}
+		if depth >= MaxResolveDepth {
+			openlog.Warn(fmt.Sprintf("reference [%s] exceeds the max depth %d", name, MaxResolveDepth))
+			return ref
+		}
+		kv := r.lookup(name, labels)
+		if kv == nil {
+			return ref
+		}
+		visiting[name] = true
+		v := r.resolve(kv.Value, labels, depth+1, visiting)
+		delete(visiting, name)
+		return v
+	})
+}
+
+// lookup return the referenced kv visible to kvs with the labels
+func (r *resolver) lookup(key string, labels map[string]string) *model.KVDoc {
+	candidates, ok := r.byKey[key]
+	if !ok {
+		resp, err := List(r.ctx, r.project, r.domain,
+			datasource.WithKey(key),
+			datasource.WithCaseSensitive(),
+			datasource.WithStatus(common.StatusEnabled))
+		if err != nil {
+			openlog.Error(fmt.Sprintf("list kvs of reference [%s] failed: %s", key, err))
+			r.err = err
+			return nil
+		}
+		for _, kv := range resp.Data {
+			if kv.Key == key {
+				candidates = append(candidates, kv)
+			}
+		}
+		datasource.ReverseByPriorityAndUpdateRev(candidates)
+		r.byKey[key] = candidates
+	}
+	for _, kv := range candidates {
+		if util.IsContainLabel(labels, kv.Labels) {
+			return kv
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/apache/servicecomb-kie/pkg/common"
+	"github.com/apache/servicecomb-kie/pkg/model"
+	kvsvc "github.com/apache/servicecomb-kie/server/service/kv"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolve(t *testing.T) {
+	ctx := context.TODO()
+	create := func(key, value string, labels map[string]string) {
+		_, err := kvsvc.Create(ctx, &model.KVDoc{
+			Key:     key,
+			Value:   value,
+			Status:  common.StatusEnabled,
+			Labels:  labels,
+			Domain:  domain,
+			Project: "resolve-test",
+		})
+		assert.Nil(t, err)
+	}
+	app := map[string]string{"app": "mall"}
+	svc := map[string]string{"app": "mall", "service": "cart"}
+	create("db.host", "10.0.0.1", app)
+	create("db.port", "3306", app)
+	create("db.url", "${db.host}:${db.port}", app)
+	create("db.dsn", "mysql://${db.url}/cart?escaped=$${db.host}&missing=${db.user}", svc)
+	create("cycle.a", "${cycle.b}", svc)
+	create("cycle.b", "${cycle.a}", svc)
+
+	_, resp, err := kvsvc.ListKV(ctx, &model.ListKVRequest{
+		Domain:  domain,
+		Project: "resolve-test",
+		Labels:  svc,
+		Match:   common.PatternExact,
+		Resolve: true,
+	})
+	assert.Nil(t, err)
+	values := map[string]string{}
+	for _, kv := range resp.Data {
+		values[kv.Key] = kv.Value
+	}
+	assert.Equal(t, "mysql://10.0.0.1:3306/cart?escaped=${db.host}&missing=${db.user}", values["db.dsn"])
+	assert.Equal(t, "${cycle.a}", values["cycle.a"])
+
+	_, resp, err = kvsvc.ListKV(ctx, &model.ListKVRequest{
+		Domain:  domain,
+		Project: "resolve-test",
+		Labels:  svc,
+		Match:   common.PatternExact,
+	})
+	assert.Nil(t, err)
+	for _, kv := range resp.Data {
+		if kv.Key == "db.dsn" {
+			assert.Equal(t, "mysql://${db.url}/cart?escaped=$${db.host}&missing=${db.user}", kv.Value)
+		}
+	}
+}
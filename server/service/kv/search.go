@@ -0,0 +1,164 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"unicode/utf8"
+
+	"github.com/apache/servicecomb-kie/pkg/common"
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/datasource"
+	"github.com/go-chassis/cari/config"
+	"github.com/go-chassis/cari/pkg/errsvc"
+	"github.com/go-chassis/openlog"
+)
+
+// highlight settings of search results
+const (
+	MaxHighlights    = 3
+	HighlightContext = 30
+	HighlightPre     = "<em>"
+	HighlightPost    = "</em>"
+)
+
+// Search find kvs by regular expressions of key and value, and filters of value type, status, labels and update time,
+// the fragments matching the expressions are highlighted
+func Search(ctx context.Context, request *model.SearchKVRequest) (*model.SearchResponse, *errsvc.Error) {
+	keyRegex, err := compileSearch(request.Key, request.IgnoreCase)
+	if err != nil {
+		return nil, config.NewError(config.ErrInvalidParams, "invalid key regex: "+err.Error())
+	}
+	valueRegex, err := compileSearch(request.Value, request.IgnoreCase)
+	if err != nil {
+		return nil, config.NewError(config.ErrInvalidParams, "invalid value regex: "+err.Error())
+	}
+	opts := []datasource.FindOption{
+		datasource.WithKeyRegex(request.Key),
+		datasource.WithValueRegex(request.Value),
+		datasource.WithValueType(request.ValueType),
+		datasource.WithStatus(request.Status),
+		datasource.WithLabels(request.Labels),
+		datasource.WithSelector(request.Selector),
+		datasource.WithUpdateTimeRange(request.UpdatedAfter, request.UpdatedBefore),
+		datasource.WithOffset(request.Offset),
+		datasource.WithLimit(request.Limit),
+	}
+	if !request.IgnoreCase {
+		opts = append(opts, datasource.WithCaseSensitive())
+	}
+	kvs, err := List(ctx, request.Project, request.Domain, opts...)
+	if errors.Is(err, datasource.ErrSearchTimeout) {
+		return nil, config.NewError(config.ErrInvalidParams, err.Error())
+	}
+	if err != nil {
+		openlog.Error("search kv failed: " + err.Error())
+		return nil, config.NewError(config.ErrInternal, common.MsgDBError)
+	}
+	result := &model.SearchResponse{
+		Total: kvs.Total,
+		Data:  make([]*model.SearchResult, 0, len(kvs.Data)),
+	}
+	for _, kv := range kvs.Data {
+		r := &model.SearchResult{KVDoc: kv}
+		if fragments := highlight(keyRegex, kv.Key); len(fragments) != 0 {
+			r.Highlight = map[string][]string{"key": fragments}
+		}
+		if fragments := highlight(valueRegex, kv.Value); len(fragments) != 0 {
+			if r.Highlight == nil {
+				r.Highlight = make(map[string][]string, 1)
+			}
+			r.Highlight["value"] = fragments
+		}
+		result.Data = append(result.Data, r)
+	}
+	return result, nil
+}
+
+// compileSearch compiles the regex in RE2 syntax, backends like mongodb match it by backtracking,
+// so that long regexes and nested repetitions like "(a+)+" are rejected
+func compileSearch(expr string, ignoreCase bool) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	if len(expr) > datasource.MaxSearchRegexLength {
+		return nil, fmt.Errorf("longer than %d", datasource.MaxSearchRegexLength)
+	}
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+	if nestedRepeat(re, false) {
+		return nil, errors.New("nested repetitions are not supported")
+	}
+	if ignoreCase {
+		expr = "(?i)" + expr
+	}
+	return regexp.Compile(expr)
+}
+
+// nestedRepeat tells if there is a repetition inside another repetition
+func nestedRepeat(re *syntax.Regexp, inRepeat bool) bool {
+	repeat := re.Op == syntax.OpStar || re.Op == syntax.OpPlus ||
+		(re.Op == syntax.OpRepeat && (re.Max == -1 || re.Max > 1))
+	if repeat && inRepeat {
+		return true
+	}
+	for _, sub := range re.Sub {
+		if nestedRepeat(sub, inRepeat || repeat) {
+			return true
+		}
+	}
+	return false
+}
+
+// highlight return at most MaxHighlights fragments of s around the matches,
+// a fragment has HighlightContext bytes at most before and after the match
+func highlight(regex *regexp.Regexp, s string) []string {
+	if regex == nil {
+		return nil
+	}
+	var fragments []string
+	for _, loc := range regex.FindAllStringIndex(s, -1) {
+		if loc[0] == loc[1] {
+			continue
+		}
+		start, end := loc[0]-HighlightContext, loc[1]+HighlightContext
+		if start < 0 {
+			start = 0
+		}
+		if end > len(s) {
+			end = len(s)
+		}
+		for start < loc[0] && !utf8.RuneStart(s[start]) {
+			start++
+		}
+		for end < len(s) && !utf8.RuneStart(s[end]) {
+			end--
+		}
+		fragments = append(fragments, s[start:loc[0]]+HighlightPre+s[loc[0]:loc[1]]+HighlightPost+s[loc[1]:end])
+		if len(fragments) == MaxHighlights {
+			break
+		}
+	}
+	return fragments
+}
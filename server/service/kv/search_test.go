@@ -0,0 +1,102 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kv_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/servicecomb-kie/pkg/common"
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/datasource"
+	kvsvc "github.com/apache/servicecomb-kie/server/service/kv"
+	"github.com/go-chassis/cari/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearch(t *testing.T) {
+	ctx := context.TODO()
+	create := func(key, value, valueType string) *model.KVDoc {
+		kv, err := kvsvc.Create(ctx, &model.KVDoc{
+			Key:       key,
+			Value:     value,
+			ValueType: valueType,
+			Status:    common.StatusEnabled,
+			Labels:    map[string]string{"app": "mall"},
+			Domain:    domain,
+			Project:   "search-test",
+		})
+		assert.Nil(t, err)
+		return kv
+	}
+	before := time.Now().Unix()
+	create("order.endpoint", "http://old.example.com/order", "text")
+	create("cart.endpoint", "http://OLD.example.com/cart", "text")
+	create("cart.config", `{"endpoint": "http://new.example.com/cart"}`, "json")
+
+	search := func(request *model.SearchKVRequest) *model.SearchResponse {
+		request.Domain = domain
+		request.Project = "search-test"
+		resp, err := kvsvc.Search(ctx, request)
+		assert.Nil(t, err)
+		return resp
+	}
+	t.Run("search value by regex should highlight the matches", func(t *testing.T) {
+		resp := search(&model.SearchKVRequest{Value: `old\.example\.com`})
+		assert.Equal(t, 1, resp.Total)
+		assert.Equal(t, "order.endpoint", resp.Data[0].Key)
+		assert.Equal(t, []string{"http://<em>old.example.com</em>/order"}, resp.Data[0].Highlight["value"])
+
+		resp = search(&model.SearchKVRequest{Value: `old\.example\.com`, IgnoreCase: true})
+		assert.Equal(t, 2, resp.Total)
+	})
+	t.Run("search key by regex with filters", func(t *testing.T) {
+		resp := search(&model.SearchKVRequest{Key: `^cart\.`})
+		assert.Equal(t, 2, resp.Total)
+
+		resp = search(&model.SearchKVRequest{Key: `^cart\.`, ValueType: "json"})
+		assert.Equal(t, 1, resp.Total)
+		assert.Equal(t, []string{"<em>cart.</em>config"}, resp.Data[0].Highlight["key"])
+
+		resp = search(&model.SearchKVRequest{Key: "endpoint", UpdatedAfter: before})
+		assert.Equal(t, 2, resp.Total)
+		resp = search(&model.SearchKVRequest{Key: "endpoint", UpdatedBefore: before - 1})
+		assert.Equal(t, 0, resp.Total)
+	})
+	t.Run("search results should be paged", func(t *testing.T) {
+		resp := search(&model.SearchKVRequest{Value: "example", Offset: 1, Limit: 2})
+		assert.Equal(t, 3, resp.Total)
+		assert.Equal(t, 2, len(resp.Data))
+	})
+	t.Run("invalid regex should be rejected", func(t *testing.T) {
+		_, err := kvsvc.Search(ctx, &model.SearchKVRequest{Domain: domain, Project: "search-test", Key: "("})
+		assert.NotNil(t, err)
+		assert.Equal(t, config.ErrInvalidParams, err.Code)
+	})
+	t.Run("nested repetitions and long regexes should be rejected", func(t *testing.T) {
+		for _, value := range []string{"(a+)+$", "(a*b?)*c", "(a{2,})+", strings.Repeat("a", datasource.MaxSearchRegexLength+1)} {
+			_, err := kvsvc.Search(ctx, &model.SearchKVRequest{Domain: domain, Project: "search-test", Value: value})
+			assert.NotNil(t, err, value)
+			assert.Equal(t, config.ErrInvalidParams, err.Code, value)
+		}
+		resp := search(&model.SearchKVRequest{Value: "(ex)ample.*"})
+		assert.Equal(t, 3, resp.Total)
+	})
+}
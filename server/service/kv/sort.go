@@ -0,0 +1,54 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kv
+
+import (
+	"strings"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/datasource"
+	"github.com/go-chassis/cari/config"
+	"github.com/go-chassis/cari/pkg/errsvc"
+)
+
+// sortOptions return the options to sort and page kvs by cursor,
+// the sort of the request must be the same as the cursor if both are given, or the cursor's sort is used
+func sortOptions(request *model.ListKVRequest) ([]datasource.FindOption, *errsvc.Error) {
+	if request.Sort == "" && request.Cursor == "" {
+		return nil, nil
+	}
+	if request.Offset != 0 {
+		return nil, config.NewError(config.ErrInvalidParams, "can not accept offset, when using sort or cursor")
+	}
+	field := strings.TrimPrefix(request.Sort, "-")
+	desc := strings.HasPrefix(request.Sort, "-")
+	if request.Sort != "" && !datasource.IsSortField(field) {
+		return nil, config.NewError(config.ErrInvalidParams, "invalid sort field: "+field)
+	}
+	if request.Cursor == "" {
+		return []datasource.FindOption{datasource.WithSort(field, desc)}, nil
+	}
+	cursor, err := datasource.DecodeCursor(request.Cursor)
+	if err != nil {
+		return nil, config.NewError(config.ErrInvalidParams, err.Error())
+	}
+	if request.Sort != "" && (cursor.Sort != field || cursor.Desc != desc) {
+		return nil, config.NewError(config.ErrInvalidParams, "the cursor does not match the sort")
+	}
+	return []datasource.FindOption{datasource.WithSort(cursor.Sort, cursor.Desc), datasource.WithCursor(cursor)}, nil
+}
@@ -0,0 +1,88 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kv
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/apache/servicecomb-kie/pkg/common"
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/datasource"
+	"github.com/go-chassis/cari/config"
+	"github.com/go-chassis/cari/pkg/errsvc"
+	"github.com/go-chassis/openlog"
+)
+
+// DefaultDelimiter separates the namespaces of keys
+const DefaultDelimiter = "."
+
+// Tree return the immediate child segments of keys under the prefix, with the number of kvs under each segment
+func Tree(ctx context.Context, request *model.KeyTreeRequest) (*model.KeyTreeResponse, *errsvc.Error) {
+	if request.Delimiter == "" {
+		request.Delimiter = DefaultDelimiter
+	}
+	opts := []datasource.FindOption{
+		datasource.WithStatus(request.Status),
+		datasource.WithLabels(request.Labels),
+		datasource.WithSelector(request.Selector),
+		datasource.WithCaseSensitive(),
+	}
+	if request.Prefix != "" {
+		opts = append(opts, datasource.WithKey("beginWith("+request.Prefix+")"))
+	}
+	kvs, err := List(ctx, request.Project, request.Domain, opts...)
+	if err != nil {
+		openlog.Error("list kv tree failed: " + err.Error())
+		return nil, config.NewError(config.ErrInternal, common.MsgDBError)
+	}
+	result := &model.KeyTreeResponse{
+		Prefix:    request.Prefix,
+		Delimiter: request.Delimiter,
+		Data:      []*model.KeyNode{},
+	}
+	nodes := make(map[string]*model.KeyNode)
+	for _, kv := range kvs.Data {
+		if !strings.HasPrefix(kv.Key, request.Prefix) {
+			continue
+		}
+		result.Total++
+		name := strings.TrimPrefix(kv.Key, request.Prefix)
+		isKey := true
+		if i := strings.Index(name, request.Delimiter); i != -1 {
+			name, isKey = name[:i], false
+		}
+		node, ok := nodes[name]
+		if !ok {
+			node = &model.KeyNode{Name: name, Path: request.Prefix + name, Leaf: true}
+			nodes[name] = node
+			result.Data = append(result.Data, node)
+		}
+		node.Count++
+		if isKey {
+			node.Key = true
+		} else {
+			node.Leaf = false
+		}
+	}
+	sort.Slice(result.Data, func(i, j int) bool {
+		return result.Data[i].Name < result.Data[j].Name
+	})
+	return result, nil
+}
@@ -0,0 +1,89 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/apache/servicecomb-kie/pkg/common"
+	"github.com/apache/servicecomb-kie/pkg/model"
+	kvsvc "github.com/apache/servicecomb-kie/server/service/kv"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTree(t *testing.T) {
+	ctx := context.TODO()
+	create := func(key string, labels map[string]string) {
+		_, err := kvsvc.Create(ctx, &model.KVDoc{
+			Key:     key,
+			Value:   "1",
+			Status:  common.StatusEnabled,
+			Labels:  labels,
+			Domain:  domain,
+			Project: "tree-test",
+		})
+		assert.Nil(t, err)
+	}
+	mall := map[string]string{"app": "mall"}
+	create("servicecomb.rest.client.timeout", mall)
+	create("servicecomb.rest.client.timeout", map[string]string{"app": "cart"})
+	create("servicecomb.rest.server.port", mall)
+	create("servicecomb.rest", mall)
+	create("servicecomb.registry.address", mall)
+	create("logging.level", mall)
+
+	tree := func(request *model.KeyTreeRequest) *model.KeyTreeResponse {
+		request.Domain = domain
+		request.Project = "tree-test"
+		resp, err := kvsvc.Tree(ctx, request)
+		assert.Nil(t, err)
+		return resp
+	}
+	t.Run("list the root, should return top segments", func(t *testing.T) {
+		resp := tree(&model.KeyTreeRequest{})
+		assert.Equal(t, 6, resp.Total)
+		assert.Equal(t, []*model.KeyNode{
+			{Name: "logging", Path: "logging", Count: 1},
+			{Name: "servicecomb", Path: "servicecomb", Count: 5},
+		}, resp.Data)
+	})
+	t.Run("list a prefix, should tell leaves and keys", func(t *testing.T) {
+		resp := tree(&model.KeyTreeRequest{Prefix: "servicecomb."})
+		assert.Equal(t, []*model.KeyNode{
+			{Name: "registry", Path: "servicecomb.registry", Count: 1},
+			{Name: "rest", Path: "servicecomb.rest", Count: 4, Key: true},
+		}, resp.Data)
+
+		resp = tree(&model.KeyTreeRequest{Prefix: "servicecomb.rest.client."})
+		assert.Equal(t, []*model.KeyNode{
+			{Name: "timeout", Path: "servicecomb.rest.client.timeout", Count: 2, Key: true, Leaf: true},
+		}, resp.Data)
+	})
+	t.Run("list by labels and another delimiter", func(t *testing.T) {
+		resp := tree(&model.KeyTreeRequest{Prefix: "servicecomb.rest.", Labels: map[string]string{"app": "cart"}})
+		assert.Equal(t, 1, resp.Total)
+		assert.Equal(t, "client", resp.Data[0].Name)
+
+		resp = tree(&model.KeyTreeRequest{Prefix: "servicecomb", Delimiter: ":"})
+		assert.Equal(t, 4, len(resp.Data))
+		for _, node := range resp.Data {
+			assert.True(t, node.Key && node.Leaf, node.Name)
+		}
+	})
+}
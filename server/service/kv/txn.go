@@ -0,0 +1,238 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-chassis/cari/config"
+	"github.com/go-chassis/cari/pkg/errsvc"
+	"github.com/go-chassis/foundation/validator"
+	"github.com/go-chassis/go-chassis/v2/pkg/backends/quota"
+	"github.com/go-chassis/openlog"
+
+	"github.com/apache/servicecomb-kie/pkg/common"
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/pkg/stringutil"
+	"github.com/apache/servicecomb-kie/pkg/util"
+	"github.com/apache/servicecomb-kie/server/datasource"
+)
+
+// MaxTxnOps is the max number of operations in a transaction, they must fit in one etcd transaction
+const MaxTxnOps = 30
+
+// Txn applies the creations, updates and deletions atomically under one revision,
+// the transaction fails if any of them fails, or any revision precondition is not satisfied
+func Txn(ctx context.Context, request *model.TxnRequest) (*model.TxnResponse, *errsvc.Error) {
+	if err := validator.Validate(request); err != nil {
+		return nil, config.NewError(config.ErrInvalidParams, err.Error())
+	}
+	if len(request.Ops) == 0 || len(request.Ops) > MaxTxnOps {
+		return nil, config.NewError(config.ErrInvalidParams, fmt.Sprintf("a transaction must have 1 to %d operations", MaxTxnOps))
+	}
+	txn := &datasource.KVTxn{Revisions: make(map[string]int64)}
+	changes := make([]*Change, 0, len(request.Ops))
+	ids := make(map[string]bool, len(request.Ops))
+	for i, op := range request.Ops {
+		if op == nil {
+			return nil, config.NewError(config.ErrInvalidParams, fmt.Sprintf("operation %d is empty", i))
+		}
+		c, svcErr := txnChange(ctx, request, op)
+		if svcErr != nil {
+			svcErr.Detail = fmt.Sprintf("operation %d: %s", i, svcErr.Detail)
+			return nil, svcErr
+		}
+		kv := c.New
+		if kv == nil {
+			kv = c.Old
+		}
+		if ids[kv.ID] {
+			return nil, config.NewError(config.ErrInvalidParams, fmt.Sprintf("operation %d: the kv is changed more than once", i))
+		}
+		ids[kv.ID] = true
+		switch c.Op {
+		case OpCreate:
+			txn.Creates = append(txn.Creates, c.New)
+		case OpUpdate:
+			txn.Updates = append(txn.Updates, c.New)
+			txn.Revisions[c.Old.ID] = c.Old.UpdateRevision
+		case OpDelete:
+			txn.Deletes = append(txn.Deletes, c.Old)
+			txn.Revisions[c.Old.ID] = c.Old.UpdateRevision
+		}
+		changes = append(changes, c)
+	}
+	if len(txn.Creates) != 0 {
+		if err := quota.PreCreate(request.Domain, request.Project, "", int64(len(txn.Creates))); err != nil {
+			if err == quota.ErrReached {
+				return nil, config.NewError(config.ErrNotEnoughQuota, err.Error())
+			}
+			openlog.Error(err.Error())
+			return nil, config.NewError(config.ErrInternal, "quota check failed")
+		}
+	}
+	var after int64
+	for _, rev := range txn.Revisions {
+		if rev > after {
+			after = rev
+		}
+	}
+	revision, err := applyRevision(ctx, request.Project, request.Domain, after)
+	if err != nil {
+		openlog.Error(err.Error())
+		return nil, config.NewError(config.ErrInternal, "apply transaction failed")
+	}
+	for _, c := range changes {
+		switch c.Op {
+		case OpCreate:
+			_ = completeKV(c.New, revision)
+		case OpUpdate:
+			c.New.UpdateRevision = revision
+			c.New.UpdateTime = time.Now().Unix()
+		}
+	}
+	err = datasource.GetBroker().GetKVDao().Batch(ctx, txn, writeOptions(ctx)...)
+	if err != nil {
+		if errors.Is(err, datasource.ErrKVConflict) {
+			return nil, config.NewError(config.ErrRecordAlreadyExists, err.Error())
+		}
+		openlog.Error("apply transaction failed: " + err.Error())
+		return nil, util.SvcErr(err)
+	}
+	openlog.Info(fmt.Sprintf("apply transaction of %d kvs to %s at revision %d", len(changes), request.Project, revision))
+	runAfter(ctx, &Change{Op: OpTxn, Changes: changes})
+	result := &model.TxnResponse{Revision: revision, Data: make([]*model.KVDoc, 0, len(changes))}
+	for _, c := range changes {
+		kv := c.New
+		if kv == nil {
+			kv = c.Old
+		}
+		datasource.ClearPart(kv)
+		result.Data = append(result.Data, kv)
+	}
+	return result, nil
+}
+
+// txnChange checks the operation the same as the creation, update or deletion of a kv
+func txnChange(ctx context.Context, request *model.TxnRequest, op *model.TxnOp) (*Change, *errsvc.Error) {
+	if op.Op == model.TxnCreate {
+		return txnCreate(ctx, request, op)
+	}
+	if op.Op != model.TxnUpdate && op.Op != model.TxnDelete {
+		return nil, config.NewError(config.ErrInvalidParams, "invalid op: "+op.Op)
+	}
+	if op.ID == "" {
+		return nil, config.NewError(config.ErrInvalidParams, "id is required to "+op.Op)
+	}
+	old, err := datasource.GetBroker().GetKVDao().Get(ctx, &model.GetKVRequest{
+		Domain:  request.Domain,
+		Project: request.Project,
+		ID:      op.ID,
+	})
+	if err != nil {
+		if errors.Is(err, datasource.ErrKeyNotExists) {
+			return nil, config.NewError(config.ErrRecordNotExists, err.Error())
+		}
+		return nil, util.SvcErr(err)
+	}
+	old.Domain = request.Domain
+	old.Project = request.Project
+	if op.Revision != 0 && op.Revision != old.UpdateRevision {
+		return nil, config.NewError(config.ErrRecordAlreadyExists,
+			fmt.Sprintf("the revision of kv [%s] is %d, not %d", old.ID, old.UpdateRevision, op.Revision))
+	}
+	if op.Op == model.TxnDelete {
+		c := &Change{Op: OpDelete, Old: old}
+		if hookErr := runBefore(ctx, c); hookErr != nil {
+			return nil, hookErr
+		}
+		return c, nil
+	}
+	updated := *old
+	if op.Status != "" {
+		updated.Status = op.Status
+	}
+	if op.Value != "" {
+		updated.Value = op.Value
+	}
+	c := &Change{Op: OpUpdate, Old: old, New: &updated}
+	if hookErr := runBefore(ctx, c); hookErr != nil {
+		return nil, hookErr
+	}
+	if c.New.Value != old.Value {
+		if valueErr, _ := checkValue(request.Project, c.New.ValueType, c.New.Value); valueErr != nil {
+			return nil, valueErr
+		}
+		if ruleErr := checkRules(ctx, c.New); ruleErr != nil {
+			return nil, ruleErr
+		}
+	}
+	return c, nil
+}
+
+func txnCreate(ctx context.Context, request *model.TxnRequest, op *model.TxnOp) (*Change, *errsvc.Error) {
+	kv := &model.KVDoc{
+		Key:       op.Key,
+		Value:     op.Value,
+		ValueType: op.ValueType,
+		Status:    op.Status,
+		Labels:    op.Labels,
+		Domain:    request.Domain,
+		Project:   request.Project,
+	}
+	if kv.Status == "" {
+		kv.Status = common.StatusDisabled
+	}
+	c := &Change{Op: OpCreate, New: kv}
+	if hookErr := runBefore(ctx, c); hookErr != nil {
+		return nil, hookErr
+	}
+	kv = c.New
+	if err := validator.Validate(kv); err != nil {
+		return nil, config.NewError(config.ErrInvalidParams, err.Error())
+	}
+	if valueErr, _ := checkValue(kv.Project, kv.ValueType, kv.Value); valueErr != nil {
+		return nil, valueErr
+	}
+	if checkerErr := checkChecker(kv); checkerErr != nil {
+		return nil, checkerErr
+	}
+	if ruleErr := checkRules(ctx, kv); ruleErr != nil {
+		return nil, ruleErr
+	}
+	if kv.Labels == nil {
+		kv.Labels = map[string]string{}
+	}
+	kv.LabelFormat = stringutil.FormatMap(kv.Labels)
+	if kv.ValueType == "" {
+		kv.ValueType = datasource.DefaultValueType
+	}
+	exist, err := Exist(ctx, kv.Key, kv.Project, kv.Domain, kv.Labels)
+	if err != nil {
+		return nil, config.NewError(config.ErrInternal, common.MsgDBError)
+	}
+	if exist {
+		return nil, config.NewError(config.ErrRecordAlreadyExists, datasource.ErrKVAlreadyExists.Error())
+	}
+	// the id is known before the revision is applied
+	_ = completeKV(kv, 0)
+	return c, nil
+}
@@ -0,0 +1,112 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-chassis/cari/config"
+	"github.com/go-chassis/cari/pkg/errsvc"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apache/servicecomb-kie/pkg/common"
+	"github.com/apache/servicecomb-kie/pkg/model"
+	kvsvc "github.com/apache/servicecomb-kie/server/service/kv"
+)
+
+type txnHook struct {
+	changes []*kvsvc.Change
+}
+
+func (h *txnHook) Before(ctx context.Context, c *kvsvc.Change) *errsvc.Error {
+	return nil
+}
+
+func (h *txnHook) After(ctx context.Context, c *kvsvc.Change) {
+	h.changes = append(h.changes, c)
+}
+
+func TestTxn(t *testing.T) {
+	ctx := context.TODO()
+	h := &txnHook{}
+	kvsvc.RegisterHook("txn-test", h)
+	defer kvsvc.UnregisterHook("txn-test")
+	labels := map[string]string{"app": "txn-test"}
+	txn := func(ops ...*model.TxnOp) (*model.TxnResponse, *errsvc.Error) {
+		return kvsvc.Txn(ctx, &model.TxnRequest{Domain: domain, Project: "txn-test", Ops: ops})
+	}
+	get := func(id string) (*model.KVDoc, error) {
+		return kvsvc.Get(ctx, &model.GetKVRequest{ID: id, Domain: domain, Project: "txn-test"})
+	}
+
+	var flag, endpoint *model.KVDoc
+	t.Run("create kvs in a transaction, should share one revision", func(t *testing.T) {
+		resp, err := txn(
+			&model.TxnOp{Op: model.TxnCreate, Key: "feature.enabled", Value: "true", Labels: labels, Status: common.StatusEnabled},
+			&model.TxnOp{Op: model.TxnCreate, Key: "feature.endpoint", Value: "http://a", Labels: labels},
+		)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(resp.Data))
+		flag, endpoint = resp.Data[0], resp.Data[1]
+		assert.Equal(t, resp.Revision, flag.UpdateRevision)
+		assert.Equal(t, resp.Revision, endpoint.CreateRevision)
+		assert.Equal(t, common.StatusDisabled, endpoint.Status)
+		if assert.Equal(t, 1, len(h.changes)) {
+			assert.Equal(t, kvsvc.OpTxn, h.changes[0].Op)
+			assert.Equal(t, 2, len(h.changes[0].Changes))
+		}
+	})
+	t.Run("revision precondition is not satisfied, should change nothing", func(t *testing.T) {
+		_, err := txn(
+			&model.TxnOp{Op: model.TxnUpdate, ID: endpoint.ID, Value: "http://b", Revision: endpoint.UpdateRevision},
+			&model.TxnOp{Op: model.TxnDelete, ID: flag.ID, Revision: flag.UpdateRevision + 1},
+		)
+		assert.Equal(t, config.ErrRecordAlreadyExists, err.Code)
+		kv, getErr := get(endpoint.ID)
+		assert.NoError(t, getErr)
+		assert.Equal(t, "http://a", kv.Value)
+	})
+	t.Run("update and delete in a transaction, should apply both", func(t *testing.T) {
+		resp, err := txn(
+			&model.TxnOp{Op: model.TxnUpdate, ID: endpoint.ID, Value: "http://b", Status: common.StatusEnabled, Revision: endpoint.UpdateRevision},
+			&model.TxnOp{Op: model.TxnDelete, ID: flag.ID, Revision: flag.UpdateRevision},
+		)
+		assert.Nil(t, err)
+		assert.Equal(t, "http://b", resp.Data[0].Value)
+		assert.Equal(t, resp.Revision, resp.Data[0].UpdateRevision)
+		kv, getErr := get(endpoint.ID)
+		assert.NoError(t, getErr)
+		assert.Equal(t, common.StatusEnabled, kv.Status)
+		_, getErr = get(flag.ID)
+		assert.Error(t, getErr)
+	})
+	t.Run("invalid operations, should be rejected", func(t *testing.T) {
+		_, err := txn()
+		assert.Equal(t, config.ErrInvalidParams, err.Code)
+		_, err = txn(&model.TxnOp{Op: "rename", ID: endpoint.ID})
+		assert.Equal(t, config.ErrInvalidParams, err.Code)
+		_, err = txn(
+			&model.TxnOp{Op: model.TxnUpdate, ID: endpoint.ID, Value: "1"},
+			&model.TxnOp{Op: model.TxnDelete, ID: endpoint.ID},
+		)
+		assert.Equal(t, config.ErrInvalidParams, err.Code)
+		_, err = txn(&model.TxnOp{Op: model.TxnCreate, Key: "feature.endpoint", Value: "1", Labels: labels})
+		assert.Equal(t, config.ErrRecordAlreadyExists, err.Code)
+	})
+}
@@ -0,0 +1,297 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-chassis/cari/config"
+	"github.com/go-chassis/cari/pkg/errsvc"
+	"github.com/go-chassis/foundation/validator"
+	"github.com/go-chassis/go-chassis/v2/pkg/backends/quota"
+	"github.com/go-chassis/openlog"
+
+	"github.com/apache/servicecomb-kie/pkg/common"
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/pkg/stringutil"
+	"github.com/apache/servicecomb-kie/server/datasource"
+)
+
+// MaxAtomicKVs is the max number of kvs in an atomic upload, they must fit in one etcd transaction
+const MaxAtomicKVs = 40
+
+// uploadStep is what uploading a kv would do
+type uploadStep struct {
+	kv   *model.KVDoc
+	old  *model.KVDoc
+	diff *model.KVDiff
+	// change is the creation or update checked by before hooks, it is nil if the kv is skipped or aborted
+	change *Change
+	// planned tells the existing kv is uploaded earlier in the same request
+	planned bool
+}
+
+// plan checks kvs the same as uploading them with the override strategy, and tells what would be done to each kv,
+// like the strategies, it calls before hooks once for each kv to create or update,
+// it stops at the duplicate kv if the strategy is abort, nothing is persisted
+func plan(ctx context.Context, request *model.UploadKVRequest) ([]*uploadStep, *model.DocRespOfUpload) {
+	result := &model.DocRespOfUpload{
+		Success: []*model.KVDoc{},
+		Failure: []*model.DocFailedOfUpload{},
+	}
+	var steps []*uploadStep
+	planned := make(map[string]*model.KVDoc)
+	for i, kv := range request.KVs {
+		if kv == nil {
+			continue
+		}
+		kv.Domain = request.Domain
+		kv.Project = request.Project
+		if kv.Labels == nil {
+			kv.Labels = map[string]string{}
+		}
+		if err := validator.Validate(kv); err != nil {
+			appendFailedKVResult(config.NewError(config.ErrInvalidParams, err.Error()), kv, result)
+			continue
+		}
+		if err, se := checkValue(kv.Project, kv.ValueType, kv.Value); err != nil {
+			appendSyntaxFailedKVResult(err, se, kv, result)
+			continue
+		}
+		id := kv.Key + "/" + stringutil.FormatMap(kv.Labels)
+		step := &uploadStep{kv: kv}
+		step.old, step.planned = planned[id]
+		if !step.planned {
+			kvs, err := GetByKey(ctx, kv.Key, kv.Project, kv.Domain, kv.Labels)
+			if err != nil && !errors.Is(err, datasource.ErrKeyNotExists) {
+				appendFailedKVResult(config.NewError(config.ErrInternal, common.MsgDBError), kv, result)
+				continue
+			}
+			if len(kvs) != 0 {
+				step.old = kvs[0]
+			}
+		}
+		if step.old == nil {
+			if err := planCreate(ctx, step); err != nil {
+				appendFailedKVResult(err, kv, result)
+				continue
+			}
+			kv = step.kv
+			step.diff = &model.KVDiff{Key: kv.Key, Labels: kv.Labels, Action: model.DiffCreate, NewValue: kv.Value}
+			steps = append(steps, step)
+			planned[id] = kv
+			continue
+		}
+		step.diff = &model.KVDiff{Key: kv.Key, Labels: kv.Labels, OldValue: step.old.Value}
+		switch request.Override {
+		case "abort":
+			step.diff.Action = model.DiffAbort
+			steps = append(steps, step)
+			appendAbortFailedKVResult(request.KVs[i:], result)
+			return steps, result
+		case "skip":
+			step.diff.Action = model.DiffSkip
+			steps = append(steps, step)
+			planned[id] = step.old
+		default:
+			if err := planUpdate(ctx, step); err != nil {
+				appendFailedKVResult(err, kv, result)
+				continue
+			}
+			updated := step.change.New
+			step.diff.NewValue = updated.Value
+			step.diff.Action = model.DiffUpdate
+			if updated.Value == step.old.Value && updated.Status == step.old.Status {
+				step.diff.Action = model.DiffUnchanged
+			}
+			steps = append(steps, step)
+			planned[id] = updated
+		}
+	}
+	return steps, result
+}
+
+// planCreate checks the kv the same as Create
+func planCreate(ctx context.Context, step *uploadStep) *errsvc.Error {
+	if step.kv.Status == "" {
+		step.kv.Status = common.StatusDisabled
+	}
+	step.change = &Change{Op: OpCreate, New: step.kv}
+	if hookErr := runBefore(ctx, step.change); hookErr != nil {
+		return hookErr
+	}
+	kv := step.change.New
+	step.kv = kv
+	if kv.Labels == nil {
+		kv.Labels = map[string]string{}
+	}
+	if err := validator.Validate(kv); err != nil {
+		return config.NewError(config.ErrInvalidParams, err.Error())
+	}
+	if err, _ := checkValue(kv.Project, kv.ValueType, kv.Value); err != nil {
+		return err
+	}
+	if err := checkChecker(kv); err != nil {
+		return err
+	}
+	return checkRules(ctx, kv)
+}
+
+// planUpdate checks the update of the existing kv the same as Update
+func planUpdate(ctx context.Context, step *uploadStep) *errsvc.Error {
+	updated := *step.old
+	// kvs found by key may have domain and project cleared
+	updated.Domain = step.kv.Domain
+	updated.Project = step.kv.Project
+	updated.Value = step.kv.Value
+	if step.kv.Status != "" {
+		updated.Status = step.kv.Status
+	}
+	step.change = &Change{Op: OpUpdate, Old: step.old, New: &updated}
+	if hookErr := runBefore(ctx, step.change); hookErr != nil {
+		return hookErr
+	}
+	if step.change.New.Value == step.old.Value {
+		return nil
+	}
+	if err, _ := checkValue(step.kv.Project, step.change.New.ValueType, step.change.New.Value); err != nil {
+		return err
+	}
+	return checkRules(ctx, step.change.New)
+}
+
+// preview tells what uploading kvs would do without persisting them
+func preview(ctx context.Context, request *model.UploadKVRequest) *model.DocRespOfUpload {
+	steps, result := plan(ctx, request)
+	result.Diff = make([]*model.KVDiff, 0, len(steps))
+	for _, step := range steps {
+		result.Diff = append(result.Diff, step.diff)
+	}
+	return result
+}
+
+// uploadAtomically writes all kvs in one transaction, nothing is written if any of them fails,
+// duplicate kvs skipped by the strategy are reported as failures but do not fail the upload
+func uploadAtomically(ctx context.Context, request *model.UploadKVRequest) *model.DocRespOfUpload {
+	if len(request.KVs) > MaxAtomicKVs {
+		result := &model.DocRespOfUpload{Success: []*model.KVDoc{}, Failure: []*model.DocFailedOfUpload{}}
+		appendStoppedKVResult(request.KVs, fmt.Sprintf("at most %d kvs can be uploaded atomically", MaxAtomicKVs), result)
+		return result
+	}
+	steps, result := plan(ctx, request)
+	var creates, updates []*model.KVDoc
+	var changes []*Change
+	revisions := make(map[string]int64)
+	var skipped []*model.KVDoc
+	for _, step := range steps {
+		switch {
+		case step.planned:
+			appendFailedKVResult(config.NewError(config.ErrInvalidParams, "duplicate kv in atomic upload"), step.kv, result)
+		case step.diff.Action == model.DiffAbort:
+		case step.diff.Action == model.DiffSkip:
+			skipped = append(skipped, step.kv)
+		case step.diff.Action == model.DiffCreate:
+			if step.kv.ValueType == "" {
+				step.kv.ValueType = datasource.DefaultValueType
+			}
+			step.kv.LabelFormat = stringutil.FormatMap(step.kv.Labels)
+			creates = append(creates, step.kv)
+			changes = append(changes, step.change)
+		default:
+			updates = append(updates, step.change.New)
+			revisions[step.old.ID] = step.old.UpdateRevision
+			changes = append(changes, step.change)
+		}
+	}
+	if len(result.Failure) != 0 {
+		return rollback(steps, "nothing is uploaded because of the failures in atomic upload", result)
+	}
+	if err := quota.PreCreate(request.Domain, request.Project, "", int64(len(creates))); err != nil {
+		openlog.Error(fmt.Sprintf("can not upload kvs to %s: %s", request.Project, err))
+		if err == quota.ErrReached {
+			return rollback(steps, err.Error(), result)
+		}
+		return rollback(steps, "quota check failed", result)
+	}
+	for _, c := range changes {
+		var after int64
+		if c.Old != nil {
+			after = c.Old.UpdateRevision
+		}
+		revision, err := applyRevision(ctx, request.Project, request.Domain, after)
+		if err != nil {
+			openlog.Error(err.Error())
+			return rollback(steps, "upload kvs failed", result)
+		}
+		if c.Op == OpCreate {
+			_ = completeKV(c.New, revision)
+			continue
+		}
+		c.New.UpdateRevision = revision
+		c.New.UpdateTime = time.Now().Unix()
+	}
+	if err := datasource.GetBroker().GetKVDao().Batch(ctx, &datasource.KVTxn{
+		Creates:   creates,
+		Updates:   updates,
+		Revisions: revisions,
+	}, writeOptions(ctx)...); err != nil {
+		openlog.Error("upload kvs atomically failed: " + err.Error())
+		return rollback(steps, "upload kvs failed: "+err.Error(), result)
+	}
+	for _, c := range changes {
+		runAfter(ctx, c)
+		datasource.ClearPart(c.New)
+		result.Success = append(result.Success, c.New)
+	}
+	for _, kv := range skipped {
+		appendFailedKVResult(config.NewError(config.ErrSkipDuplicateKV, "skip overriding duplicate kvs"), kv, result)
+	}
+	openlog.Info(fmt.Sprintf("upload %d kvs to %s atomically", len(changes), request.Project))
+	return result
+}
+
+// rollback reports kvs of the steps as stopped, because nothing is written in the atomic upload,
+// failed kvs are reported already
+func rollback(steps []*uploadStep, msg string, result *model.DocRespOfUpload) *model.DocRespOfUpload {
+	stopped := make([]*model.KVDoc, 0, len(steps))
+	for _, step := range steps {
+		if !step.planned && step.diff.Action != model.DiffAbort {
+			stopped = append(stopped, step.kv)
+		}
+	}
+	result.Success = []*model.KVDoc{}
+	appendStoppedKVResult(stopped, msg, result)
+	return result
+}
+
+func appendStoppedKVResult(kvs []*model.KVDoc, msg string, result *model.DocRespOfUpload) {
+	for _, kv := range kvs {
+		if kv == nil {
+			continue
+		}
+		result.Failure = append(result.Failure, &model.DocFailedOfUpload{
+			Key:     kv.Key,
+			Labels:  kv.Labels,
+			ErrCode: config.ErrStopUpload,
+			ErrMsg:  msg,
+		})
+	}
+}
@@ -0,0 +1,122 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package kv
+
+import (
+	"context"
+
+	"github.com/go-chassis/cari/config"
+	"github.com/go-chassis/cari/pkg/errsvc"
+	"github.com/go-chassis/foundation/validator"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	valuetype "github.com/apache/servicecomb-kie/pkg/validator"
+	kieconfig "github.com/apache/servicecomb-kie/server/config"
+	"github.com/apache/servicecomb-kie/server/service/checker"
+	"github.com/apache/servicecomb-kie/server/service/schema"
+)
+
+// IsStrict return true if kvs of the project must declare value_type
+func IsStrict(project string) bool {
+	c := kieconfig.GetValueType()
+	if c.Strict {
+		return true
+	}
+	for _, p := range c.StrictProjects {
+		if p == project {
+			return true
+		}
+	}
+	return false
+}
+
+// checkValue checks the value parses as the value type, and the value type is declared in strict mode
+func checkValue(project, valueType, value string) (*errsvc.Error, *valuetype.SyntaxError) {
+	if valueType == "" && IsStrict(project) {
+		return config.NewError(config.ErrInvalidParams, "value_type is required in strict mode"), nil
+	}
+	if se := valuetype.ValidateValue(valueType, value); se != nil {
+		return config.NewError(config.ErrInvalidParams, se.Error()), se
+	}
+	return nil, nil
+}
+
+// checkChecker checks the checker of a new kv is an expression, checkers stored before are skipped when they are not
+func checkChecker(kv *model.KVDoc) *errsvc.Error {
+	if kv.Checker == "" {
+		return nil
+	}
+	if _, err := checker.Compile(kv.Checker); err != nil {
+		return config.NewError(config.ErrInvalidParams, err.Error())
+	}
+	return nil
+}
+
+// checkRules checks the value against json schemas of the key and the checker of the kv
+func checkRules(ctx context.Context, kv *model.KVDoc) *errsvc.Error {
+	if err := schema.Validate(ctx, kv); err != nil {
+		return err
+	}
+	if err := checker.Check(kv); err != nil {
+		return config.NewError(config.ErrInvalidParams, err.Error())
+	}
+	return nil
+}
+
+// Validate checks kvs the same as uploading them, including json schemas and checkers, but never persists them
+func Validate(ctx context.Context, request *model.UploadKVRequest) *model.DocRespOfUpload {
+	result := &model.DocRespOfUpload{
+		Success: []*model.KVDoc{},
+		Failure: []*model.DocFailedOfUpload{},
+	}
+	for _, kv := range request.KVs {
+		if kv == nil {
+			continue
+		}
+		kv.Domain = request.Domain
+		kv.Project = request.Project
+		if err := validator.Validate(kv); err != nil {
+			appendFailedKVResult(config.NewError(config.ErrInvalidParams, err.Error()), kv, result)
+			continue
+		}
+		if err, se := checkValue(kv.Project, kv.ValueType, kv.Value); err != nil {
+			appendSyntaxFailedKVResult(err, se, kv, result)
+			continue
+		}
+		if err := checkChecker(kv); err != nil {
+			appendFailedKVResult(err, kv, result)
+			continue
+		}
+		if err := checkRules(ctx, kv); err != nil {
+			appendFailedKVResult(err, kv, result)
+			continue
+		}
+		kv.Domain = ""
+		kv.Project = ""
+		result.Success = append(result.Success, kv)
+	}
+	return result
+}
+
+func appendSyntaxFailedKVResult(err *errsvc.Error, se *valuetype.SyntaxError, kv *model.KVDoc, result *model.DocRespOfUpload) {
+	appendFailedKVResult(err, kv, result)
+	if se != nil {
+		failed := result.Failure[len(result.Failure)-1]
+		failed.Line = se.Line
+		failed.Column = se.Column
+	}
+}
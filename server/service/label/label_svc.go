@@ -0,0 +1,143 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package label is the catalog of labels used by kvs of a project
+package label
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/go-chassis/cari/config"
+	"github.com/go-chassis/cari/pkg/errsvc"
+	"github.com/go-chassis/foundation/validator"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/pkg/stringutil"
+	"github.com/apache/servicecomb-kie/server/datasource"
+)
+
+// List return the distinct label keys with their values, and the number of kvs having each of them
+func List(ctx context.Context, project, domain string) (*model.LabelResponse, *errsvc.Error) {
+	docs, err := datasource.GetBroker().GetLabelDao().List(ctx, project, domain)
+	if err != nil {
+		return nil, svcError(err)
+	}
+	keys := make(map[string]*model.LabelKey)
+	values := make(map[string]map[string]*model.LabelValue)
+	for _, doc := range docs {
+		if doc.Count <= 0 {
+			continue
+		}
+		for k, v := range doc.Labels {
+			lk, ok := keys[k]
+			if !ok {
+				lk = &model.LabelKey{Key: k, Values: []*model.LabelValue{}}
+				keys[k] = lk
+				values[k] = make(map[string]*model.LabelValue)
+			}
+			lk.Count += doc.Count
+			lv, ok := values[k][v]
+			if !ok {
+				lv = &model.LabelValue{Value: v}
+				values[k][v] = lv
+				lk.Values = append(lk.Values, lv)
+			}
+			lv.Count += doc.Count
+		}
+	}
+	result := &model.LabelResponse{Data: make([]*model.LabelKey, 0, len(keys))}
+	for _, lk := range keys {
+		sort.Slice(lk.Values, func(i, j int) bool {
+			return lk.Values[i].Value < lk.Values[j].Value
+		})
+		result.Data = append(result.Data, lk)
+	}
+	sort.Slice(result.Data, func(i, j int) bool {
+		return result.Data[i].Key < result.Data[j].Key
+	})
+	result.Total = len(result.Data)
+	return result, nil
+}
+
+// ListSets return the label sets of the project, orphans are the label sets no kv uses any more
+func ListSets(ctx context.Context, project, domain string, orphan bool) (*model.LabelSetResponse, *errsvc.Error) {
+	docs, err := datasource.GetBroker().GetLabelDao().List(ctx, project, domain)
+	if err != nil {
+		return nil, svcError(err)
+	}
+	result := &model.LabelSetResponse{Data: make([]*model.LabelDoc, 0, len(docs))}
+	for _, doc := range docs {
+		if orphan && doc.Count > 0 {
+			continue
+		}
+		doc.Domain = ""
+		doc.Project = ""
+		result.Data = append(result.Data, doc)
+	}
+	sort.Slice(result.Data, func(i, j int) bool {
+		return result.Data[i].Format < result.Data[j].Format
+	})
+	result.Total = len(result.Data)
+	return result, nil
+}
+
+// UpdateAlias names the label set, an alias is unique in the project
+func UpdateAlias(ctx context.Context, request *model.LabelAliasRequest) (*model.LabelDoc, *errsvc.Error) {
+	if err := validator.Validate(request); err != nil {
+		return nil, config.NewError(config.ErrInvalidParams, err.Error())
+	}
+	id := datasource.LabelID(request.Domain, request.Project, stringutil.FormatMap(request.Labels))
+	if request.Alias != "" {
+		docs, err := datasource.GetBroker().GetLabelDao().List(ctx, request.Project, request.Domain)
+		if err != nil {
+			return nil, svcError(err)
+		}
+		for _, doc := range docs {
+			if doc.Alias == request.Alias && doc.ID != id {
+				return nil, config.NewError(config.ErrRecordAlreadyExists,
+					"alias "+request.Alias+" is used by labels "+doc.Format)
+			}
+		}
+	}
+	doc, err := datasource.GetBroker().GetLabelDao().UpdateAlias(ctx, id, request.Project, request.Domain, request.Alias)
+	if err != nil {
+		return nil, svcError(err)
+	}
+	doc.Domain = ""
+	doc.Project = ""
+	return doc, nil
+}
+
+// DeleteOrphan deletes the label set by id, if no kv uses it
+func DeleteOrphan(ctx context.Context, id, project, domain string) *errsvc.Error {
+	if err := datasource.GetBroker().GetLabelDao().DeleteOrphan(ctx, id, project, domain); err != nil {
+		return svcError(err)
+	}
+	return nil
+}
+
+func svcError(err error) *errsvc.Error {
+	if errors.Is(err, datasource.ErrLabelNotExists) {
+		return config.NewError(config.ErrRecordNotExists, err.Error())
+	}
+	if errors.Is(err, datasource.ErrLabelInUse) {
+		return config.NewError(config.ErrInvalidParams, err.Error())
+	}
+	return config.NewError(config.ErrInternal, err.Error())
+}
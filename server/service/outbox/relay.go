@@ -0,0 +1,124 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+// Package outbox delivers kv change events written in the same transaction as kv mutations,
+// so that events are never lost once mutations are committed
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-chassis/openlog"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/config"
+	"github.com/apache/servicecomb-kie/server/datasource"
+	"github.com/apache/servicecomb-kie/server/pubsub"
+)
+
+const (
+	// BatchSize is the max number of events to deliver in one round
+	BatchSize = 100
+	// RetryInterval is the interval to deliver events which failed to be published
+	RetryInterval = time.Second
+	// TakeoverAfter is how long an event waits for its node to deliver it,
+	// after that, any node delivers it, in case its node is down
+	TakeoverAfter = 30 * time.Second
+)
+
+var notify = make(chan struct{}, 1)
+
+// Enabled return true if kv change events should be written to outbox
+func Enabled() bool {
+	return config.GetOutbox().Enabled
+}
+
+// Notify wakes up the relay to deliver events right away
+func Notify() {
+	select {
+	case notify <- struct{}{}:
+	default:
+	}
+}
+
+// Run starts the relay if outbox is enabled, it should be called after bus started
+func Run() {
+	if !Enabled() {
+		return
+	}
+	openlog.Info("outbox relay started")
+	go func() {
+		ticker := time.NewTicker(RetryInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-notify:
+			}
+			if _, err := Deliver(context.Background()); err != nil {
+				openlog.Error("deliver outbox events failed: " + err.Error())
+			}
+		}
+	}()
+}
+
+// Deliver publishes pending events to the bus and removes delivered ones,
+// it stops at the first event failed to publish, so that events are delivered in order.
+// events of other nodes are paged past until they wait longer than TakeoverAfter.
+// an event may be delivered more than once, watchers only query kvs again
+func Deliver(ctx context.Context) (int, error) {
+	node := pubsub.LocalNode()
+	delivered := 0
+	var after *model.OutboxEvent
+	for {
+		events, err := datasource.GetBroker().GetOutboxDao().List(ctx, after, BatchSize)
+		if err != nil {
+			return delivered, err
+		}
+		done := make([]*model.OutboxEvent, 0, len(events))
+		var publishErr error
+		now := time.Now()
+		for _, e := range events {
+			if e.Node != node && now.Sub(time.Unix(0, e.Timestamp)) < TakeoverAfter {
+				continue
+			}
+			publishErr = pubsub.Publish(&pubsub.KVChangeEvent{
+				Key:      e.Key,
+				Action:   e.Action,
+				Labels:   e.Labels,
+				DomainID: e.Domain,
+				Project:  e.Project,
+			})
+			if publishErr != nil {
+				break
+			}
+			done = append(done, e)
+		}
+		if err := datasource.GetBroker().GetOutboxDao().Done(ctx, done); err != nil {
+			return delivered, err
+		}
+		delivered += len(done)
+		if publishErr != nil {
+			return delivered, fmt.Errorf("publish event failed, retry later: %w", publishErr)
+		}
+		if len(events) < BatchSize {
+			return delivered, nil
+		}
+		after = events[len(events)-1]
+	}
+}
@@ -0,0 +1,113 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package outbox_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	_ "github.com/apache/servicecomb-kie/test"
+
+	"github.com/apache/servicecomb-kie/pkg/common"
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/config"
+	"github.com/apache/servicecomb-kie/server/datasource"
+	"github.com/apache/servicecomb-kie/server/pubsub"
+	kvsvc "github.com/apache/servicecomb-kie/server/service/kv"
+	"github.com/apache/servicecomb-kie/server/service/outbox"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeliver(t *testing.T) {
+	config.Configurations.Outbox.Enabled = true
+	defer func() {
+		config.Configurations.Outbox.Enabled = false
+	}()
+	ctx := context.TODO()
+	_, err := outbox.Deliver(ctx)
+	assert.NoError(t, err)
+
+	kv, svcErr := kvsvc.Create(ctx, &model.KVDoc{
+		Key:     "outbox-test",
+		Value:   "1",
+		Status:  common.StatusEnabled,
+		Labels:  map[string]string{"app": "outbox-test"},
+		Domain:  "default",
+		Project: "outbox-test",
+	})
+	assert.Nil(t, svcErr)
+	_, err = kvsvc.FindOneAndDelete(ctx, kv.ID, "outbox-test", "default")
+	assert.NoError(t, err)
+
+	events, err := datasource.GetBroker().GetOutboxDao().List(ctx, nil, outbox.BatchSize)
+	assert.NoError(t, err)
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, pubsub.ActionPut, events[0].Action)
+		assert.Equal(t, pubsub.ActionDelete, events[1].Action)
+		assert.Equal(t, "outbox-test", events[0].Key)
+		assert.Equal(t, "outbox-test", events[0].Project)
+		assert.Equal(t, pubsub.LocalNode(), events[0].Node)
+	}
+
+	n, err := outbox.Deliver(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	events, err = datasource.GetBroker().GetOutboxDao().List(ctx, nil, outbox.BatchSize)
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestDeliverPastOtherNodes(t *testing.T) {
+	config.Configurations.Outbox.Enabled = true
+	defer func() {
+		config.Configurations.Outbox.Enabled = false
+	}()
+	ctx := context.TODO()
+	_, err := outbox.Deliver(ctx)
+	assert.NoError(t, err)
+
+	dao := datasource.GetBroker().GetKVDao()
+	for i := 0; i < outbox.BatchSize; i++ {
+		_, err := dao.Create(ctx, &model.KVDoc{
+			ID:          fmt.Sprintf("outbox-other-%d", i),
+			Key:         fmt.Sprintf("outbox-other-%d", i),
+			Value:       "1",
+			Labels:      map[string]string{"app": "outbox-other"},
+			LabelFormat: "app=outbox-other",
+			Domain:      "default",
+			Project:     "outbox-other",
+		}, datasource.WithOutbox("other-node"))
+		assert.NoError(t, err)
+	}
+	_, svcErr := kvsvc.Create(ctx, &model.KVDoc{
+		Key:     "outbox-local",
+		Value:   "1",
+		Labels:  map[string]string{"app": "outbox-other"},
+		Domain:  "default",
+		Project: "outbox-other",
+	})
+	assert.Nil(t, svcErr)
+
+	n, err := outbox.Deliver(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n, "the local event should not be blocked by events of other nodes")
+	events, err := datasource.GetBroker().GetOutboxDao().List(ctx, nil, outbox.BatchSize+1)
+	assert.NoError(t, err)
+	assert.Len(t, events, outbox.BatchSize)
+	assert.NoError(t, datasource.GetBroker().GetOutboxDao().Done(ctx, events))
+}
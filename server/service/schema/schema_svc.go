@@ -0,0 +1,285 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+// Package schema manages json schemas of keys, values of matched keys must conform to them
+package schema
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chassis/cari/config"
+	"github.com/go-chassis/cari/pkg/errsvc"
+	"github.com/go-chassis/foundation/validator"
+	"github.com/go-chassis/openlog"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"gopkg.in/yaml.v2"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	valuetype "github.com/apache/servicecomb-kie/pkg/validator"
+	"github.com/apache/servicecomb-kie/server/datasource"
+)
+
+type compiled struct {
+	source string
+	schema *jsonschema.Schema
+}
+
+// compiled schemas by schema id
+var (
+	mu    sync.RWMutex
+	cache = map[string]*compiled{}
+)
+
+// Create creates the schema of a key or a key pattern,
+// there is only one schema for the same key pattern in a project
+func Create(ctx context.Context, doc *model.SchemaDoc) (*model.SchemaDoc, *errsvc.Error) {
+	if err := validator.Validate(doc); err != nil {
+		return nil, config.NewError(config.ErrInvalidParams, err.Error())
+	}
+	if _, err := compile(doc); err != nil {
+		return nil, config.NewError(config.ErrInvalidParams, "invalid schema: "+err.Error())
+	}
+	doc.ID = fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join([]string{doc.Domain, doc.Project, doc.Key}, "/"))))
+	now := time.Now().Unix()
+	doc.CreateTime = now
+	doc.UpdateTime = now
+	doc, err := datasource.GetBroker().GetSchemaDao().Create(ctx, doc)
+	if err != nil {
+		if errors.Is(err, datasource.ErrSchemaAlreadyExists) {
+			return nil, config.NewError(config.ErrRecordAlreadyExists, err.Error())
+		}
+		return nil, config.NewError(config.ErrInternal, "create schema failed")
+	}
+	return doc, nil
+}
+
+// Update replaces the schema content, the key pattern can not be changed
+func Update(ctx context.Context, id, project, domain, schema string) (*model.SchemaDoc, *errsvc.Error) {
+	doc, svcErr := Get(ctx, id, project, domain)
+	if svcErr != nil {
+		return nil, svcErr
+	}
+	doc.Schema = schema
+	doc.UpdateTime = time.Now().Unix()
+	if err := validator.Validate(doc); err != nil {
+		return nil, config.NewError(config.ErrInvalidParams, err.Error())
+	}
+	if _, err := compile(doc); err != nil {
+		return nil, config.NewError(config.ErrInvalidParams, "invalid schema: "+err.Error())
+	}
+	if err := datasource.GetBroker().GetSchemaDao().Update(ctx, doc); err != nil {
+		return nil, svcError(err)
+	}
+	return doc, nil
+}
+
+// Get return the schema by id
+func Get(ctx context.Context, id, project, domain string) (*model.SchemaDoc, *errsvc.Error) {
+	doc, err := datasource.GetBroker().GetSchemaDao().Get(ctx, id, project, domain)
+	if err != nil {
+		return nil, svcError(err)
+	}
+	return doc, nil
+}
+
+// List return all schemas of the project
+func List(ctx context.Context, project, domain string) ([]*model.SchemaDoc, *errsvc.Error) {
+	docs, err := datasource.GetBroker().GetSchemaDao().List(ctx, project, domain)
+	if err != nil {
+		return nil, svcError(err)
+	}
+	return docs, nil
+}
+
+// Delete deletes the schema by id
+func Delete(ctx context.Context, id, project, domain string) *errsvc.Error {
+	if err := datasource.GetBroker().GetSchemaDao().Delete(ctx, id, project, domain); err != nil {
+		return svcError(err)
+	}
+	mu.Lock()
+	delete(cache, id)
+	mu.Unlock()
+	return nil
+}
+
+// Validate checks the value of the kv against schemas matching its key,
+// json and yaml values are checked as documents, text values are checked as scalars,
+// values of other types are not checked
+func Validate(ctx context.Context, kv *model.KVDoc) *errsvc.Error {
+	docs, err := datasource.GetBroker().GetSchemaDao().List(ctx, kv.Project, kv.Domain)
+	if err != nil {
+		return config.NewError(config.ErrInternal, "list schemas failed")
+	}
+	var instance interface{}
+	decoded := false
+	for _, doc := range docs {
+		if !Match(doc.Key, kv.Key) {
+			continue
+		}
+		if !decoded {
+			var ok bool
+			instance, ok, err = decode(kv.ValueType, kv.Value)
+			if err != nil {
+				return config.NewError(config.ErrInvalidParams, err.Error())
+			}
+			if !ok {
+				return nil
+			}
+			decoded = true
+		}
+		s, err := compile(doc)
+		if err != nil {
+			openlog.Error(fmt.Sprintf("invalid schema [%s]: %s", doc.ID, err))
+			return config.NewError(config.ErrInternal, "invalid schema of key "+doc.Key)
+		}
+		if err := s.Validate(instance); err != nil {
+			return violation(kv.Key, doc.Key, err)
+		}
+	}
+	return nil
+}
+
+// Match return true if the key matches the key pattern,
+// a pattern can be a key, beginWith(prefix) or wildcard(expr)
+func Match(pattern, key string) bool {
+	switch {
+	case strings.HasPrefix(pattern, "beginWith(") && strings.HasSuffix(pattern, ")"):
+		return strings.HasPrefix(key, pattern[len("beginWith("):len(pattern)-1])
+	case strings.HasPrefix(pattern, "wildcard(") && strings.HasSuffix(pattern, ")"):
+		expr := regexp.QuoteMeta(pattern[len("wildcard(") : len(pattern)-1])
+		expr = "^" + strings.ReplaceAll(expr, "\\*", ".*") + "$"
+		matched, err := regexp.MatchString(expr, key)
+		return err == nil && matched
+	default:
+		return pattern == key
+	}
+}
+
+func compile(doc *model.SchemaDoc) (*jsonschema.Schema, error) {
+	mu.RLock()
+	c, ok := cache[doc.ID]
+	mu.RUnlock()
+	if ok && c.source == doc.Schema {
+		return c.schema, nil
+	}
+	s, err := jsonschema.CompileString("schema.json", doc.Schema)
+	if err != nil {
+		return nil, err
+	}
+	if doc.ID != "" {
+		mu.Lock()
+		cache[doc.ID] = &compiled{source: doc.Schema, schema: s}
+		mu.Unlock()
+	}
+	return s, nil
+}
+
+// decode converts the value to a json instance, ok is false if the value type is not checked by schemas
+func decode(valueType, value string) (interface{}, bool, error) {
+	switch valueType {
+	case valuetype.ValueTypeJSON:
+		v, err := decodeJSON([]byte(value))
+		return v, true, err
+	case valuetype.ValueTypeYAML:
+		var v interface{}
+		if err := yaml.Unmarshal([]byte(value), &v); err != nil {
+			return nil, false, err
+		}
+		b, err := json.Marshal(convertYAML(v))
+		if err != nil {
+			return nil, false, err
+		}
+		v, err = decodeJSON(b)
+		return v, true, err
+	case "", valuetype.ValueTypeText:
+		v, err := decodeJSON([]byte(value))
+		if err != nil {
+			return value, true, nil
+		}
+		switch v.(type) {
+		case map[string]interface{}, []interface{}:
+			return value, true, nil
+		}
+		return v, true, nil
+	default:
+		return nil, false, nil
+	}
+}
+
+func decodeJSON(b []byte) (interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	if d.More() {
+		return nil, errors.New("unexpected data after json value")
+	}
+	return v, nil
+}
+
+// convertYAML converts yaml maps to json objects
+func convertYAML(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, e := range t {
+			m[fmt.Sprint(k)] = convertYAML(e)
+		}
+		return m
+	case []interface{}:
+		for i, e := range t {
+			t[i] = convertYAML(e)
+		}
+		return t
+	default:
+		return v
+	}
+}
+
+// violation points to the deepest failing path of the value
+func violation(key, pattern string, err error) *errsvc.Error {
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		return config.NewError(config.ErrInvalidParams, err.Error())
+	}
+	for len(ve.Causes) > 0 {
+		ve = ve.Causes[0]
+	}
+	pointer := ve.InstanceLocation
+	if pointer == "" {
+		pointer = "/"
+	}
+	return config.NewError(config.ErrInvalidParams,
+		fmt.Sprintf("value of key [%s] violates schema of [%s] at %s: %s", key, pattern, pointer, ve.Message))
+}
+
+func svcError(err error) *errsvc.Error {
+	if errors.Is(err, datasource.ErrSchemaNotExists) {
+		return config.NewError(config.ErrRecordNotExists, err.Error())
+	}
+	return config.NewError(config.ErrInternal, err.Error())
+}
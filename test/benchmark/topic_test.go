@@ -0,0 +1,119 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/apache/servicecomb-kie/server/pubsub"
+)
+
+var topicNums = []int{100, 1000, 10000, 50000}
+
+// prepareTopics add observers for n topics, every topic watches a different service
+func prepareTopics(b *testing.B, n int) []*pubsub.Topic {
+	ts := make([]*pubsub.Topic, 0, n)
+	for i := 0; i < n; i++ {
+		t := &pubsub.Topic{
+			DomainID: "default",
+			Project:  "benchmark",
+			Labels: map[string]string{
+				"app":     "mall",
+				"service": "svc" + strconv.Itoa(i),
+			},
+		}
+		o := &pubsub.Observer{
+			UUID:  strconv.Itoa(i),
+			Event: make(chan *pubsub.KVChangeEvent, 1),
+		}
+		if _, err := pubsub.AddObserver(o, t); err != nil {
+			b.Fatal(err)
+		}
+		ts = append(ts, t)
+	}
+	// do not let the garbage of preparation be collected during benchmark
+	runtime.GC()
+	return ts
+}
+
+func cleanTopics(ts []*pubsub.Topic) {
+	for i, t := range ts {
+		pubsub.RemoveObserver(strconv.Itoa(i), t)
+	}
+}
+
+func newEvent() *pubsub.KVChangeEvent {
+	return &pubsub.KVChangeEvent{
+		Key:      "timeout",
+		Action:   pubsub.ActionPut,
+		DomainID: "default",
+		Project:  "benchmark",
+		Labels: map[string]string{
+			"app":     "mall",
+			"service": "svc0",
+		},
+	}
+}
+
+// BenchmarkMatchTopics looks up the matched topics by the inverted index
+func BenchmarkMatchTopics(b *testing.B) {
+	for _, n := range topicNums {
+		b.Run(fmt.Sprintf("topics-%d", n), func(b *testing.B) {
+			ts := prepareTopics(b, n)
+			defer cleanTopics(ts)
+			e := newEvent()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if len(pubsub.MatchTopics(e)) != 1 {
+					b.Fatal("should match one topic")
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkScanTopics ranges and parses all topics, it is how events were fanned out before the index
+func BenchmarkScanTopics(b *testing.B) {
+	for _, n := range topicNums {
+		b.Run(fmt.Sprintf("topics-%d", n), func(b *testing.B) {
+			ts := prepareTopics(b, n)
+			defer cleanTopics(ts)
+			e := newEvent()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				matched := 0
+				pubsub.Topics().Range(func(key, value interface{}) bool {
+					t, err := pubsub.ParseTopic(key.(string))
+					if err != nil {
+						b.Fatal(err)
+					}
+					if t.Match(e) {
+						matched++
+					}
+					return true
+				})
+				if matched != 1 {
+					b.Fatal("should match one topic")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/apache/servicecomb-kie/pkg/model"
+	"github.com/apache/servicecomb-kie/server/datasource"
+	kvsvc "github.com/apache/servicecomb-kie/server/service/kv"
+	_ "github.com/apache/servicecomb-kie/test"
+)
+
+const uploadSize = 50
+
+// blockSizes is the number of revisions reserved at once, 1 is a round trip to the counter for each write
+var blockSizes = []int64{1, 100}
+
+// BenchmarkUpload uploads kvs concurrently, every upload updates uploadSize kvs,
+// run it with TEST_DB_KIND and TEST_DB_URI to benchmark etcd or mongo
+func BenchmarkUpload(b *testing.B) {
+	for _, size := range blockSizes {
+		b.Run(fmt.Sprintf("block-%d", size), func(b *testing.B) {
+			datasource.Revisions().SetBlockSize(size)
+			defer datasource.Revisions().SetBlockSize(1)
+			var workers int32
+			start := time.Now()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				prefix := fmt.Sprintf("upload%d.", atomic.AddInt32(&workers, 1))
+				for i := 0; pb.Next(); i++ {
+					kvs := make([]*model.KVDoc, 0, uploadSize)
+					for k := 0; k < uploadSize; k++ {
+						kvs = append(kvs, &model.KVDoc{
+							Key:    prefix + strconv.Itoa(k),
+							Value:  strconv.Itoa(i),
+							Labels: map[string]string{"app": "benchmark"},
+						})
+					}
+					resp := kvsvc.Upload(context.Background(), &model.UploadKVRequest{
+						Domain:   "default",
+						Project:  "benchmark",
+						KVs:      kvs,
+						Override: "force",
+					})
+					if len(resp.Failure) != 0 {
+						b.Fatal(resp.Failure[0].ErrMsg)
+					}
+				}
+			})
+			b.ReportMetric(float64(b.N*uploadSize)/time.Since(start).Seconds(), "kvs/s")
+		})
+	}
+}
+
+// BenchmarkApplyRevision applies revisions concurrently, it is the cost of revisions in every write
+func BenchmarkApplyRevision(b *testing.B) {
+	for _, size := range blockSizes {
+		b.Run(fmt.Sprintf("block-%d", size), func(b *testing.B) {
+			datasource.Revisions().SetBlockSize(size)
+			defer datasource.Revisions().SetBlockSize(1)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if _, err := datasource.GetBroker().GetRevisionDao().ApplyRevision(context.Background(), "benchmark", "default"); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		})
+	}
+}
@@ -24,7 +24,9 @@ import (
 	"github.com/apache/servicecomb-kie/server/db"
 	_ "github.com/go-chassis/cari/db/bootstrap"
 
+	_ "github.com/apache/servicecomb-kie/server/datasource/bolt"
 	_ "github.com/apache/servicecomb-kie/server/datasource/etcd"
+	_ "github.com/apache/servicecomb-kie/server/datasource/memory"
 	_ "github.com/apache/servicecomb-kie/server/datasource/mongo"
 	_ "github.com/apache/servicecomb-kie/server/plugin/qms"
 	_ "github.com/apache/servicecomb-kie/server/pubsub/notifier"